@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/service"
 )
 
@@ -19,7 +23,66 @@ type SuccessResponse struct {
 
 // CreateLeagueRequest represents a request to create a league
 type CreateLeagueRequest struct {
-	Name string `json:"name"`
+	Name        string                 `json:"name"`
+	TeamIDs     []int                  `json:"team_ids"`     // Optional, defaults to all teams
+	Rounds      int                    `json:"rounds"`       // Optional, 1 = single (default), 2 = double (home and away), N = N-fold round robin
+	Engine      model.EngineConfig     `json:"engine"`       // Optional, match engine and its parameters, defaults to the linear engine
+	Rating      model.RatingConfig     `json:"rating"`       // Optional, Elo ratings that replace the static team strengths, disabled by default
+	TieBreakers []string               `json:"tie_breakers"` // Optional, ordered rules the table is sorted by, defaults to points, goal_difference, goals_for
+	Scoring     *model.ScoringRules    `json:"scoring"`      // Optional, points for results and bonus points, defaults to 3 for a win and 1 for a draw
+	Discipline  *model.DisciplineRules `json:"discipline"`   // Optional, bans for cards, defaults to a match after five yellow cards or a second booking and three after a straight red card
+	Zones       []model.PositionBand   `json:"zones"`        // Optional, qualification and relegation zones, defaults to the title, the top three and the last place
+	Seed        *int64                 `json:"seed"`         // Optional, seed all simulations of the league derive from, random by default
+}
+
+// CreateDeductionRequest represents a request to take points off a team
+type CreateDeductionRequest struct {
+	TeamID int    `json:"team_id"`
+	Points int    `json:"points"` // Points taken off, a negative value gives points back
+	Reason string `json:"reason"`
+}
+
+// EditMatchResultRequest represents a request to change the result of a played match
+type EditMatchResultRequest struct {
+	HomeScore     *int `json:"home_score"`
+	AwayScore     *int `json:"away_score"`
+	HomePenalties *int `json:"home_penalties"` // Required for draws in leagues deciding draws on penalties
+	AwayPenalties *int `json:"away_penalties"`
+}
+
+// SetZonesRequest represents a request to replace the zones of a league table
+type SetZonesRequest struct {
+	Zones []model.PositionBand `json:"zones"` // Empty restores the default zones
+}
+
+// WhatIfRequest represents a what-if scenario of hypothetical results
+type WhatIfRequest struct {
+	Results []model.ForcedResult `json:"results"`
+}
+
+// CreateCupRequest represents a request to create a knockout cup
+type CreateCupRequest struct {
+	Name           string             `json:"name"`
+	TeamIDs        []int              `json:"team_ids"`         // Optional, defaults to all teams
+	TwoLegged      bool               `json:"two_legged"`       // Optional, ties before the final are played home and away
+	TwoLeggedFinal bool               `json:"two_legged_final"` // Optional, the final is played home and away
+	Draw           string             `json:"draw"`             // Optional, seeded (default) or random
+	Engine         model.EngineConfig `json:"engine"`           // Optional, match engine and its parameters, defaults to the linear engine
+	Seed           *int64             `json:"seed"`             // Optional, seed of the draw and all rounds, random by default
+}
+
+// CreateTournamentRequest represents a request to create a group stage plus knockout tournament
+type CreateTournamentRequest struct {
+	Name            string             `json:"name"`
+	TeamIDs         []int              `json:"team_ids"`          // Optional, defaults to all teams
+	Groups          int                `json:"groups"`            // Number of groups the teams are drawn into
+	AdvancePerGroup int                `json:"advance_per_group"` // Teams of every group advancing to the knockout stage
+	GroupRounds     int                `json:"group_rounds"`      // Optional, round robins played within a group, 1 by default
+	TwoLegged       bool               `json:"two_legged"`        // Optional, knockout ties before the final are played home and away
+	TwoLeggedFinal  bool               `json:"two_legged_final"`  // Optional, the final is played home and away
+	Engine          model.EngineConfig `json:"engine"`            // Optional, match engine and its parameters, defaults to the linear engine
+	TieBreakers     []string           `json:"tie_breakers"`      // Optional, ordered rules the group tables are sorted by
+	Seed            *int64             `json:"seed"`              // Optional, seed of the group draw and all simulations, random by default
 }
 
 // SetupRoutes sets up all the routes for the application
@@ -29,6 +92,8 @@ func SetupRoutes(app *fiber.App, service *service.Service) {
 	matchController := NewMatchController(service.Match)
 	leagueController := NewLeagueController(service.League)
 	predictionController := NewPredictionController(service.Prediction)
+	cupController := NewCupController(service.Cup)
+	tournamentController := NewTournamentController(service.Tournament)
 
 	// Middleware
 	app.Use(logger.New())
@@ -47,26 +112,63 @@ func SetupRoutes(app *fiber.App, service *service.Service) {
 	teams.Put("/:id", teamController.UpdateTeam)
 	teams.Delete("/:id", teamController.DeleteTeam)
 	teams.Post("/initialize", teamController.CreateInitialTeams)
+	teams.Get("/:id/players", teamController.GetPlayers)
+	teams.Post("/:id/players", teamController.CreatePlayer)
+	teams.Get("/:id/players/:playerId", teamController.GetPlayer)
+	teams.Put("/:id/players/:playerId", teamController.UpdatePlayer)
+	teams.Delete("/:id/players/:playerId", teamController.DeletePlayer)
 
 	// Match routes
 	matches := api.Group("/matches")
 	matches.Get("/", matchController.GetMatches)
 	matches.Get("/:id", matchController.GetMatch)
+	matches.Get("/:id/events", matchController.GetMatchEvents)
 	matches.Post("/", matchController.CreateMatch)
 	matches.Put("/:id", matchController.UpdateMatch)
 
 	// League routes
 	leagues := api.Group("/leagues")
+	leagues.Get("/", leagueController.GetLeagues)
 	leagues.Post("/", leagueController.CreateLeague)
 	leagues.Get("/:id", leagueController.GetLeague)
 	leagues.Post("/:id/simulate", leagueController.SimulateWeek)
 	leagues.Post("/:id/simulate-all", leagueController.SimulateAllWeeks)
 	leagues.Get("/:id/standings", leagueController.GetStandings)
 	leagues.Get("/:id/weeks/:week/matches", leagueController.GetWeeklyMatches)
+	leagues.Put("/:id/matches/:matchId/result", leagueController.EditMatchResult)
+	leagues.Post("/:id/rewind", leagueController.RewindLeague)
+	leagues.Get("/:id/replay", leagueController.ReplayLeague)
+	leagues.Get("/:id/ratings", leagueController.GetRatings)
+	leagues.Get("/:id/deductions", leagueController.GetDeductions)
+	leagues.Get("/:id/stats/players", leagueController.GetPlayerStats)
+	leagues.Get("/:id/absences", leagueController.GetAbsences)
+	leagues.Get("/:id/teams/:teamId/form", leagueController.GetTeamForm)
+	leagues.Post("/:id/deductions", leagueController.CreateDeduction)
+	leagues.Get("/:id/zones", leagueController.GetZones)
+	leagues.Put("/:id/zones", leagueController.SetZones)
 
 	// Prediction routes
 	leagues.Get("/:id/predict", predictionController.PredictFinalStandings)
 	leagues.Get("/:id/predictions", predictionController.GetPredictionWithConfidence)
+	leagues.Post("/:id/predictions/what-if", predictionController.PredictScenario)
+	leagues.Get("/:id/prediction-backtest", predictionController.GetBacktest)
+
+	// Cup routes
+	cups := api.Group("/cups")
+	cups.Get("/", cupController.GetCups)
+	cups.Post("/", cupController.CreateCup)
+	cups.Get("/:id", cupController.GetCup)
+	cups.Post("/:id/draw", cupController.DrawCup)
+	cups.Post("/:id/simulate-round", cupController.SimulateRound)
+	cups.Get("/:id/bracket", cupController.GetBracket)
+
+	// Tournament routes
+	tournaments := api.Group("/tournaments")
+	tournaments.Get("/", tournamentController.GetTournaments)
+	tournaments.Post("/", tournamentController.CreateTournament)
+	tournaments.Get("/:id", tournamentController.GetTournament)
+	tournaments.Post("/:id/groups/simulate", tournamentController.SimulateGroupWeek)
+	tournaments.Post("/:id/knockout/simulate", tournamentController.SimulateKnockoutRound)
 
 	// For backward compatibility, also add routes without /api prefix
 	// Team routes
@@ -76,22 +178,97 @@ func SetupRoutes(app *fiber.App, service *service.Service) {
 	app.Put("/teams/:id", teamController.UpdateTeam)
 	app.Delete("/teams/:id", teamController.DeleteTeam)
 	app.Post("/teams/initialize", teamController.CreateInitialTeams)
+	app.Get("/teams/:id/players", teamController.GetPlayers)
+	app.Post("/teams/:id/players", teamController.CreatePlayer)
+	app.Get("/teams/:id/players/:playerId", teamController.GetPlayer)
+	app.Put("/teams/:id/players/:playerId", teamController.UpdatePlayer)
+	app.Delete("/teams/:id/players/:playerId", teamController.DeletePlayer)
 
 	// Match routes
 	app.Get("/matches", matchController.GetMatches)
 	app.Get("/matches/:id", matchController.GetMatch)
+	app.Get("/matches/:id/events", matchController.GetMatchEvents)
 	app.Post("/matches", matchController.CreateMatch)
 	app.Put("/matches/:id", matchController.UpdateMatch)
 
 	// League routes
+	app.Get("/leagues", leagueController.GetLeagues)
 	app.Post("/leagues", leagueController.CreateLeague)
 	app.Get("/leagues/:id", leagueController.GetLeague)
 	app.Post("/leagues/:id/simulate", leagueController.SimulateWeek)
 	app.Post("/leagues/:id/simulate-all", leagueController.SimulateAllWeeks)
 	app.Get("/leagues/:id/standings", leagueController.GetStandings)
 	app.Get("/leagues/:id/weeks/:week/matches", leagueController.GetWeeklyMatches)
+	app.Put("/leagues/:id/matches/:matchId/result", leagueController.EditMatchResult)
+	app.Post("/leagues/:id/rewind", leagueController.RewindLeague)
+	app.Get("/leagues/:id/replay", leagueController.ReplayLeague)
+	app.Get("/leagues/:id/ratings", leagueController.GetRatings)
+	app.Get("/leagues/:id/deductions", leagueController.GetDeductions)
+	app.Get("/leagues/:id/stats/players", leagueController.GetPlayerStats)
+	app.Get("/leagues/:id/absences", leagueController.GetAbsences)
+	app.Get("/leagues/:id/teams/:teamId/form", leagueController.GetTeamForm)
+	app.Post("/leagues/:id/deductions", leagueController.CreateDeduction)
+	app.Get("/leagues/:id/zones", leagueController.GetZones)
+	app.Put("/leagues/:id/zones", leagueController.SetZones)
 
 	// Prediction routes
 	app.Get("/leagues/:id/predict", predictionController.PredictFinalStandings)
 	app.Get("/leagues/:id/predictions", predictionController.GetPredictionWithConfidence)
+	app.Post("/leagues/:id/predictions/what-if", predictionController.PredictScenario)
+	app.Get("/leagues/:id/prediction-backtest", predictionController.GetBacktest)
+
+	// Cup routes
+	app.Get("/cups", cupController.GetCups)
+	app.Post("/cups", cupController.CreateCup)
+	app.Get("/cups/:id", cupController.GetCup)
+	app.Post("/cups/:id/draw", cupController.DrawCup)
+	app.Post("/cups/:id/simulate-round", cupController.SimulateRound)
+	app.Get("/cups/:id/bracket", cupController.GetBracket)
+
+	// Tournament routes
+	app.Get("/tournaments", tournamentController.GetTournaments)
+	app.Post("/tournaments", tournamentController.CreateTournament)
+	app.Get("/tournaments/:id", tournamentController.GetTournament)
+	app.Post("/tournaments/:id/groups/simulate", tournamentController.SimulateGroupWeek)
+	app.Post("/tournaments/:id/knockout/simulate", tournamentController.SimulateKnockoutRound)
+}
+
+// parseSeed reads the optional seed query parameter
+func parseSeed(ctx *fiber.Ctx) (*int64, error) {
+	value := ctx.Query("seed")
+	if value == "" {
+		return nil, nil
+	}
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seed, nil
+}
+
+// parsePredictionOptions reads the optional seed, iterations and workers query parameters
+func parsePredictionOptions(ctx *fiber.Ctx) (model.PredictionOptions, error) {
+	var opts model.PredictionOptions
+
+	seed, err := parseSeed(ctx)
+	if err != nil {
+		return opts, errors.New("invalid seed")
+	}
+	opts.Seed = seed
+
+	if value := ctx.Query("iterations"); value != "" {
+		if opts.Iterations, err = strconv.Atoi(value); err != nil || opts.Iterations < 1 {
+			return opts, errors.New("invalid iterations")
+		}
+	}
+
+	if value := ctx.Query("workers"); value != "" {
+		if opts.Workers, err = strconv.Atoi(value); err != nil || opts.Workers < 1 {
+			return opts, errors.New("invalid workers")
+		}
+	}
+
+	return opts, opts.Validate()
 }
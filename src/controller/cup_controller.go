@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/league-simulator/src/model"
+	"github.com/user/league-simulator/src/service"
+)
+
+// CupController handles HTTP requests for knockout cups
+type CupController struct {
+	service *service.CupService
+}
+
+// NewCupController creates a new CupController
+func NewCupController(service *service.CupService) *CupController {
+	return &CupController{
+		service: service,
+	}
+}
+
+// GetCups godoc
+// @Summary Get all cups
+// @Description Get a list of all knockout cups
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.Cup
+// @Failure 500 {object} ErrorResponse
+// @Router /cups [get]
+func (c *CupController) GetCups(ctx *fiber.Ctx) error {
+	cups, err := c.service.GetAll(ctx.Context())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(cups)
+}
+
+// CreateCup godoc
+// @Summary Create a new cup
+// @Description Create a single-elimination cup with the provided name and teams (all teams when team_ids is omitted). The bracket is empty until the draw is made.
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Param cup body CreateCupRequest true "Cup information"
+// @Success 201 {object} model.Cup
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cups [post]
+func (c *CupController) CreateCup(ctx *fiber.Ctx) error {
+	var request CreateCupRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if request.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Cup name is required"})
+	}
+
+	if request.Draw != "" && request.Draw != model.DrawSeeded && request.Draw != model.DrawRandom {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Draw must be seeded or random"})
+	}
+
+	if _, err := model.NewMatchSimulator(request.Engine); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if request.Engine.Modifiers != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Engine modifiers only apply to leagues"})
+	}
+
+	cup, err := c.service.Create(ctx.Context(), request.Name, model.CupOptions{
+		TeamIDs:        request.TeamIDs,
+		TwoLegged:      request.TwoLegged,
+		TwoLeggedFinal: request.TwoLeggedFinal,
+		Draw:           request.Draw,
+		Engine:         request.Engine,
+		Seed:           request.Seed,
+	})
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(cup)
+}
+
+// GetCup godoc
+// @Summary Get a cup by ID
+// @Description Get a specific cup with its teams and ties
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Param id path int true "Cup ID"
+// @Success 200 {object} model.Cup
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /cups/{id} [get]
+func (c *CupController) GetCup(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid cup ID"})
+	}
+
+	cup, err := c.service.GetByID(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(cup)
+}
+
+// DrawCup godoc
+// @Summary Make the draw of a cup
+// @Description Place the teams in the bracket, seeded by strength or at random depending on the cup's draw type. Teams without a first round opponent advance on a bye.
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Param id path int true "Cup ID"
+// @Success 200 {object} model.CupBracket
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cups/{id}/draw [post]
+func (c *CupController) DrawCup(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid cup ID"})
+	}
+
+	bracket, err := c.service.Draw(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(bracket)
+}
+
+// SimulateRound godoc
+// @Summary Simulate the next round of a cup
+// @Description Play every tie of the next round. Ties level on aggregate go to extra time and then to a penalty shootout.
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Param id path int true "Cup ID"
+// @Success 200 {object} model.CupRound
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /cups/{id}/simulate-round [post]
+func (c *CupController) SimulateRound(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid cup ID"})
+	}
+
+	round, err := c.service.SimulateRound(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(round)
+}
+
+// GetBracket godoc
+// @Summary Get the bracket of a cup
+// @Description Get the ties of every round of a cup with their legs, penalties and winners
+// @Tags cups
+// @Accept json
+// @Produce json
+// @Param id path int true "Cup ID"
+// @Success 200 {object} model.CupBracket
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /cups/{id}/bracket [get]
+func (c *CupController) GetBracket(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid cup ID"})
+	}
+
+	bracket, err := c.service.GetBracket(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(bracket)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/service"
 )
 
@@ -17,9 +18,27 @@ func NewLeagueController(service *service.LeagueService) *LeagueController {
 	}
 }
 
+// GetLeagues godoc
+// @Summary Get all leagues
+// @Description Get a list of all leagues
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.League
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues [get]
+func (c *LeagueController) GetLeagues(ctx *fiber.Ctx) error {
+	leagues, err := c.service.GetAll(ctx.Context())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(leagues)
+}
+
 // CreateLeague godoc
 // @Summary Create a new league
-// @Description Create a new league with the provided name
+// @Description Create a new league with the provided name and teams (all teams when team_ids is omitted)
 // @Tags leagues
 // @Accept json
 // @Produce json
@@ -38,7 +57,53 @@ func (c *LeagueController) CreateLeague(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "League name is required"})
 	}
 
-	league, err := c.service.Create(ctx.Context(), request.Name)
+	if request.Rounds == 0 {
+		request.Rounds = model.SingleRoundRobin
+	}
+
+	if request.Rounds < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Rounds must be a positive number"})
+	}
+
+	if _, err := model.NewMatchSimulator(request.Engine); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := request.Engine.Modifiers.Resolve(); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := request.Rating.Resolve(); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := model.ResolveTieBreakers(request.TieBreakers); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := model.ResolveScoringRules(request.Scoring); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := model.ResolveDisciplineRules(request.Discipline); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if _, err := model.ResolveZones(request.Zones, 0); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	league, err := c.service.Create(ctx.Context(), request.Name, model.LeagueOptions{
+		TeamIDs:     request.TeamIDs,
+		Rounds:      request.Rounds,
+		Engine:      request.Engine,
+		Rating:      request.Rating,
+		TieBreakers: request.TieBreakers,
+		Scoring:     request.Scoring,
+		Discipline:  request.Discipline,
+		Zones:       request.Zones,
+		Seed:        request.Seed,
+	})
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
@@ -78,6 +143,7 @@ func (c *LeagueController) GetLeague(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "League ID"
+// @Param seed query int false "Seed for this week, derived from the league seed by default"
 // @Success 200 {object} model.Standings
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -88,7 +154,12 @@ func (c *LeagueController) SimulateWeek(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
 	}
 
-	standings, err := c.service.SimulateWeek(ctx.Context(), id)
+	seed, err := parseSeed(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid seed"})
+	}
+
+	standings, err := c.service.SimulateWeek(ctx.Context(), id, seed)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
@@ -128,6 +199,7 @@ func (c *LeagueController) GetStandings(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "Liga ID"
+// @Param seed query int false "Hafta seed'lerinin türetileceği seed, varsayılan olarak liganın seed'i"
 // @Success 200 {object} model.LeagueSimulationResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -138,7 +210,12 @@ func (c *LeagueController) SimulateAllWeeks(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Geçersiz liga ID"})
 	}
 
-	result, err := c.service.SimulateAllRemainingWeeks(ctx.Context(), id)
+	seed, err := parseSeed(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Geçersiz seed"})
+	}
+
+	result, err := c.service.SimulateAllRemainingWeeks(ctx.Context(), id, seed)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
@@ -148,13 +225,13 @@ func (c *LeagueController) SimulateAllWeeks(ctx *fiber.Ctx) error {
 
 // GetWeeklyMatches - Haftalık maçları getir
 // @Summary Belirli bir haftanın maçlarını getir
-// @Description Ligada belirli bir haftanın tüm maçlarını getir
+// @Description Ligada belirli bir haftanın tüm maçlarını ve bay geçen takımlarını getir
 // @Tags leagues
 // @Accept json
 // @Produce json
 // @Param id path int true "Liga ID"
 // @Param week path int true "Hafta numarası"
-// @Success 200 {array} model.Match
+// @Success 200 {object} model.WeeklyFixtures
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /leagues/{id}/weeks/{week}/matches [get]
@@ -169,10 +246,353 @@ func (c *LeagueController) GetWeeklyMatches(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Geçersiz hafta numarası"})
 	}
 
-	matches, err := c.service.GetWeeklyMatches(ctx.Context(), leagueID, week)
+	fixtures, err := c.service.GetWeeklyMatches(ctx.Context(), leagueID, week)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(fixtures)
+}
+
+// EditMatchResult godoc
+// @Summary Edit the result of a played match
+// @Description Change the score of a played match of the league and rebuild every standings and rating snapshot from the match's week to the current week in a single transaction. Draws in leagues deciding draws on penalties need a shootout result. In matches simulated by the event engine, the goals of a side whose goal count changes are removed from the timeline and its scorer and assist statistics; the goals of a side whose count is unchanged are kept.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param matchId path int true "Match ID"
+// @Param result body EditMatchResultRequest true "New result"
+// @Success 200 {object} model.Standings
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/matches/{matchId}/result [put]
+func (c *LeagueController) EditMatchResult(ctx *fiber.Ctx) error {
+	leagueID, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	matchID, err := ctx.ParamsInt("matchId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid match ID"})
+	}
+
+	var request EditMatchResultRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if request.HomeScore == nil || request.AwayScore == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Home score and away score are required"})
+	}
+
+	if *request.HomeScore < 0 || *request.AwayScore < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Scores cannot be negative"})
+	}
+
+	standings, err := c.service.EditMatchResult(
+		ctx.Context(),
+		leagueID,
+		matchID,
+		*request.HomeScore,
+		*request.AwayScore,
+		request.HomePenalties,
+		request.AwayPenalties,
+	)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(standings)
+}
+
+// RewindLeague godoc
+// @Summary Rewind a league to a previous week
+// @Description Take a league back to the end of the given week in a single transaction. Matches of later weeks become unplayed and the standings snapshots and ratings of later weeks are removed. Point deductions are kept and those of later weeks apply again when their week is played.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param to_week query int true "Week to rewind to, 0 resets the league"
+// @Success 200 {object} model.Standings
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/rewind [post]
+func (c *LeagueController) RewindLeague(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	week := ctx.QueryInt("to_week", -1)
+	if week < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid to_week parameter"})
+	}
+
+	standings, err := c.service.Rewind(ctx.Context(), id, week)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(standings)
+}
+
+// ReplayLeague godoc
+// @Summary Replay a league from its seeds
+// @Description Re-simulate every played week of a league from its recorded seeds without persisting anything and report whether the results are identical
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {object} model.ReplayResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/replay [get]
+func (c *LeagueController) ReplayLeague(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	result, err := c.service.Replay(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(result)
+}
+
+// GetRatings godoc
+// @Summary Get the rating history of a league
+// @Description Get the Elo rating of every team after every played week, starting with the initial ratings of week 0. Teams have no history when ratings are disabled.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {object} model.RatingHistory
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/ratings [get]
+func (c *LeagueController) GetRatings(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	history, err := c.service.GetRatingHistory(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(history)
+}
+
+// GetPlayerStats godoc
+// @Summary Get the player statistics of a league
+// @Description Get the top scorers, assist leaders, goalkeepers' clean sheets, the card table and the bans still to be served, built from the matches simulated by the event engine
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {object} model.PlayerStatsReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /leagues/{id}/stats/players [get]
+func (c *LeagueController) GetPlayerStats(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	stats, err := c.service.GetPlayerStats(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(stats)
+}
+
+// GetTeamForm godoc
+// @Summary Get the form of a team of a league
+// @Description Get the last results of a team and how the league's form, momentum and fatigue modifiers scale its strength for the next week
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param teamId path int true "Team ID"
+// @Success 200 {object} model.TeamForm
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /leagues/{id}/teams/{teamId}/form [get]
+func (c *LeagueController) GetTeamForm(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	teamID, err := ctx.ParamsInt("teamId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	form, err := c.service.GetTeamForm(ctx.Context(), id, teamID)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(form)
+}
+
+// GetAbsences godoc
+// @Summary Get the absent players of a league
+// @Description Get the players who are injured or serving a ban for the next week of a league, with the week they return
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {array} model.Absence
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /leagues/{id}/absences [get]
+func (c *LeagueController) GetAbsences(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	absences, err := c.service.GetAbsences(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(absences)
+}
+
+// GetDeductions godoc
+// @Summary Get the point deductions of a league
+// @Description Get every point deduction of a league with its team, reason and the week it applies from
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {array} model.PointDeduction
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/deductions [get]
+func (c *LeagueController) GetDeductions(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	deductions, err := c.service.GetDeductions(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(deductions)
+}
+
+// CreateDeduction godoc
+// @Summary Deduct points from a team
+// @Description Take points off a team of the league, with a reason, from the current week onwards. The deduction is reflected in the standings and predictions.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param deduction body CreateDeductionRequest true "Deduction information"
+// @Success 201 {object} model.PointDeduction
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/deductions [post]
+func (c *LeagueController) CreateDeduction(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	var request CreateDeductionRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if request.TeamID < 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Team ID is required"})
+	}
+
+	if request.Points == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Points must not be zero"})
+	}
+
+	if request.Reason == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Reason is required"})
+	}
+
+	deduction, err := c.service.AddDeduction(ctx.Context(), id, request.TeamID, request.Points, request.Reason)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(deduction)
+}
+
+// GetZones godoc
+// @Summary Get the zones of a league table
+// @Description Get the qualification and relegation zones of a league, the title, the top three and the last place unless the league has its own
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Success 200 {array} model.PositionBand
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/zones [get]
+func (c *LeagueController) GetZones(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	league, err := c.service.GetByID(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(league.PositionZones())
+}
+
+// SetZones godoc
+// @Summary Replace the zones of a league table
+// @Description Replace the qualification and relegation zones of a league, e.g. positions 1-4 "Champions League" and 18-20 "Relegation". Zones drive the zone probabilities of the predictions and are marked on the standings. An empty list restores the default zones.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param zones body SetZonesRequest true "Zones of the league table"
+// @Success 200 {array} model.PositionBand
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/zones [put]
+func (c *LeagueController) SetZones(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	var request SetZonesRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if _, err := model.ResolveZones(request.Zones, 0); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	zones, err := c.service.SetZones(ctx.Context(), id, request.Zones)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
 
-	return ctx.JSON(matches)
+	return ctx.JSON(zones)
 }
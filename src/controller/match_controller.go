@@ -20,16 +20,24 @@ func NewMatchController(service *service.MatchService) *MatchController {
 
 // GetMatches godoc
 // @Summary Get all matches
-// @Description Get a list of all matches or matches for a specific week
+// @Description Get a list of all matches, the matches of a league or the matches of a league for a specific week
 // @Tags matches
 // @Accept json
 // @Produce json
+// @Param league_id query int false "League ID (required when filtering by week)"
 // @Param week query int false "Week number"
 // @Success 200 {array} model.Match
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /matches [get]
 func (c *MatchController) GetMatches(ctx *fiber.Ctx) error {
+	// Check if league_id query parameter is provided
+	leagueIDStr := ctx.Query("league_id")
+	leagueID := ctx.QueryInt("league_id", 0) // Default to 0 if conversion fails
+	if leagueIDStr != "" && leagueID < 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league_id parameter"})
+	}
+
 	// Check if week query parameter is provided
 	weekStr := ctx.Query("week")
 	if weekStr != "" {
@@ -38,7 +46,20 @@ func (c *MatchController) GetMatches(ctx *fiber.Ctx) error {
 			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid week parameter"})
 		}
 
-		matches, err := c.service.GetByWeek(ctx.Context(), week)
+		if leagueIDStr == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "league_id is required when filtering by week"})
+		}
+
+		matches, err := c.service.GetByWeek(ctx.Context(), leagueID, week)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		}
+
+		return ctx.JSON(matches)
+	}
+
+	if leagueIDStr != "" {
+		matches, err := c.service.GetByLeague(ctx.Context(), leagueID)
 		if err != nil {
 			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 		}
@@ -80,16 +101,40 @@ func (c *MatchController) GetMatch(ctx *fiber.Ctx) error {
 	return ctx.JSON(match)
 }
 
+// GetMatchEvents godoc
+// @Summary Get the timeline of a match
+// @Description Get the goals, assists, cards, substitutions and injuries of a match in the order they happened. Only matches simulated by the event engine have a timeline.
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path int true "Match ID"
+// @Success 200 {array} model.MatchEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /matches/{id}/events [get]
+func (c *MatchController) GetMatchEvents(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid match ID"})
+	}
+
+	events, err := c.service.GetEvents(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(events)
+}
+
 // CreateMatch godoc
 // @Summary Create a new match
-// @Description Create a new match with the provided information
+// @Description Add an unplayed match between two teams of a league to a week that has not been simulated yet, in which neither team plays. Matches are played by simulating their week.
 // @Tags matches
 // @Accept json
 // @Produce json
 // @Param match body model.Match true "Match information"
 // @Success 201 {object} model.Match
 // @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
 // @Router /matches [post]
 func (c *MatchController) CreateMatch(ctx *fiber.Ctx) error {
 	var match model.Match
@@ -98,15 +143,15 @@ func (c *MatchController) CreateMatch(ctx *fiber.Ctx) error {
 	}
 
 	if err := c.service.Create(ctx.Context(), &match); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(match)
 }
 
 // UpdateMatch godoc
-// @Summary Update a match
-// @Description Update a match with the provided information
+// @Summary Reschedule a match
+// @Description Move an unplayed match to a later week of its league that has not been simulated yet, in which neither team plays. The league, teams and result cannot change here; results are edited through PUT /leagues/{id}/matches/{matchId}/result.
 // @Tags matches
 // @Accept json
 // @Produce json
@@ -114,7 +159,6 @@ func (c *MatchController) CreateMatch(ctx *fiber.Ctx) error {
 // @Param match body model.Match true "Match information"
 // @Success 200 {object} model.Match
 // @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
 // @Router /matches/{id} [put]
 func (c *MatchController) UpdateMatch(ctx *fiber.Ctx) error {
 	id, err := ctx.ParamsInt("id")
@@ -129,7 +173,7 @@ func (c *MatchController) UpdateMatch(ctx *fiber.Ctx) error {
 
 	match.ID = id
 	if err := c.service.Update(ctx.Context(), &match); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
 	}
 
 	return ctx.JSON(match)
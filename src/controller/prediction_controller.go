@@ -24,6 +24,7 @@ func NewPredictionController(service *service.PredictionService) *PredictionCont
 // @Accept json
 // @Produce json
 // @Param id path int true "League ID"
+// @Param seed query int false "Seed for the simulation, derived from the league seed by default"
 // @Success 200 {object} model.Standings
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -34,7 +35,12 @@ func (c *PredictionController) PredictFinalStandings(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
 	}
 
-	standings, err := c.service.PredictFinalStandings(ctx.Context(), id)
+	seed, err := parseSeed(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid seed"})
+	}
+
+	standings, err := c.service.PredictFinalStandings(ctx.Context(), id, seed)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
@@ -49,6 +55,9 @@ func (c *PredictionController) PredictFinalStandings(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path int true "League ID"
+// @Param seed query int false "Seed for the simulations, derived from the league seed by default"
+// @Param iterations query int false "Number of simulated seasons, 100 by default"
+// @Param workers query int false "Number of goroutines running the simulations, one per CPU by default"
 // @Success 200 {object} model.PredictionResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -59,10 +68,91 @@ func (c *PredictionController) GetPredictionWithConfidence(ctx *fiber.Ctx) error
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Geçersiz liga ID"})
 	}
 
-	predictions, err := c.service.GetPredictionWithConfidence(ctx.Context(), id)
+	opts, err := parsePredictionOptions(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	predictions, err := c.service.GetPredictionWithConfidence(ctx.Context(), id, opts)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
 	}
 
 	return ctx.JSON(predictions)
 }
+
+// PredictScenario godoc
+// @Summary Predict a what-if scenario
+// @Description Predict the final standings with probabilities as if the given results had happened, without persisting anything, and compare them with the predictions from the real state of the league. Matches are identified by match_id or by home_team_id and away_team_id (plus week when the teams meet at the same ground more than once). Results of played matches replace the real ones.
+// @Tags predictions
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param seed query int false "Seed for the simulations of both predictions, derived from the league seed by default"
+// @Param iterations query int false "Number of simulated seasons of each prediction, 100 by default"
+// @Param workers query int false "Number of goroutines running the simulations, one per CPU by default"
+// @Param scenario body WhatIfRequest true "Hypothetical results"
+// @Success 200 {object} model.ScenarioPrediction
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/predictions/what-if [post]
+func (c *PredictionController) PredictScenario(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	opts, err := parsePredictionOptions(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	var request WhatIfRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if len(request.Results) == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "At least one result is required"})
+	}
+
+	prediction, err := c.service.PredictScenario(ctx.Context(), id, request.Results, opts)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(prediction)
+}
+
+// GetBacktest godoc
+// @Summary Backtest the predictions of a finished league
+// @Description Replay the predictions the league would have got after each of its weeks, using only the results known then, and score them against the final table for every match engine: Brier score, log loss, Spearman rank correlation and a calibration table
+// @Tags predictions
+// @Accept json
+// @Produce json
+// @Param id path int true "League ID"
+// @Param seed query int false "Seed for the simulations, derived from the league seed and the week by default"
+// @Param iterations query int false "Number of simulated seasons per prediction, 100 by default"
+// @Param workers query int false "Number of goroutines running the simulations, one per CPU by default"
+// @Success 200 {object} model.BacktestReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /leagues/{id}/prediction-backtest [get]
+func (c *PredictionController) GetBacktest(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid league ID"})
+	}
+
+	opts, err := parsePredictionOptions(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	report, err := c.service.Backtest(ctx.Context(), id, opts)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(report)
+}
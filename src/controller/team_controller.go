@@ -176,3 +176,164 @@ func (c *TeamController) CreateInitialTeams(ctx *fiber.Ctx) error {
 	log.Printf("Successfully created %d initial teams", len(teams))
 	return ctx.Status(fiber.StatusCreated).JSON(teams)
 }
+
+// GetPlayers godoc
+// @Summary Get the squad of a team
+// @Description Get every player of a team, ordered by shirt number
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {array} model.Player
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /teams/{id}/players [get]
+func (c *TeamController) GetPlayers(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	players, err := c.service.GetPlayers(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(players)
+}
+
+// GetPlayer godoc
+// @Summary Get a player of a team
+// @Description Get a specific player of a team's squad
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param playerId path int true "Player ID"
+// @Success 200 {object} model.Player
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /teams/{id}/players/{playerId} [get]
+func (c *TeamController) GetPlayer(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	playerID, err := ctx.ParamsInt("playerId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid player ID"})
+	}
+
+	player, err := c.service.GetPlayer(ctx.Context(), id, playerID)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(player)
+}
+
+// CreatePlayer godoc
+// @Summary Add a player to a team
+// @Description Add a player to a team's squad. Teams with squad_strength get their strength from the best line-up of the squad.
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param player body model.Player true "Player information"
+// @Success 201 {object} model.Player
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /teams/{id}/players [post]
+func (c *TeamController) CreatePlayer(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	var player model.Player
+	if err := ctx.BodyParser(&player); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if err := player.Validate(); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if err := c.service.AddPlayer(ctx.Context(), id, &player); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(player)
+}
+
+// UpdatePlayer godoc
+// @Summary Update a player of a team
+// @Description Update a player of a team's squad with the provided information
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param playerId path int true "Player ID"
+// @Param player body model.Player true "Player information"
+// @Success 200 {object} model.Player
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /teams/{id}/players/{playerId} [put]
+func (c *TeamController) UpdatePlayer(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	playerID, err := ctx.ParamsInt("playerId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid player ID"})
+	}
+
+	var player model.Player
+	if err := ctx.BodyParser(&player); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if err := player.Validate(); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	player.ID = playerID
+	if err := c.service.UpdatePlayer(ctx.Context(), id, &player); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(player)
+}
+
+// DeletePlayer godoc
+// @Summary Remove a player from a team
+// @Description Remove a player from a team's squad
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Param playerId path int true "Player ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /teams/{id}/players/{playerId} [delete]
+func (c *TeamController) DeletePlayer(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid team ID"})
+	}
+
+	playerID, err := ctx.ParamsInt("playerId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid player ID"})
+	}
+
+	if err := c.service.DeletePlayer(ctx.Context(), id, playerID); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(SuccessResponse{Result: "success"})
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/league-simulator/src/model"
+	"github.com/user/league-simulator/src/service"
+)
+
+// TournamentController handles HTTP requests for group stage plus knockout tournaments
+type TournamentController struct {
+	service *service.TournamentService
+}
+
+// NewTournamentController creates a new TournamentController
+func NewTournamentController(service *service.TournamentService) *TournamentController {
+	return &TournamentController{
+		service: service,
+	}
+}
+
+// GetTournaments godoc
+// @Summary Get all tournaments
+// @Description Get a list of all group stage plus knockout tournaments
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.Tournament
+// @Failure 500 {object} ErrorResponse
+// @Router /tournaments [get]
+func (c *TournamentController) GetTournaments(ctx *fiber.Ctx) error {
+	tournaments, err := c.service.GetAll(ctx.Context())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(tournaments)
+}
+
+// CreateTournament godoc
+// @Summary Create a new tournament
+// @Description Draw the provided teams (all teams when team_ids is omitted) into groups from pots of decreasing strength. The best teams of every group advance to a knockout stage drawn once all groups have finished.
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param tournament body CreateTournamentRequest true "Tournament information"
+// @Success 201 {object} model.TournamentView
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tournaments [post]
+func (c *TournamentController) CreateTournament(ctx *fiber.Ctx) error {
+	var request CreateTournamentRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	if request.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Tournament name is required"})
+	}
+
+	if request.Groups < 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Groups must be at least 1"})
+	}
+
+	if request.AdvancePerGroup < 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Advance per group must be at least 1"})
+	}
+
+	if request.GroupRounds < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Group rounds must be 1 or more"})
+	}
+
+	if _, err := model.NewMatchSimulator(request.Engine); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if request.Engine.Modifiers != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Engine modifiers only apply to leagues"})
+	}
+
+	if _, err := model.ResolveTieBreakers(request.TieBreakers); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	tournament, err := c.service.Create(ctx.Context(), request.Name, model.TournamentOptions{
+		TeamIDs:         request.TeamIDs,
+		Groups:          request.Groups,
+		AdvancePerGroup: request.AdvancePerGroup,
+		GroupRounds:     request.GroupRounds,
+		TwoLegged:       request.TwoLegged,
+		TwoLeggedFinal:  request.TwoLeggedFinal,
+		Engine:          request.Engine,
+		TieBreakers:     request.TieBreakers,
+		Seed:            request.Seed,
+	})
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(tournament)
+}
+
+// GetTournament godoc
+// @Summary Get a tournament by ID
+// @Description Get the phase, the sorted group tables and the knockout bracket of a tournament
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param id path int true "Tournament ID"
+// @Success 200 {object} model.TournamentView
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tournaments/{id} [get]
+func (c *TournamentController) GetTournament(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid tournament ID"})
+	}
+
+	tournament, err := c.service.GetByID(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(tournament)
+}
+
+// SimulateGroupWeek godoc
+// @Summary Simulate the next group week
+// @Description Play the next week of every group. After the last group week the qualifiers are drawn into the knockout stage, group winners first.
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param id path int true "Tournament ID"
+// @Success 200 {object} model.TournamentView
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tournaments/{id}/groups/simulate [post]
+func (c *TournamentController) SimulateGroupWeek(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid tournament ID"})
+	}
+
+	tournament, err := c.service.SimulateGroupWeek(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(tournament)
+}
+
+// SimulateKnockoutRound godoc
+// @Summary Simulate the next knockout round
+// @Description Play every tie of the next knockout round of a tournament whose group stage has finished
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param id path int true "Tournament ID"
+// @Success 200 {object} model.TournamentView
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tournaments/{id}/knockout/simulate [post]
+func (c *TournamentController) SimulateKnockoutRound(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid tournament ID"})
+	}
+
+	tournament, err := c.service.SimulateKnockoutRound(ctx.Context(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	return ctx.JSON(tournament)
+}
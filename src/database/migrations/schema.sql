@@ -49,6 +49,239 @@ CREATE TABLE IF NOT EXISTS leagues (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Schedule format: number of round robins played (1 = single, 2 = double, ...)
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS rounds INTEGER NOT NULL DEFAULT 1 CHECK (rounds >= 1);
+
+-- Match engine and its parameters
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS engine VARCHAR(50) NOT NULL DEFAULT 'linear';
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS engine_params JSONB NOT NULL DEFAULT '{}';
+
+-- Strength modifiers for form, momentum and fatigue, JSON null when none is enabled
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS engine_modifiers JSONB NOT NULL DEFAULT 'null';
+
+-- Seeds for reproducible simulations: every league has a seed, every played
+-- match records the seed of the week it was simulated in
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT (floor(random() * 4503599627370496))::BIGINT;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS seed BIGINT;
+
+-- Create league_teams table (league membership)
+CREATE TABLE IF NOT EXISTS league_teams (
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (league_id, team_id)
+);
+
+-- Scope matches and standings to a league.
+-- Databases created before multi-league support have no league_id column;
+-- their data is backfilled into the seeded "Premier League".
+DO $$
+DECLARE
+    default_league_id INTEGER;
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'matches' AND column_name = 'league_id'
+    ) THEN
+        ALTER TABLE matches ADD COLUMN league_id INTEGER REFERENCES leagues(id) ON DELETE CASCADE;
+        ALTER TABLE standings_history ADD COLUMN league_id INTEGER REFERENCES leagues(id) ON DELETE CASCADE;
+
+        SELECT MIN(id) INTO default_league_id FROM leagues WHERE name = 'Premier League';
+
+        IF default_league_id IS NOT NULL THEN
+            UPDATE matches SET league_id = default_league_id;
+            UPDATE standings_history SET league_id = default_league_id;
+
+            INSERT INTO league_teams (league_id, team_id)
+            SELECT default_league_id, id FROM teams
+            ON CONFLICT DO NOTHING;
+        END IF;
+
+        ALTER TABLE matches ALTER COLUMN league_id SET NOT NULL;
+        ALTER TABLE standings_history ALTER COLUMN league_id SET NOT NULL;
+
+        ALTER TABLE standings_history DROP CONSTRAINT IF EXISTS standings_history_team_id_week_key;
+        ALTER TABLE standings_history ADD CONSTRAINT standings_history_league_id_team_id_week_key
+            UNIQUE (league_id, team_id, week);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_matches_league_week ON matches (league_id, week);
+
+-- Create byes table (teams sitting out a week in odd-sized leagues)
+CREATE TABLE IF NOT EXISTS byes (
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (league_id, week, team_id)
+);
+
+ALTER TABLE standings_history ADD COLUMN IF NOT EXISTS byes INTEGER DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_standings_history_league_week ON standings_history (league_id, week);
+
+-- Elo rating settings of a league, ratings are disabled when empty
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS rating_config JSONB NOT NULL DEFAULT '{}';
+
+-- Ordered tie-breaker rules of the league table
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS tie_breakers JSONB NOT NULL DEFAULT '["points", "goal_difference", "goals_for"]';
+
+-- Scoring rules of the league and the shootouts deciding drawn matches
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS scoring JSONB NOT NULL DEFAULT '{"win_points": 3, "draw_points": 1}';
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_penalties INTEGER;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_penalties INTEGER;
+ALTER TABLE standings_history ADD COLUMN IF NOT EXISTS bonus_points INTEGER DEFAULT 0;
+ALTER TABLE standings_history ADD COLUMN IF NOT EXISTS points_deducted INTEGER DEFAULT 0;
+
+-- Administrative point deductions, applied to the standings from their week onwards
+CREATE TABLE IF NOT EXISTS point_deductions (
+    id SERIAL PRIMARY KEY,
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    points INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    week INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_point_deductions_league ON point_deductions (league_id, week);
+
+-- Create team_ratings table (Elo rating of every team after every week, week 0 is the initial rating)
+CREATE TABLE IF NOT EXISTS team_ratings (
+    league_id INTEGER NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (league_id, week, team_id)
+);
+
+-- Create cups table (single-elimination knockout competitions)
+CREATE TABLE IF NOT EXISTS cups (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    current_round INTEGER NOT NULL DEFAULT 0,
+    total_rounds INTEGER NOT NULL,
+    two_legged BOOLEAN NOT NULL DEFAULT FALSE,
+    two_legged_final BOOLEAN NOT NULL DEFAULT FALSE,
+    draw VARCHAR(20) NOT NULL DEFAULT 'seeded',
+    engine VARCHAR(50) NOT NULL DEFAULT 'linear',
+    engine_params JSONB NOT NULL DEFAULT '{}',
+    seed BIGINT NOT NULL,
+    champion_id INTEGER REFERENCES teams(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create cup_teams table (cup entries)
+CREATE TABLE IF NOT EXISTS cup_teams (
+    cup_id INTEGER NOT NULL REFERENCES cups(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cup_id, team_id)
+);
+
+-- Create cup_ties table (bracket positions, teams are unknown until the previous round is played)
+CREATE TABLE IF NOT EXISTS cup_ties (
+    id SERIAL PRIMARY KEY,
+    cup_id INTEGER NOT NULL REFERENCES cups(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    slot INTEGER NOT NULL,
+    home_team_id INTEGER REFERENCES teams(id),
+    away_team_id INTEGER REFERENCES teams(id),
+    bye BOOLEAN NOT NULL DEFAULT FALSE,
+    home_penalties INTEGER,
+    away_penalties INTEGER,
+    winner_id INTEGER REFERENCES teams(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (cup_id, round, slot)
+);
+
+-- Create cup_legs table (matches of a tie)
+CREATE TABLE IF NOT EXISTS cup_legs (
+    tie_id INTEGER NOT NULL REFERENCES cup_ties(id) ON DELETE CASCADE,
+    leg INTEGER NOT NULL,
+    home_team_id INTEGER NOT NULL REFERENCES teams(id),
+    away_team_id INTEGER NOT NULL REFERENCES teams(id),
+    home_score INTEGER NOT NULL DEFAULT 0,
+    away_score INTEGER NOT NULL DEFAULT 0,
+    extra_time BOOLEAN NOT NULL DEFAULT FALSE,
+    played_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tie_id, leg)
+);
+
+-- Create tournaments table (group stage followed by a knockout stage)
+CREATE TABLE IF NOT EXISTS tournaments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    group_count INTEGER NOT NULL CHECK (group_count >= 1),
+    advance_per_group INTEGER NOT NULL CHECK (advance_per_group >= 1),
+    group_rounds INTEGER NOT NULL DEFAULT 1,
+    two_legged BOOLEAN NOT NULL DEFAULT FALSE,
+    two_legged_final BOOLEAN NOT NULL DEFAULT FALSE,
+    engine VARCHAR(50) NOT NULL DEFAULT 'linear',
+    engine_params JSONB NOT NULL DEFAULT '{}',
+    seed BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Groups of a tournament are leagues, its knockout stage is a cup
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS tournament_id INTEGER REFERENCES tournaments(id) ON DELETE CASCADE;
+ALTER TABLE cups ADD COLUMN IF NOT EXISTS tournament_id INTEGER REFERENCES tournaments(id) ON DELETE CASCADE;
+
+-- Qualification and relegation zones of the league table, the defaults when empty
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS zones JSONB NOT NULL DEFAULT '[]';
+
+-- Create players table (squads of the teams)
+CREATE TABLE IF NOT EXISTS players (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position VARCHAR(2) NOT NULL CHECK (position IN ('GK', 'DF', 'MF', 'FW')),
+    shirt_number INTEGER NOT NULL CHECK (shirt_number >= 1 AND shirt_number <= 99),
+    attack INTEGER NOT NULL CHECK (attack >= 1 AND attack <= 100),
+    defence INTEGER NOT NULL CHECK (defence >= 1 AND defence <= 100),
+    goalkeeping INTEGER NOT NULL CHECK (goalkeeping >= 1 AND goalkeeping <= 100),
+    age INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (team_id, shirt_number)
+);
+
+-- Teams whose strength is derived from their squad
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS squad_strength BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create match_events table (timelines of matches simulated by the event engine)
+CREATE TABLE IF NOT EXISTS match_events (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    minute INTEGER NOT NULL CHECK (minute >= 1),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('goal', 'yellow_card', 'red_card', 'substitution', 'injury')),
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    player_id INTEGER REFERENCES players(id) ON DELETE SET NULL,
+    related_player_id INTEGER REFERENCES players(id) ON DELETE SET NULL,
+    detail VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_match_events_match ON match_events (match_id, minute);
+
+-- Weeks an injured player is out for after the match
+ALTER TABLE match_events ADD COLUMN IF NOT EXISTS weeks INTEGER NOT NULL DEFAULT 0 CHECK (weeks >= 0);
+
+-- Starting line-ups of the matches simulated by the event engine, goalkeeper first
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_lineup JSONB;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_lineup JSONB;
+
+-- Bans players of the league get for their cards
+ALTER TABLE leagues ADD COLUMN IF NOT EXISTS discipline JSONB NOT NULL DEFAULT '{"yellow_card_limit": 5, "yellow_card_ban": 1, "second_yellow_ban": 1, "red_card_ban": 3}';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cups_tournament ON cups (tournament_id);
+
+-- Clinched and eliminated position bands of the teams, worked out when the snapshot is stored
+ALTER TABLE standings_history ADD COLUMN IF NOT EXISTS clinch JSONB;
+
 -- Create function to update timestamps
 CREATE OR REPLACE FUNCTION update_timestamp()
 RETURNS TRIGGER AS $$
@@ -63,6 +296,11 @@ DROP TRIGGER IF EXISTS update_teams_timestamp ON teams;
 DROP TRIGGER IF EXISTS update_matches_timestamp ON matches;
 DROP TRIGGER IF EXISTS update_standings_history_timestamp ON standings_history;
 DROP TRIGGER IF EXISTS update_leagues_timestamp ON leagues;
+DROP TRIGGER IF EXISTS update_team_ratings_timestamp ON team_ratings;
+DROP TRIGGER IF EXISTS update_cups_timestamp ON cups;
+DROP TRIGGER IF EXISTS update_cup_ties_timestamp ON cup_ties;
+DROP TRIGGER IF EXISTS update_tournaments_timestamp ON tournaments;
+DROP TRIGGER IF EXISTS update_players_timestamp ON players;
 
 -- Create triggers for updated_at columns
 CREATE TRIGGER update_teams_timestamp
@@ -80,3 +318,23 @@ FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
 CREATE TRIGGER update_leagues_timestamp
 BEFORE UPDATE ON leagues
 FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_team_ratings_timestamp
+BEFORE UPDATE ON team_ratings
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_cups_timestamp
+BEFORE UPDATE ON cups
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_cup_ties_timestamp
+BEFORE UPDATE ON cup_ties
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_tournaments_timestamp
+BEFORE UPDATE ON tournaments
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
+
+CREATE TRIGGER update_players_timestamp
+BEFORE UPDATE ON players
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
@@ -1,43 +1,57 @@
 -- Seed data for teams
-INSERT INTO teams (name, strength) VALUES
-    ('Manchester United', 85),
-    ('Liverpool', 88),
-    ('Chelsea', 82),
-    ('Arsenal', 80)
+INSERT INTO teams (id, name, strength) VALUES
+    (1, 'Manchester United', 85),
+    (2, 'Liverpool', 88),
+    (3, 'Chelsea', 82),
+    (4, 'Arsenal', 80)
 ON CONFLICT (id) DO NOTHING;
 
 -- Seed data for a league
-INSERT INTO leagues (name, current_week, total_weeks)
-VALUES ('Premier League', 0, 3)
+INSERT INTO leagues (id, name, current_week, total_weeks)
+VALUES (1, 'Premier League', 0, 3)
 ON CONFLICT (id) DO NOTHING;
 
+-- League membership
+INSERT INTO league_teams (league_id, team_id)
+VALUES
+    (1, 1),
+    (1, 2),
+    (1, 3),
+    (1, 4)
+ON CONFLICT DO NOTHING;
+
 -- Seed data for matches (round-robin tournament for 4 teams)
 -- Week 1
-INSERT INTO matches (home_team_id, away_team_id, week, played)
-VALUES 
-    (1, 2, 1, false),
-    (3, 4, 1, false)
+INSERT INTO matches (id, league_id, home_team_id, away_team_id, week, played)
+VALUES
+    (1, 1, 1, 2, 1, false),
+    (2, 1, 3, 4, 1, false)
 ON CONFLICT (id) DO NOTHING;
 
 -- Week 2
-INSERT INTO matches (home_team_id, away_team_id, week, played)
-VALUES 
-    (1, 3, 2, false),
-    (2, 4, 2, false)
+INSERT INTO matches (id, league_id, home_team_id, away_team_id, week, played)
+VALUES
+    (3, 1, 1, 3, 2, false),
+    (4, 1, 2, 4, 2, false)
 ON CONFLICT (id) DO NOTHING;
 
 -- Week 3
-INSERT INTO matches (home_team_id, away_team_id, week, played)
-VALUES 
-    (1, 4, 3, false),
-    (2, 3, 3, false)
+INSERT INTO matches (id, league_id, home_team_id, away_team_id, week, played)
+VALUES
+    (5, 1, 1, 4, 3, false),
+    (6, 1, 2, 3, 3, false)
 ON CONFLICT (id) DO NOTHING;
 
 -- Initialize standings for each team at week 0
-INSERT INTO standings_history (team_id, week, points, played, wins, draws, losses, goals_for, goals_against)
+INSERT INTO standings_history (league_id, team_id, week, points, played, wins, draws, losses, goals_for, goals_against)
 VALUES
-    (1, 0, 0, 0, 0, 0, 0, 0, 0),
-    (2, 0, 0, 0, 0, 0, 0, 0, 0),
-    (3, 0, 0, 0, 0, 0, 0, 0, 0),
-    (4, 0, 0, 0, 0, 0, 0, 0, 0)
-ON CONFLICT (team_id, week) DO NOTHING;
+    (1, 1, 0, 0, 0, 0, 0, 0, 0, 0),
+    (1, 2, 0, 0, 0, 0, 0, 0, 0, 0),
+    (1, 3, 0, 0, 0, 0, 0, 0, 0, 0),
+    (1, 4, 0, 0, 0, 0, 0, 0, 0, 0)
+ON CONFLICT (league_id, team_id, week) DO NOTHING;
+
+-- Keep serial sequences ahead of the explicit ids above
+SELECT setval(pg_get_serial_sequence('teams', 'id'), (SELECT MAX(id) FROM teams));
+SELECT setval(pg_get_serial_sequence('leagues', 'id'), (SELECT MAX(id) FROM leagues));
+SELECT setval(pg_get_serial_sequence('matches', 'id'), (SELECT MAX(id) FROM matches));
@@ -0,0 +1,248 @@
+package model
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Reasons a player misses matches
+const (
+	AbsenceInjury     = "injury"
+	AbsenceSuspension = "suspension"
+)
+
+// youthRating is the rating of the youth player filling a place of the line-up nobody in the squad can fill
+const youthRating = 30.0
+
+// Absence is a player missing matches of the league, injured or banned
+type Absence struct {
+	PlayerID   int    `json:"player_id"`
+	PlayerName string `json:"player_name,omitempty"`
+	TeamID     int    `json:"team_id"`
+	Reason     string `json:"reason"`                // injury or suspension
+	Since      int    `json:"since_week"`            // Week of the match the injury or the ban came from
+	ReturnWeek int    `json:"return_week,omitempty"` // First week the player is available again, omitted when not this season
+}
+
+// Absences returns the players who miss the next week of the league
+func (l *League) Absences() []*Absence {
+	names := l.playerNames()
+	absences := make([]*Absence, 0)
+
+	week := l.CurrentWeek + 1
+	state := l.SeasonStateBefore(l.Matches, week)
+	for _, team := range l.Teams {
+		for _, absence := range state.team(team.ID).availability.absences(l, team.ID, week) {
+			absence.PlayerName = names[absence.PlayerID]
+			absences = append(absences, absence)
+		}
+	}
+
+	sort.SliceStable(absences, func(i, j int) bool {
+		if absences[i].TeamID != absences[j].TeamID {
+			return absences[i].TeamID < absences[j].TeamID
+		}
+		return absences[i].PlayerID < absences[j].PlayerID
+	})
+
+	return absences
+}
+
+// availability is what the played matches of a team leave it without: the
+// players injured or banned, and the yellow cards counting towards the next
+// ban. It is moved on match by match, see SeasonState.
+type availability struct {
+	injuries map[int]injurySpell // Longest injury of every player
+	bans     map[int][]banSpell  // Bans of every player still to serve, in the order they were earned
+	yellows  map[int]int         // Yellow cards of every player
+}
+
+// injurySpell is an injury keeping a player out
+type injurySpell struct {
+	since   int // Week of the match the injury came from
+	through int // Last week the player is out
+}
+
+// banSpell is a ban a player has still to serve
+type banSpell struct {
+	since int // Week of the match the ban was earned in
+	left  int // Matches of the team still to serve
+}
+
+// clone returns a copy of the availability to move on separately
+func (a *availability) clone() availability {
+	clone := availability{injuries: maps.Clone(a.injuries), bans: maps.Clone(a.bans), yellows: maps.Clone(a.yellows)}
+	for playerID, bans := range clone.bans {
+		clone.bans[playerID] = slices.Clone(bans)
+	}
+	return clone
+}
+
+// record moves the availability of the team on past one of its played
+// matches, which must not be earlier than the matches recorded before
+func (a *availability) record(l *League, match *Match, teamID int) {
+	// The match is served by the bans earned before it
+	for playerID, bans := range a.bans {
+		left := bans[:0]
+		for _, ban := range bans {
+			if ban.since < match.Week {
+				ban.left--
+			}
+			if ban.left > 0 {
+				left = append(left, ban)
+			}
+		}
+		if len(left) == 0 {
+			delete(a.bans, playerID)
+			continue
+		}
+		a.bans[playerID] = left
+	}
+
+	for playerID, injury := range a.injuries {
+		if injury.through < match.Week {
+			delete(a.injuries, playerID)
+		}
+	}
+	for _, event := range match.Events {
+		if event.Type != EventInjury || event.TeamID != teamID || event.PlayerID == nil {
+			continue
+		}
+		through := match.Week + event.Weeks
+		if current, ok := a.injuries[*event.PlayerID]; !ok || current.through < through {
+			if a.injuries == nil {
+				a.injuries = make(map[int]injurySpell)
+			}
+			a.injuries[*event.PlayerID] = injurySpell{since: match.Week, through: through}
+		}
+	}
+
+	if len(match.Events) == 0 {
+		return
+	}
+	if a.yellows == nil {
+		a.yellows = make(map[int]int)
+	}
+	for _, ban := range l.matchBans(match, teamID, a.yellows) {
+		if a.bans == nil {
+			a.bans = make(map[int][]banSpell)
+		}
+		a.bans[ban.PlayerID] = append(a.bans[ban.PlayerID], banSpell{since: ban.Week, left: ban.Matches})
+	}
+}
+
+// out reports whether a player misses the team's match of the given week
+func (a *availability) out(playerID, week int) bool {
+	if injury, ok := a.injuries[playerID]; ok && injury.through >= week {
+		return true
+	}
+	return len(a.bans[playerID]) > 0
+}
+
+// absences returns the players who miss the team's match of the given week
+func (a *availability) absences(l *League, teamID, week int) map[int]*Absence {
+	absent := make(map[int]*Absence)
+
+	for playerID, injury := range a.injuries {
+		if injury.through >= week {
+			absent[playerID] = &Absence{PlayerID: playerID, TeamID: teamID, Reason: AbsenceInjury, Since: injury.since, ReturnWeek: injury.through + 1}
+		}
+	}
+
+	for playerID, bans := range a.bans {
+		for _, ban := range bans {
+			// A ban outlasting an injury keeps the player out longest
+			returnWeek := l.weekAfterMatches(teamID, week, ban.left)
+			if current, ok := absent[playerID]; ok && (current.ReturnWeek == 0 || (returnWeek != 0 && current.ReturnWeek >= returnWeek)) {
+				continue
+			}
+			absent[playerID] = &Absence{PlayerID: playerID, TeamID: teamID, Reason: AbsenceSuspension, Since: ban.since, ReturnWeek: returnWeek}
+		}
+	}
+
+	for _, absence := range absent {
+		if absence.ReturnWeek > l.TotalWeeks {
+			absence.ReturnWeek = 0
+		}
+	}
+
+	return absent
+}
+
+// weekAfterMatches returns the week of the team's first match once it has
+// played the given number of matches from the given week on, 0 when the
+// season ends before
+func (l *League) weekAfterMatches(teamID, week, matches int) int {
+	weeks := make([]int, 0)
+	for _, match := range l.Matches {
+		if match.Week >= week && (match.HomeTeamID == teamID || match.AwayTeamID == teamID) {
+			weeks = append(weeks, match.Week)
+		}
+	}
+	sort.Ints(weeks)
+
+	if matches < len(weeks) {
+		return weeks[matches]
+	}
+	return 0
+}
+
+// matchSide returns a team as it lines up for its match of the given week,
+// without the players the season state has injured or banned. Its strength
+// is scaled by the league's strength modifiers, following the recent results
+// of the state, and by how much of its best line-up is left.
+func (l *League) matchSide(team *Team, state *SeasonState, week int) MatchSide {
+	recorded := state.team(team.ID)
+	side := MatchSide{Team: team, Strength: l.EffectiveStrength(team) * l.formFactor(recorded, week)}
+	if len(team.Players) == 0 {
+		return side
+	}
+
+	available := make([]*Player, 0, len(team.Players))
+	for _, player := range team.Players {
+		if !recorded.availability.out(player.ID, week) {
+			available = append(available, player)
+		}
+	}
+	if len(available) == len(team.Players) {
+		return side
+	}
+
+	weakened := *team
+	weakened.Players = available
+	side.Team = &weakened
+	side.Strength *= LineupRating(available) / LineupRating(team.Players)
+
+	return side
+}
+
+// LineupRating returns the mean rating of the best line-up the players make
+// up, see BestLineup. Places nobody can fill count as youth players.
+func LineupRating(players []*Player) float64 {
+	goalkeeper := 0.0
+	outfield := make([]float64, 0, len(players))
+	for _, player := range players {
+		if player.Position == PositionGoalkeeper {
+			goalkeeper = math.Max(goalkeeper, player.Rating())
+			continue
+		}
+		outfield = append(outfield, player.Rating())
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(outfield)))
+
+	if goalkeeper == 0 {
+		goalkeeper = youthRating
+	}
+	total := goalkeeper
+	for i := 0; i < startingOutfielders; i++ {
+		if i < len(outfield) {
+			total += outfield[i]
+		} else {
+			total += youthRating
+		}
+	}
+
+	return total / (startingOutfielders + 1)
+}
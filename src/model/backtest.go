@@ -0,0 +1,248 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// calibrationBins is the number of equal-width probability ranges of a calibration table
+const calibrationBins = 10
+
+// BacktestReport compares the predictions a finished league would have got
+// after each of its weeks with its final table, for every match engine
+type BacktestReport struct {
+	LeagueID   int               `json:"league_id"`
+	TotalWeeks int               `json:"total_weeks"`
+	Iterations int               `json:"iterations"` // Simulated seasons per prediction
+	Final      *Standings        `json:"final_standings"`
+	Engines    []*EngineBacktest `json:"engines"`
+}
+
+// EngineBacktest holds the accuracy of one match engine's predictions,
+// averaged over the weeks and week by week
+type EngineBacktest struct {
+	Engine          EngineConfig      `json:"engine"`
+	BrierScore      float64           `json:"brier_score"`
+	LogLoss         float64           `json:"log_loss"`
+	RankCorrelation float64           `json:"rank_correlation"`
+	Calibration     []*CalibrationBin `json:"calibration"`
+	Weeks           []*ForecastScore  `json:"weeks"`
+}
+
+// ForecastScore rates the predicted position probabilities of one week
+// against the final table. The Brier score is the squared error summed over
+// the positions and averaged over the teams, from 0 (perfect) to 2. The log
+// loss is the average negative log probability given to the actual
+// positions. The rank correlation is Spearman's, between the expected and
+// the actual positions.
+type ForecastScore struct {
+	Week            int     `json:"week"`
+	BrierScore      float64 `json:"brier_score"`
+	LogLoss         float64 `json:"log_loss"`
+	RankCorrelation float64 `json:"rank_correlation"`
+}
+
+// CalibrationBin compares the probabilities forecast within a range with how
+// often the forecast positions actually came about
+type CalibrationBin struct {
+	From      float64 `json:"from"` // Probability range, in percent
+	To        float64 `json:"to"`
+	Forecasts int     `json:"forecasts"`
+	Hits      int     `json:"hits"`          // Forecasts that came about
+	Forecast  float64 `json:"mean_forecast"` // Mean forecast probability, in percent
+	Observed  float64 `json:"observed"`      // Share of the forecasts that came about, in percent
+
+	sum float64 // Sum of the forecast probabilities
+}
+
+// AsOfWeek returns a copy of the league as it stood at the end of the given
+// week, with only the results, deductions and ratings known then and the
+// given match engine. The league itself is not modified.
+func (l *League) AsOfWeek(week int, engine EngineConfig) (*League, error) {
+	if week < 0 || week > l.CurrentWeek {
+		return nil, errors.New("week must be between 0 and the current week")
+	}
+
+	past := &League{
+		ID:          l.ID,
+		Name:        l.Name,
+		Teams:       l.Teams,
+		CurrentWeek: l.CurrentWeek,
+		TotalWeeks:  l.TotalWeeks,
+		Rounds:      l.Rounds,
+		Byes:        l.Byes,
+		Rating:      l.Rating,
+		TieBreakers: l.TieBreakers,
+		Scoring:     l.Scoring,
+		Discipline:  l.Discipline,
+		Zones:       l.Zones,
+		Seed:        l.Seed,
+	}
+
+	if err := past.SetEngine(engine); err != nil {
+		return nil, err
+	}
+
+	// Rewind keeps later deductions, which were not known then
+	for _, deduction := range l.Deductions {
+		if deduction.Week <= week {
+			past.Deductions = append(past.Deductions, deduction)
+		}
+	}
+
+	past.Matches = make([]*Match, len(l.Matches))
+	for i, match := range l.Matches {
+		copied := *match
+		past.Matches[i] = &copied
+	}
+
+	if _, err := past.Rewind(week); err != nil {
+		return nil, err
+	}
+	past.RebuildRatings(past.InitialRatings())
+
+	return past, nil
+}
+
+// FinalPositions returns the position of every team in sorted standings, 1 being the top
+func FinalPositions(standings *Standings) map[int]int {
+	positions := make(map[int]int, len(standings.Teams))
+	for i, team := range standings.Teams {
+		positions[team.TeamID] = i + 1
+	}
+	return positions
+}
+
+// ScoreForecast rates position probabilities, in percent per team and
+// position, against the actual positions. Probabilities estimated from n
+// simulations are never taken below half a simulation, so that an outcome
+// no simulation produced does not make the log loss infinite.
+func ScoreForecast(week int, probabilities map[int][]float64, actual map[int]int, n int) *ForecastScore {
+	score := &ForecastScore{Week: week}
+	if len(probabilities) == 0 {
+		return score
+	}
+
+	floor := 0.5 / float64(n)
+	teams := sortedTeams(probabilities)
+	expected := make([]float64, 0, len(teams))
+	positions := make([]float64, 0, len(teams))
+	for _, teamID := range teams {
+		mean := 0.0
+		for pos, p := range probabilities[teamID] {
+			p /= 100
+			outcome := 0.0
+			if pos+1 == actual[teamID] {
+				outcome = 1
+				score.LogLoss -= math.Log(math.Max(p, floor))
+			}
+			score.BrierScore += (p - outcome) * (p - outcome)
+			mean += p * float64(pos+1)
+		}
+		expected = append(expected, mean)
+		positions = append(positions, float64(actual[teamID]))
+	}
+
+	score.BrierScore /= float64(len(teams))
+	score.LogLoss /= float64(len(teams))
+	score.RankCorrelation = pearson(ranks(expected), ranks(positions))
+
+	return score
+}
+
+// NewCalibrationTable creates empty calibration bins covering 0 to 100 percent
+func NewCalibrationTable() []*CalibrationBin {
+	bins := make([]*CalibrationBin, calibrationBins)
+	width := 100.0 / calibrationBins
+	for i := range bins {
+		bins[i] = &CalibrationBin{From: float64(i) * width, To: float64(i+1) * width}
+	}
+	return bins
+}
+
+// AddToCalibration counts every team and position forecast in its bin
+func AddToCalibration(bins []*CalibrationBin, probabilities map[int][]float64, actual map[int]int) {
+	for _, teamID := range sortedTeams(probabilities) {
+		for pos, p := range probabilities[teamID] {
+			bin := int(p / (100.0 / calibrationBins))
+			if bin >= len(bins) {
+				bin = len(bins) - 1
+			}
+			bins[bin].Forecasts++
+			bins[bin].sum += p
+			if pos+1 == actual[teamID] {
+				bins[bin].Hits++
+			}
+		}
+	}
+
+	for _, bin := range bins {
+		if bin.Forecasts > 0 {
+			bin.Forecast = bin.sum / float64(bin.Forecasts)
+			bin.Observed = float64(bin.Hits) / float64(bin.Forecasts) * 100
+		}
+	}
+}
+
+// sortedTeams returns the team IDs of the probabilities in ascending order
+func sortedTeams(probabilities map[int][]float64) []int {
+	teams := make([]int, 0, len(probabilities))
+	for teamID := range probabilities {
+		teams = append(teams, teamID)
+	}
+	sort.Ints(teams)
+	return teams
+}
+
+// ranks returns the rank of every value, 1 for the lowest, ties sharing their average rank
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			result[i] = rank
+		}
+		start = end
+	}
+	return result
+}
+
+// pearson returns the correlation of two series, 0 when either does not vary
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	if n == 0 {
+		return 0
+	}
+
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range x {
+		cov += (x[i] - meanX) * (y[i] - meanY)
+		varX += (x[i] - meanX) * (x[i] - meanX)
+		varY += (y[i] - meanY) * (y[i] - meanY)
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
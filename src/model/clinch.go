@@ -0,0 +1,683 @@
+package model
+
+import "sort"
+
+// PositionBand is a range of table positions a team can clinch or be
+// eliminated from, 1 being the top of the table
+type PositionBand struct {
+	Name string `json:"name"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// DefaultPositionBands returns the title, top three and relegation bands of a
+// league with the given number of teams
+func DefaultPositionBands(teams int) []PositionBand {
+	topThree := 3
+	if teams < topThree {
+		topThree = teams
+	}
+
+	return []PositionBand{
+		{Name: "title", From: 1, To: 1},
+		{Name: "top_three", From: 1, To: topThree},
+		{Name: "relegation", From: teams, To: teams},
+	}
+}
+
+// ClinchStatus tells whether a team's place in a band is mathematically decided
+type ClinchStatus struct {
+	Band           string `json:"band"`
+	Clinched       bool   `json:"clinched"`
+	Eliminated     bool   `json:"eliminated"`
+	MagicNumber    *int   `json:"magic_number,omitempty"`    // Points the team still needs to be out of reach, see Clinches
+	ClinchedWeek   *int   `json:"clinched_week,omitempty"`   // Week after which the band was clinched
+	EliminatedWeek *int   `json:"eliminated_week,omitempty"` // Week after which the team was eliminated from the band
+	Undecided      bool   `json:"undecided,omitempty"`       // The search ran out of budget before it could decide the band
+}
+
+// clinchSearchBudget caps the number of outcomes a single check may try.
+// A check running out of budget decides nothing and marks the band undecided.
+const clinchSearchBudget = 200000
+
+// Clinches works out for every team and band whether the team has clinched
+// the band or has been eliminated from it, by searching the results of the
+// remaining fixtures rather than sampling them. Level points count against a
+// team when clinching and for it when eliminating, so a decision holds
+// whatever the tie-breakers say; once every match has been played the final
+// table decides.
+//
+// Each check first splits the points of the rival fixtures as a maximum flow,
+// which rules a table out at once when no split of the points reaches it and
+// decides it outright under scoring rules where any split is a result. Only
+// the tables the flow cannot decide are searched result by result, and a
+// band whose search runs out of budget is reported as undecided rather than
+// as clinched or eliminated.
+//
+// The magic number is the number of points that puts a team out of reach of
+// all but the rivals the band leaves room for, even if they win every
+// remaining match. For a band at the bottom of the table it is the number of
+// points that keeps the team above the band. It is omitted when the team
+// cannot get there on its own.
+func (l *League) Clinches(bands []PositionBand) map[int][]*ClinchStatus {
+	current := l.Standings
+	snapshots := l.RebuildStandings(0)
+	l.Standings = current
+
+	analyses := make([]*clinchAnalysis, len(snapshots))
+	analysis := func(week int) *clinchAnalysis {
+		if analyses[week] == nil {
+			analyses[week] = l.newClinchAnalysis(snapshots[week])
+		}
+		return analyses[week]
+	}
+
+	now := analysis(l.CurrentWeek)
+	result := make(map[int][]*ClinchStatus, len(l.Teams))
+	for _, team := range l.Teams {
+		statuses := make([]*ClinchStatus, 0, len(bands))
+		for _, band := range bands {
+			status := &ClinchStatus{Band: band.Name}
+
+			now.exhausted = false
+			if now.clinched(team.ID, band) {
+				status.Clinched = true
+				week := l.CurrentWeek
+				for week > 0 && analysis(week-1).clinched(team.ID, band) {
+					week--
+				}
+				status.ClinchedWeek = &week
+			}
+
+			if now.eliminated(team.ID, band) {
+				status.Eliminated = true
+				week := l.CurrentWeek
+				for week > 0 && analysis(week-1).eliminated(team.ID, band) {
+					week--
+				}
+				status.EliminatedWeek = &week
+			}
+			status.Undecided = now.exhausted && !status.Clinched && !status.Eliminated
+
+			status.MagicNumber = now.magicNumber(team.ID, band)
+			statuses = append(statuses, status)
+		}
+		result[team.ID] = statuses
+	}
+
+	return result
+}
+
+// AnnotateClinches marks every team of the current standings with the bands
+// of the league's zones it has clinched or been eliminated from, see
+// Clinches. The marks are stored with the standings, so that they are worked
+// out once whenever the standings change rather than on every read.
+func (l *League) AnnotateClinches() {
+	clinches := l.Clinches(l.PositionZones())
+	for i := range l.Standings.Teams {
+		l.Standings.Teams[i].Clinch = clinches[l.Standings.Teams[i].TeamID]
+	}
+}
+
+// CurrentClinches returns the marks AnnotateClinches stored with the current
+// standings, working them out when a team has none
+func (l *League) CurrentClinches() map[int][]*ClinchStatus {
+	clinches := make(map[int][]*ClinchStatus, len(l.Standings.Teams))
+	for _, team := range l.Standings.Teams {
+		if team.Clinch == nil {
+			return l.Clinches(l.PositionZones())
+		}
+		clinches[team.TeamID] = team.Clinch
+	}
+	return clinches
+}
+
+// clinchOutcome is the points a result gives the home and the away team
+type clinchOutcome struct {
+	home, away int
+}
+
+// clinchAnalysis holds the table after a week and the fixtures still to play
+type clinchAnalysis struct {
+	teams     []int
+	points    map[int]int
+	position  map[int]int // Final positions, only set once every match has been played
+	fixtures  []*Match
+	outcomes  []clinchOutcome // Every distinct way a result can split points
+	best      clinchOutcome   // The home team takes the most and the away team the fewest points
+	minPts    int             // Fewest points a team can take from a match
+	maxPts    int             // Most points a team can take from a match
+	minTotal  int             // Fewest points a match hands out
+	maxTotal  int             // Most points a match hands out
+	exactCap  bool            // Every split of minTotal is a result, so flows keeping teams down decide
+	exactMet  bool            // Every split of maxTotal is a result, so flows lifting teams up decide
+	budget    int
+	exhausted bool // A check ran out of budget since last reset
+}
+
+// newClinchAnalysis prepares the analysis of the given standings snapshot
+func (l *League) newClinchAnalysis(standings *Standings) *clinchAnalysis {
+	a := &clinchAnalysis{
+		points:   make(map[int]int, len(standings.Teams)),
+		outcomes: l.Scoring.outcomes(),
+	}
+
+	table := Standings{Teams: append([]TeamStanding(nil), standings.Teams...)}
+	for _, match := range l.Matches {
+		if match.Week <= standings.Week {
+			continue
+		}
+		if match.Played && standings.Week == l.CurrentWeek {
+			// Results of later weeks fixed by a what-if scenario
+			table.UpdateStandings(match, l.Scoring)
+			continue
+		}
+		a.fixtures = append(a.fixtures, match)
+	}
+
+	for _, team := range table.Teams {
+		a.teams = append(a.teams, team.TeamID)
+		a.points[team.TeamID] = team.Points
+	}
+
+	if len(a.fixtures) == 0 && standings.Week >= l.TotalWeeks {
+		a.position = make(map[int]int, len(standings.Teams))
+		for i, team := range standings.Teams {
+			a.position[team.TeamID] = i + 1
+		}
+	}
+
+	a.best = a.outcomes[0]
+	a.minPts, a.maxPts = a.outcomes[0].home, a.outcomes[0].home
+	for _, o := range a.outcomes {
+		if o.home > a.best.home || (o.home == a.best.home && o.away < a.best.away) {
+			a.best = o
+		}
+		if o.home < a.minPts {
+			a.minPts = o.home
+		}
+		if o.home > a.maxPts {
+			a.maxPts = o.home
+		}
+	}
+
+	a.minTotal, a.maxTotal = a.best.home+a.best.away, a.best.home+a.best.away
+	for _, o := range a.outcomes {
+		a.minTotal = min(a.minTotal, o.home+o.away)
+		a.maxTotal = max(a.maxTotal, o.home+o.away)
+	}
+
+	// The flows give a team any share of a match's points, which has to be a
+	// result for them to decide: one at most the share when keeping teams
+	// down, one at least the share when lifting them up
+	a.exactCap, a.exactMet = true, true
+	spare := a.minTotal - 2*a.minPts
+	for x := 0; x <= spare; x++ {
+		a.exactCap = a.exactCap && a.hasOutcome(func(o clinchOutcome) bool {
+			return o.home <= a.minPts+x && o.away <= a.minPts+spare-x
+		})
+	}
+	for x := 0; x <= a.maxPts; x++ {
+		y := min(a.maxPts, a.maxTotal-x)
+		if y < 0 {
+			continue
+		}
+		a.exactMet = a.exactMet && a.hasOutcome(func(o clinchOutcome) bool {
+			return o.home >= x && o.away >= y
+		})
+	}
+
+	return a
+}
+
+// hasOutcome reports whether some result of a match satisfies the condition
+func (a *clinchAnalysis) hasOutcome(condition func(o clinchOutcome) bool) bool {
+	for _, o := range a.outcomes {
+		if condition(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// outcomes returns every distinct split of points a result can give. Scores
+// up to one goal beyond both bonus thresholds cover every combination of bonuses.
+func (r ScoringRules) outcomes() []clinchOutcome {
+	goals := 1
+	if r.ScoringBonusGoals > goals {
+		goals = r.ScoringBonusGoals
+	}
+	if r.LosingBonusMargin+1 > goals {
+		goals = r.LosingBonusMargin + 1
+	}
+
+	seen := make(map[clinchOutcome]bool)
+	var outcomes []clinchOutcome
+	add := func(home, away, shootout int) {
+		homePoints, _ := r.result(home, away, shootout)
+		awayPoints, _ := r.result(away, home, -shootout)
+		o := clinchOutcome{home: homePoints, away: awayPoints}
+		if !seen[o] {
+			seen[o] = true
+			outcomes = append(outcomes, o)
+		}
+	}
+	for home := 0; home <= goals+1; home++ {
+		for away := 0; away <= goals+1; away++ {
+			if home == away && r.DrawShootout {
+				add(home, away, 1)
+				add(home, away, -1)
+				continue
+			}
+			add(home, away, 0)
+		}
+	}
+
+	// Try the results giving away the fewest points first
+	sort.Slice(outcomes, func(i, j int) bool {
+		return outcomes[i].home+outcomes[i].away < outcomes[j].home+outcomes[j].away
+	})
+
+	return outcomes
+}
+
+// clinched reports whether the team is certain to finish within the band
+func (a *clinchAnalysis) clinched(teamID int, band PositionBand) bool {
+	if a.position != nil {
+		return a.position[teamID] >= band.From && a.position[teamID] <= band.To
+	}
+	return !a.canFinishBelow(teamID, band.To) && (band.From == 1 || !a.canFinishAbove(teamID, band.From-1))
+}
+
+// eliminated reports whether the team can no longer finish within the band
+func (a *clinchAnalysis) eliminated(teamID int, band PositionBand) bool {
+	if a.position != nil {
+		return a.position[teamID] < band.From || a.position[teamID] > band.To
+	}
+	return !a.canFinishAbove(teamID, band.To) || (band.From > 1 && !a.canFinishBelow(teamID, band.From-1))
+}
+
+// magicNumber returns the points the team still needs to be certain of
+// finishing in the top positions the band is about, nil when it cannot get
+// there on its own
+func (a *clinchAnalysis) magicNumber(teamID int, band PositionBand) *int {
+	top := band.To
+	if band.To >= len(a.teams) {
+		top = band.From - 1
+	}
+	if top < 1 || top >= len(a.teams) {
+		return nil
+	}
+
+	if a.position != nil {
+		if a.position[teamID] > top {
+			return nil
+		}
+		magic := 0
+		return &magic
+	}
+
+	magic := 0
+	if a.canFinishBelow(teamID, top) {
+		maximum := make([]int, 0, len(a.teams)-1)
+		for _, id := range a.teams {
+			if id != teamID {
+				maximum = append(maximum, a.points[id]+a.matchesLeft(id)*a.maxPts)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(maximum)))
+
+		magic = maximum[top-1] + 1 - a.points[teamID]
+		if magic < 0 {
+			magic = 0
+		}
+		if magic > a.matchesLeft(teamID)*a.maxPts {
+			return nil
+		}
+	}
+
+	return &magic
+}
+
+// matchesLeft returns the number of fixtures the team still has to play
+func (a *clinchAnalysis) matchesLeft(teamID int) int {
+	count := 0
+	for _, match := range a.fixtures {
+		if match.HomeTeamID == teamID || match.AwayTeamID == teamID {
+			count++
+		}
+	}
+	return count
+}
+
+// base returns the points of every team after the team's own fixtures have
+// gone its way (best) or against it, together with its own total
+func (a *clinchAnalysis) base(teamID int, best bool) (int, map[int]int) {
+	points := make(map[int]int, len(a.points))
+	for id, p := range a.points {
+		points[id] = p
+	}
+
+	for _, match := range a.fixtures {
+		var opponent int
+		switch teamID {
+		case match.HomeTeamID:
+			opponent = match.AwayTeamID
+		case match.AwayTeamID:
+			opponent = match.HomeTeamID
+		default:
+			continue
+		}
+		if best {
+			points[teamID] += a.best.home
+			points[opponent] += a.best.away
+		} else {
+			points[teamID] += a.best.away
+			points[opponent] += a.best.home
+		}
+	}
+
+	own := points[teamID]
+	delete(points, teamID)
+	return own, points
+}
+
+// rivalFixtures returns the fixtures not involving the team
+func (a *clinchAnalysis) rivalFixtures(teamID int) []*Match {
+	fixtures := make([]*Match, 0, len(a.fixtures))
+	for _, match := range a.fixtures {
+		if match.HomeTeamID != teamID && match.AwayTeamID != teamID {
+			fixtures = append(fixtures, match)
+		}
+	}
+	return fixtures
+}
+
+// canFinishAbove reports whether some results leave fewer than k other teams
+// with more points than the team, i.e. whether it can still finish in the top k
+func (a *clinchAnalysis) canFinishAbove(teamID, k int) bool {
+	if k >= len(a.teams) {
+		return true
+	}
+
+	own, points := a.base(teamID, true)
+	fixtures := a.rivalFixtures(teamID)
+	left := make(map[int]int, len(points))
+	for _, match := range fixtures {
+		left[match.HomeTeamID]++
+		left[match.AwayTeamID]++
+	}
+
+	// Teams finishing above the team whatever happens, and teams that may
+	forced, optional := 0, make([]int, 0)
+	for _, id := range a.sortedRivals(points) {
+		switch {
+		case points[id]+left[id]*a.minPts > own:
+			forced++
+		case points[id]+left[id]*a.maxPts > own:
+			optional = append(optional, id)
+		}
+	}
+	if forced >= k {
+		return false
+	}
+
+	// Some teams may finish above the team, the others have to stay at or below it
+	room := k - 1 - forced
+	if room >= len(optional) {
+		return true
+	}
+
+	a.budget = clinchSearchBudget
+	found := false
+	combinations(len(optional), room, func(above []bool) bool {
+		capped := make(map[int]int)
+		for i, id := range optional {
+			if !above[i] {
+				capped[id] = own - points[id]
+			}
+		}
+		found = a.search(fixtures, capped, false)
+		return found || a.budget <= 0
+	})
+
+	// Running out of budget decides nothing, so the team is not eliminated
+	if !found && a.budget <= 0 {
+		a.exhausted = true
+		return true
+	}
+	return found
+}
+
+// canFinishBelow reports whether some results give at least k other teams as
+// many points as the team, i.e. whether it can still drop out of the top k
+func (a *clinchAnalysis) canFinishBelow(teamID, k int) bool {
+	if k >= len(a.teams) {
+		return false
+	}
+
+	own, points := a.base(teamID, false)
+	fixtures := a.rivalFixtures(teamID)
+	left := make(map[int]int, len(points))
+	for _, match := range fixtures {
+		left[match.HomeTeamID]++
+		left[match.AwayTeamID]++
+	}
+
+	// Teams level with or above the team whatever happens, and teams that may be
+	forced, optional := 0, make([]int, 0)
+	for _, id := range a.sortedRivals(points) {
+		switch {
+		case points[id]+left[id]*a.minPts >= own:
+			forced++
+		case points[id]+left[id]*a.maxPts >= own:
+			optional = append(optional, id)
+		}
+	}
+	if forced >= k {
+		return true
+	}
+
+	// Enough of the other teams have to catch up with the team
+	need := k - forced
+	if need > len(optional) {
+		return false
+	}
+
+	a.budget = clinchSearchBudget
+	found := false
+	combinations(len(optional), need, func(chosen []bool) bool {
+		targets := make(map[int]int)
+		for i, id := range optional {
+			if chosen[i] {
+				targets[id] = own - points[id]
+			}
+		}
+		found = a.search(fixtures, targets, true)
+		return found || a.budget <= 0
+	})
+
+	// Running out of budget decides nothing, so the team is not clinched
+	if !found && a.budget <= 0 {
+		a.exhausted = true
+		return true
+	}
+	return found
+}
+
+// sortedRivals returns the other teams, most points first
+func (a *clinchAnalysis) sortedRivals(points map[int]int) []int {
+	ids := make([]int, 0, len(points))
+	for id := range points {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if points[ids[i]] != points[ids[j]] {
+			return points[ids[i]] > points[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// search looks for results of the fixtures that keep every bounded team at
+// or below its bound (reach false) or take it to at least its bound (reach
+// true). Matches against unbounded teams go the way that suits the bounded team.
+func (a *clinchAnalysis) search(fixtures []*Match, bounds map[int]int, reach bool) bool {
+	gained := make(map[int]int, len(bounds))
+	left := make(map[int]int, len(bounds))
+	var contested []*Match
+	for _, match := range fixtures {
+		_, home := bounds[match.HomeTeamID]
+		_, away := bounds[match.AwayTeamID]
+		switch {
+		case home && away:
+			contested = append(contested, match)
+			left[match.HomeTeamID]++
+			left[match.AwayTeamID]++
+		case home:
+			gained[match.HomeTeamID] += a.pick(reach)
+		case away:
+			gained[match.AwayTeamID] += a.pick(reach)
+		}
+	}
+
+	for id, bound := range bounds {
+		if !a.feasible(gained[id], left[id], bound, reach) {
+			return false
+		}
+	}
+
+	if possible, exact := a.flow(contested, gained, left, bounds, reach); !possible || exact {
+		return possible
+	}
+
+	var try func(i int) bool
+	try = func(i int) bool {
+		if i == len(contested) {
+			return true
+		}
+		a.budget--
+		if a.budget <= 0 {
+			return false
+		}
+
+		match := contested[i]
+		home, away := match.HomeTeamID, match.AwayTeamID
+		left[home]--
+		left[away]--
+		for j := range a.outcomes {
+			o := a.outcomes[j]
+			if reach {
+				o = a.outcomes[len(a.outcomes)-1-j]
+			}
+			gained[home] += o.home
+			gained[away] += o.away
+			ok := a.feasible(gained[home], left[home], bounds[home], reach) &&
+				a.feasible(gained[away], left[away], bounds[away], reach) &&
+				try(i+1)
+			gained[home] -= o.home
+			gained[away] -= o.away
+			if ok {
+				return true
+			}
+		}
+		left[home]++
+		left[away]++
+		return false
+	}
+
+	return try(0)
+}
+
+// flow splits the points of the contested matches between their teams as a
+// maximum flow, keeping every team within its bound. Any split of a match's
+// points is allowed, not only the results of the scoring rules, so a split
+// that does not exist rules the results out, while one that does only
+// decides when the analysis knows every split to be a result (exact).
+func (a *clinchAnalysis) flow(contested []*Match, gained, left, bounds map[int]int, reach bool) (possible, exact bool) {
+	if len(contested) == 0 {
+		return true, true
+	}
+
+	// Nodes: the source, the matches, the bounded teams and the sink
+	teams := make(map[int]int, len(bounds))
+	for id := range bounds {
+		teams[id] = 1 + len(contested) + len(teams)
+	}
+	source, sink := 0, 1+len(contested)+len(teams)
+	network := newFlowNetwork(sink + 1)
+
+	// Keeping teams down, every match hands out at least minTotal points, of
+	// which each team takes minPts at least; lifting teams up, a match hands
+	// out maxTotal points at most, of which each team takes maxPts at most
+	supply, share := a.minTotal-2*a.minPts, a.minTotal-2*a.minPts
+	if reach {
+		supply, share = a.maxTotal, a.maxPts
+	}
+	for i, match := range contested {
+		network.addEdge(source, 1+i, supply)
+		network.addEdge(1+i, teams[match.HomeTeamID], share)
+		network.addEdge(1+i, teams[match.AwayTeamID], share)
+	}
+
+	// Every match has to hand out its points when keeping teams down, while
+	// every team has to get what it needs when lifting them up
+	required := 0
+	for id, node := range teams {
+		if reach {
+			need := max(bounds[id]-gained[id], 0)
+			network.addEdge(node, sink, need)
+			required += need
+			continue
+		}
+		network.addEdge(node, sink, bounds[id]-gained[id]-left[id]*a.minPts)
+	}
+	if !reach {
+		required = supply * len(contested)
+	}
+
+	possible = network.maxFlow(source, sink) == required
+	if reach {
+		return possible, a.exactMet
+	}
+	return possible, a.exactCap
+}
+
+// pick returns the points a bounded team takes from a match against an unbounded one
+func (a *clinchAnalysis) pick(reach bool) int {
+	if reach {
+		return a.maxPts
+	}
+	return a.minPts
+}
+
+// feasible reports whether a team that has gained the given points with
+// matches still left can end up within its bound
+func (a *clinchAnalysis) feasible(gained, left, bound int, reach bool) bool {
+	if reach {
+		return gained+left*a.maxPts >= bound
+	}
+	return gained+left*a.minPts <= bound
+}
+
+// combinations calls fn with every way of choosing k of n items until fn returns true
+func combinations(n, k int, fn func(chosen []bool) bool) {
+	chosen := make([]bool, n)
+	var choose func(start, k int) bool
+	choose = func(start, k int) bool {
+		if k == 0 {
+			return fn(chosen)
+		}
+		for i := start; i <= n-k; i++ {
+			chosen[i] = true
+			stop := choose(i+1, k-1)
+			chosen[i] = false
+			if stop {
+				return true
+			}
+		}
+		return false
+	}
+	choose(0, k)
+}
@@ -0,0 +1,203 @@
+package model
+
+import "testing"
+
+func TestClinchesTitleBeforeLastWeek(t *testing.T) {
+	// Team 1 wins every match and meets team 2 in the last week, every other match is drawn
+	league, err := NewLeague("Title League", testTeams(4), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	score := func(home, away int) (int, int) {
+		switch {
+		case home == 1:
+			return 1, 0
+		case away == 1:
+			return 0, 1
+		}
+		return 1, 1
+	}
+
+	tests := []struct {
+		week     int
+		clinched bool
+		magic    int
+	}{
+		// 3 points against 1, 1 and 0, each rival can still reach 7
+		{week: 1, clinched: false, magic: 5},
+		// 6 points against 2, 1 and 1 with one week left
+		{week: 2, clinched: true, magic: 0},
+		{week: 3, clinched: true, magic: 0},
+	}
+
+	for _, tt := range tests {
+		playWeek(league, tt.week, score)
+		clinches := league.Clinches(DefaultPositionBands(4))
+
+		title := clinches[1][0]
+		if title.Clinched != tt.clinched || title.Eliminated {
+			t.Errorf("week %d: expected team 1 to have clinched the title %v, got %+v", tt.week, tt.clinched, title)
+		}
+		if tt.clinched && (title.ClinchedWeek == nil || *title.ClinchedWeek != 2) {
+			t.Errorf("week %d: expected the title to be clinched after week 2, got %v", tt.week, title.ClinchedWeek)
+		}
+		if title.MagicNumber == nil || *title.MagicNumber != tt.magic {
+			t.Errorf("week %d: expected a magic number of %d, got %v", tt.week, tt.magic, title.MagicNumber)
+		}
+
+		for teamID := 2; teamID <= 4; teamID++ {
+			rival := clinches[teamID][0]
+			if rival.Eliminated != tt.clinched || rival.Clinched {
+				t.Errorf("week %d: expected team %d to be eliminated from the title %v, got %+v", tt.week, teamID, tt.clinched, rival)
+			}
+			if tt.clinched && (rival.EliminatedWeek == nil || *rival.EliminatedWeek != 2) {
+				t.Errorf("week %d: expected team %d to be eliminated after week 2, got %v", tt.week, teamID, rival.EliminatedWeek)
+			}
+		}
+	}
+}
+
+func TestClinchesOddLeague(t *testing.T) {
+	// Five teams, team 3 sitting out the last week while 4 and 5 meet
+	league, err := NewLeague("Odd League", testTeams(5), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := map[[2]int][2]int{
+		{2, 5}: {2, 0}, {3, 4}: {1, 0}, // Week 1, team 1 sits out
+		{5, 1}: {0, 1}, {2, 3}: {1, 0}, // Week 2, team 4 sits out
+		{1, 4}: {1, 0}, {5, 3}: {0, 0}, // Week 3, team 2 sits out
+		{3, 1}: {0, 2}, {4, 2}: {2, 0}, // Week 4, team 5 sits out
+	}
+	for week := 1; week <= 4; week++ {
+		playWeek(league, week, func(home, away int) (int, int) {
+			result, ok := results[[2]int{home, away}]
+			if !ok {
+				t.Fatalf("week %d: unexpected fixture %d-%d", week, home, away)
+			}
+			return result[0], result[1]
+		})
+	}
+
+	// 9, 6, 4, 3 and 1 points with 1-2 and 4-5 left to play
+	tests := []struct {
+		teamID     int
+		clinched   bool
+		eliminated bool
+		magic      int // -1 when the team cannot get out of the band on its own
+	}{
+		// Teams 4 and 5 share 3 points at most, so they cannot both reach 4
+		{teamID: 3, eliminated: true, magic: 0},
+		// Beating team 5 takes team 4 past the 4 points teams 3 and 5 can reach
+		{teamID: 4, magic: 2},
+		// Even a win leaves team 5 level with team 3
+		{teamID: 5, magic: -1},
+		{teamID: 1, eliminated: true, magic: 0},
+	}
+
+	clinches := league.Clinches(DefaultPositionBands(5))
+	for _, tt := range tests {
+		relegation := clinches[tt.teamID][2]
+		if relegation.Clinched != tt.clinched || relegation.Eliminated != tt.eliminated {
+			t.Errorf("team %d: expected clinched %v and eliminated %v, got %+v", tt.teamID, tt.clinched, tt.eliminated, relegation)
+		}
+		magic := -1
+		if relegation.MagicNumber != nil {
+			magic = *relegation.MagicNumber
+		}
+		if magic != tt.magic {
+			t.Errorf("team %d: expected magic number %d, got %d", tt.teamID, tt.magic, magic)
+		}
+	}
+}
+
+func TestClinchAnalysis(t *testing.T) {
+	shootout := ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 2}
+	title := PositionBand{Name: "title", From: 1, To: 1}
+
+	tests := []struct {
+		name       string
+		rules      ScoringRules
+		points     []int    // Points of teams 1 onwards
+		fixtures   [][2]int // Home and away team of the fixtures left
+		teamID     int      // Team whose title chances are checked
+		eliminated bool
+		flowOnly   bool // The maximum flow decides without trying any result
+	}{
+		{
+			// Team 4 reaches 10, but the three matches between the teams on 9 hand out 6 points
+			name:       "rival fixtures",
+			rules:      DefaultScoringRules,
+			points:     []int{9, 9, 9, 7, 0},
+			fixtures:   [][2]int{{4, 5}, {1, 2}, {1, 3}, {2, 3}},
+			teamID:     4,
+			eliminated: true,
+			flowOnly:   true,
+		},
+		{
+			// Three draws leave the teams on 8 level with team 4
+			name:     "rival fixtures with room",
+			rules:    DefaultScoringRules,
+			points:   []int{8, 8, 8, 7, 0},
+			fixtures: [][2]int{{4, 5}, {1, 2}, {1, 3}, {2, 3}},
+			teamID:   4,
+		},
+		{
+			// A match hands out 2 points or more, but never 1 to each team
+			name:       "draw shootout without an even split",
+			rules:      shootout,
+			points:     []int{5, 4, 4},
+			fixtures:   [][2]int{{2, 3}},
+			teamID:     1,
+			eliminated: true,
+		},
+		{
+			name:     "drawn points",
+			rules:    DefaultScoringRules,
+			points:   []int{5, 4, 4},
+			fixtures: [][2]int{{2, 3}},
+			teamID:   1,
+		},
+		{
+			// 3-2-1-0 gives a match's 3 points every way, so the flow decides
+			name:     "draw shootout with every split",
+			rules:    ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 2, ShootoutLossPoints: 1},
+			points:   []int{6, 4, 4},
+			fixtures: [][2]int{{2, 3}},
+			teamID:   1,
+			flowOnly: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			league := &League{Scoring: tt.rules, TotalWeeks: 1}
+			standings := &Standings{}
+			for i, points := range tt.points {
+				standings.Teams = append(standings.Teams, TeamStanding{TeamID: i + 1, Points: points})
+			}
+			for _, fixture := range tt.fixtures {
+				league.Matches = append(league.Matches, &Match{HomeTeamID: fixture[0], AwayTeamID: fixture[1], Week: 1})
+			}
+
+			analysis := league.newClinchAnalysis(standings)
+			if got := analysis.eliminated(tt.teamID, title); got != tt.eliminated {
+				t.Errorf("expected team %d to be eliminated %v, got %v", tt.teamID, tt.eliminated, got)
+			}
+			if got := analysis.budget == clinchSearchBudget; got != tt.flowOnly {
+				t.Errorf("expected the flow alone to decide %v, got %v", tt.flowOnly, got)
+			}
+		})
+	}
+}
+
+// playWeek plays the matches of the week with the given scores
+func playWeek(l *League, week int, score func(home, away int) (int, int)) {
+	for _, match := range l.Matches {
+		if match.Week == week {
+			match.HomeScore, match.AwayScore = score(match.HomeTeamID, match.AwayTeamID)
+			match.Played = true
+		}
+	}
+	l.CurrentWeek = week
+}
@@ -0,0 +1,528 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Draw types of a cup
+const (
+	DrawSeeded = "seeded" // Strongest teams are kept apart and receive the byes
+	DrawRandom = "random" // Bracket positions are drawn with the cup seed
+)
+
+// penaltyConversion is the probability of scoring a penalty in a shootout
+const penaltyConversion = 0.75
+
+// CupOptions holds the settings a cup is created with
+type CupOptions struct {
+	TeamIDs        []int        // Teams taking part, all teams when empty
+	TwoLegged      bool         // Ties before the final are played home and away
+	TwoLeggedFinal bool         // The final is played home and away
+	Draw           string       // Draw type, seeded when empty
+	Engine         EngineConfig // Match engine, linear when empty
+	Seed           *int64       // Cup seed, random when nil
+}
+
+// Cup represents a single-elimination knockout competition.
+// Entries that do not fill a power-of-two bracket give byes in the first round.
+type Cup struct {
+	ID             int          `json:"id"`
+	Name           string       `json:"name"`
+	Teams          []*Team      `json:"teams"`
+	Ties           []*CupTie    `json:"ties,omitempty"`
+	CurrentRound   int          `json:"current_round"` // Number of rounds played
+	TotalRounds    int          `json:"total_rounds"`
+	TwoLegged      bool         `json:"two_legged"`
+	TwoLeggedFinal bool         `json:"two_legged_final"`
+	Draw           string       `json:"draw"`
+	Engine         EngineConfig `json:"engine"`
+	Seed           int64        `json:"seed"`
+	ChampionID     *int         `json:"champion_id,omitempty"`
+	TournamentID   *int         `json:"tournament_id,omitempty"` // Set when the cup is a tournament's knockout stage
+
+	simulator MatchSimulator
+}
+
+// CupTie pairs two teams in a round of a cup, the winner advances to the next round
+type CupTie struct {
+	ID            int       `json:"id"`
+	Round         int       `json:"round"`
+	Slot          int       `json:"slot"`         // Ties 2n and 2n+1 feed tie n of the next round
+	HomeTeamID    *int      `json:"home_team_id"` // Hosts the first leg, nil until known
+	AwayTeamID    *int      `json:"away_team_id"`
+	HomeTeam      *Team     `json:"home_team,omitempty"`
+	AwayTeam      *Team     `json:"away_team,omitempty"`
+	Bye           bool      `json:"bye"`
+	Legs          []*CupLeg `json:"legs"`
+	HomePenalties *int      `json:"home_penalties,omitempty"`
+	AwayPenalties *int      `json:"away_penalties,omitempty"`
+	WinnerID      *int      `json:"winner_id,omitempty"`
+}
+
+// CupLeg is a single match of a tie
+type CupLeg struct {
+	Leg        int       `json:"leg"`
+	HomeTeamID int       `json:"home_team_id"`
+	AwayTeamID int       `json:"away_team_id"`
+	HomeScore  int       `json:"home_score"` // Including extra time
+	AwayScore  int       `json:"away_score"`
+	ExtraTime  bool      `json:"extra_time"`
+	PlayedAt   time.Time `json:"played_at"`
+}
+
+// CupRound holds the ties of a single round of a cup
+type CupRound struct {
+	Round     int       `json:"round"`
+	Name      string    `json:"name"`
+	TwoLegged bool      `json:"two_legged"`
+	Played    bool      `json:"played"`
+	Ties      []*CupTie `json:"ties"`
+}
+
+// CupBracket is the full bracket of a cup, round by round
+type CupBracket struct {
+	CupID        int         `json:"cup_id"`
+	Name         string      `json:"name"`
+	CurrentRound int         `json:"current_round"`
+	TotalRounds  int         `json:"total_rounds"`
+	Champion     *Team       `json:"champion,omitempty"`
+	Rounds       []*CupRound `json:"rounds"`
+}
+
+// NewCup creates a new cup for the given teams. The bracket is empty until the draw is made.
+func NewCup(name string, teams []*Team, opts CupOptions) (*Cup, error) {
+	if name == "" {
+		return nil, errors.New("cup name cannot be empty")
+	}
+
+	if len(teams) < 2 {
+		return nil, errors.New("cup must have at least 2 teams")
+	}
+
+	draw := opts.Draw
+	if draw == "" {
+		draw = DrawSeeded
+	}
+	if draw != DrawSeeded && draw != DrawRandom {
+		return nil, fmt.Errorf("unknown draw type %q", opts.Draw)
+	}
+
+	// Every round halves the bracket, which is rounded up to a power of two
+	totalRounds := 0
+	for 1<<totalRounds < len(teams) {
+		totalRounds++
+	}
+
+	return &Cup{
+		Name:           name,
+		Teams:          teams,
+		TotalRounds:    totalRounds,
+		TwoLegged:      opts.TwoLegged,
+		TwoLeggedFinal: opts.TwoLeggedFinal,
+		Draw:           draw,
+	}, nil
+}
+
+// Drawn reports whether the draw of the cup has been made
+func (c *Cup) Drawn() bool {
+	return len(c.Ties) > 0
+}
+
+// IsTwoLegged reports whether the ties of the given round are played home and away
+func (c *Cup) IsTwoLegged(round int) bool {
+	if round == c.TotalRounds {
+		return c.TwoLeggedFinal
+	}
+	return c.TwoLegged
+}
+
+// RoundName returns the common name of a round, e.g. "Quarter-finals"
+func (c *Cup) RoundName(round int) string {
+	switch c.TotalRounds - round {
+	case 0:
+		return "Final"
+	case 1:
+		return "Semi-finals"
+	case 2:
+		return "Quarter-finals"
+	default:
+		return fmt.Sprintf("Round of %d", 1<<(c.TotalRounds-round+1))
+	}
+}
+
+// RoundSeed returns the seed the given round is simulated with
+func (c *Cup) RoundSeed(round int) int64 {
+	return DeriveSeed(c.Seed, seedStreamCupRound, int64(round))
+}
+
+// RoundTies returns the ties of the given round ordered by slot
+func (c *Cup) RoundTies(round int) []*CupTie {
+	ties := make([]*CupTie, 0)
+	for _, tie := range c.Ties {
+		if tie.Round == round {
+			ties = append(ties, tie)
+		}
+	}
+	sort.Slice(ties, func(i, j int) bool {
+		return ties[i].Slot < ties[j].Slot
+	})
+	return ties
+}
+
+// MakeDraw places the teams in the bracket and creates the ties of every round.
+// Seeded draws order teams by strength, random draws shuffle them with the cup seed.
+func (c *Cup) MakeDraw() error {
+	order := make([]*Team, len(c.Teams))
+	copy(order, c.Teams)
+
+	if c.Draw == DrawRandom {
+		rng := rand.New(rand.NewSource(DeriveSeed(c.Seed, seedStreamCupDraw)))
+		rng.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	} else {
+		sort.SliceStable(order, func(i, j int) bool {
+			if order[i].Strength != order[j].Strength {
+				return order[i].Strength > order[j].Strength
+			}
+			return order[i].ID < order[j].ID
+		})
+	}
+
+	return c.DrawInOrder(order)
+}
+
+// DrawInOrder places the teams in the bracket by the given seeding, the first
+// team being the top seed, and creates the ties of every round.
+// Teams without an opponent in the first round advance on a bye.
+func (c *Cup) DrawInOrder(order []*Team) error {
+	if c.Drawn() {
+		return errors.New("the draw has already been made")
+	}
+
+	if len(order) != len(c.Teams) {
+		return errors.New("every team of the cup must be seeded exactly once")
+	}
+
+	size := 1 << c.TotalRounds
+	positions := bracketPositions(size)
+
+	for round := 1; round <= c.TotalRounds; round++ {
+		for slot := 0; slot < size>>round; slot++ {
+			tie := &CupTie{Round: round, Slot: slot, Legs: make([]*CupLeg, 0)}
+
+			if round == 1 {
+				// Positions beyond the number of entries are byes
+				if seed := positions[2*slot]; seed <= len(order) {
+					homeTeamID := order[seed-1].ID
+					tie.HomeTeamID = &homeTeamID
+				}
+				if seed := positions[2*slot+1]; seed <= len(order) {
+					awayTeamID := order[seed-1].ID
+					tie.AwayTeamID = &awayTeamID
+				}
+			}
+
+			c.Ties = append(c.Ties, tie)
+		}
+	}
+
+	for _, tie := range c.RoundTies(1) {
+		if tie.AwayTeamID == nil {
+			tie.Bye = true
+			tie.WinnerID = tie.HomeTeamID
+			c.advance(tie)
+		}
+	}
+
+	return nil
+}
+
+// bracketPositions returns the seed numbers in bracket order for a bracket of
+// the given power-of-two size, so that seeds 1 and 2 can only meet in the final
+func bracketPositions(size int) []int {
+	positions := []int{1}
+	for len(positions) < size {
+		next := make([]int, 0, len(positions)*2)
+		for _, seed := range positions {
+			next = append(next, seed, len(positions)*2+1-seed)
+		}
+		positions = next
+	}
+	return positions
+}
+
+// SimulateRound plays every tie of the next round and moves the winners on.
+// Ties level on aggregate are decided by extra time and then penalties.
+func (c *Cup) SimulateRound() ([]*CupTie, error) {
+	if !c.Drawn() {
+		return nil, errors.New("the draw has not been made yet")
+	}
+
+	if c.CurrentRound >= c.TotalRounds {
+		return nil, errors.New("all rounds have been played")
+	}
+
+	c.CurrentRound++
+	rng := rand.New(rand.NewSource(c.RoundSeed(c.CurrentRound)))
+
+	ties := c.RoundTies(c.CurrentRound)
+	for _, tie := range ties {
+		// Byes are decided at the draw
+		if tie.WinnerID != nil {
+			continue
+		}
+
+		if err := c.playTie(tie, rng); err != nil {
+			return nil, err
+		}
+		c.advance(tie)
+	}
+
+	return ties, nil
+}
+
+// playTie plays the legs of a tie and decides its winner
+func (c *Cup) playTie(tie *CupTie, rng *rand.Rand) error {
+	if tie.HomeTeamID == nil || tie.AwayTeamID == nil {
+		return fmt.Errorf("tie %d of round %d has no opponents", tie.Slot, tie.Round)
+	}
+
+	home := c.team(*tie.HomeTeamID)
+	away := c.team(*tie.AwayTeamID)
+	if home == nil || away == nil {
+		return errors.New("tie teams are not part of the cup")
+	}
+
+	simulator, err := c.Simulator()
+	if err != nil {
+		return err
+	}
+
+	legs := 1
+	if c.IsTwoLegged(tie.Round) {
+		legs = 2
+	}
+
+	for leg := 1; leg <= legs; leg++ {
+		// The teams swap venues in the second leg
+		host, visitor := home, away
+		if leg == 2 {
+			host, visitor = away, home
+		}
+
+		match := playCupMatch(simulator, host, visitor, rng)
+		tie.Legs = append(tie.Legs, &CupLeg{
+			Leg:        leg,
+			HomeTeamID: host.ID,
+			AwayTeamID: visitor.ID,
+			HomeScore:  match.HomeScore,
+			AwayScore:  match.AwayScore,
+			PlayedAt:   time.Now(),
+		})
+	}
+
+	homeGoals, awayGoals := tie.Aggregate()
+
+	// Extra time is added to the last leg, a third of a match long
+	if homeGoals == awayGoals {
+		last := tie.Legs[len(tie.Legs)-1]
+		extraTime := playCupMatch(simulator, c.team(last.HomeTeamID), c.team(last.AwayTeamID), rng)
+		last.HomeScore += thinGoals(extraTime.HomeScore, 1.0/3, rng)
+		last.AwayScore += thinGoals(extraTime.AwayScore, 1.0/3, rng)
+		last.ExtraTime = true
+
+		homeGoals, awayGoals = tie.Aggregate()
+	}
+
+	if homeGoals == awayGoals {
+		homePenalties, awayPenalties := penaltyShootout(rng)
+		tie.HomePenalties = &homePenalties
+		tie.AwayPenalties = &awayPenalties
+		homeGoals, awayGoals = homePenalties, awayPenalties
+	}
+
+	if homeGoals > awayGoals {
+		tie.WinnerID = tie.HomeTeamID
+	} else {
+		tie.WinnerID = tie.AwayTeamID
+	}
+
+	return nil
+}
+
+// Aggregate returns the total goals of both teams over all legs of the tie,
+// from the point of view of the team hosting the first leg
+func (t *CupTie) Aggregate() (int, int) {
+	homeGoals, awayGoals := 0, 0
+	for _, leg := range t.Legs {
+		if t.HomeTeamID != nil && leg.HomeTeamID == *t.HomeTeamID {
+			homeGoals += leg.HomeScore
+			awayGoals += leg.AwayScore
+		} else {
+			homeGoals += leg.AwayScore
+			awayGoals += leg.HomeScore
+		}
+	}
+	return homeGoals, awayGoals
+}
+
+// advance moves the winner of a tie into its tie of the next round,
+// or crowns the champion after the final
+func (c *Cup) advance(tie *CupTie) {
+	if tie.Round == c.TotalRounds {
+		c.ChampionID = tie.WinnerID
+		return
+	}
+
+	for _, next := range c.RoundTies(tie.Round + 1) {
+		if next.Slot == tie.Slot/2 {
+			if tie.Slot%2 == 0 {
+				next.HomeTeamID = tie.WinnerID
+			} else {
+				next.AwayTeamID = tie.WinnerID
+			}
+			return
+		}
+	}
+}
+
+// Bracket returns the ties of the cup grouped by round with their teams filled in
+func (c *Cup) Bracket() *CupBracket {
+	bracket := &CupBracket{
+		CupID:        c.ID,
+		Name:         c.Name,
+		CurrentRound: c.CurrentRound,
+		TotalRounds:  c.TotalRounds,
+		Rounds:       make([]*CupRound, 0, c.TotalRounds),
+	}
+
+	if c.ChampionID != nil {
+		bracket.Champion = c.team(*c.ChampionID)
+	}
+
+	if !c.Drawn() {
+		return bracket
+	}
+
+	for round := 1; round <= c.TotalRounds; round++ {
+		ties := c.RoundTies(round)
+		for _, tie := range ties {
+			if tie.HomeTeamID != nil {
+				tie.HomeTeam = c.team(*tie.HomeTeamID)
+			}
+			if tie.AwayTeamID != nil {
+				tie.AwayTeam = c.team(*tie.AwayTeamID)
+			}
+		}
+
+		bracket.Rounds = append(bracket.Rounds, &CupRound{
+			Round:     round,
+			Name:      c.RoundName(round),
+			TwoLegged: c.IsTwoLegged(round),
+			Played:    round <= c.CurrentRound,
+			Ties:      ties,
+		})
+	}
+
+	return bracket
+}
+
+// Simulator returns the match engine configured for the cup
+func (c *Cup) Simulator() (MatchSimulator, error) {
+	if c.simulator == nil {
+		simulator, err := NewMatchSimulator(c.Engine)
+		if err != nil {
+			return nil, err
+		}
+		c.simulator = simulator
+	}
+	return c.simulator, nil
+}
+
+// SetEngine validates the engine config and stores it on the cup
+// together with the engine's effective parameters
+func (c *Cup) SetEngine(config EngineConfig) error {
+	simulator, err := NewMatchSimulator(config)
+	if err != nil {
+		return err
+	}
+
+	if config.Name == "" {
+		config.Name = EngineLinear
+	}
+
+	c.Engine = EngineConfig{Name: config.Name, Params: simulator.Params()}
+	c.simulator = simulator
+	return nil
+}
+
+// team returns the cup team with the given ID
+func (c *Cup) team(id int) *Team {
+	for _, team := range c.Teams {
+		if team.ID == id {
+			return team
+		}
+	}
+	return nil
+}
+
+// playCupMatch simulates a match between two teams with their static strengths
+func playCupMatch(simulator MatchSimulator, home, away *Team, rng *rand.Rand) *Match {
+	match := &Match{HomeTeamID: home.ID, AwayTeamID: away.ID}
+	simulator.Simulate(
+		match,
+		MatchSide{Team: home, Strength: float64(home.Strength)},
+		MatchSide{Team: away, Strength: float64(away.Strength)},
+		rng,
+	)
+	return match
+}
+
+// thinGoals keeps every goal with the given probability
+func thinGoals(goals int, probability float64, rng *rand.Rand) int {
+	kept := 0
+	for i := 0; i < goals; i++ {
+		if rng.Float64() < probability {
+			kept++
+		}
+	}
+	return kept
+}
+
+// penaltyShootout plays five penalties per team, stopping once the result is
+// decided, followed by sudden death while level
+func penaltyShootout(rng *rand.Rand) (int, int) {
+	home, away := 0, 0
+
+	for kick := 0; kick < 5; kick++ {
+		if rng.Float64() < penaltyConversion {
+			home++
+		}
+		if home > away+5-kick || away > home+4-kick {
+			return home, away
+		}
+
+		if rng.Float64() < penaltyConversion {
+			away++
+		}
+		if home > away+4-kick || away > home+4-kick {
+			return home, away
+		}
+	}
+
+	for home == away {
+		if rng.Float64() < penaltyConversion {
+			home++
+		}
+		if rng.Float64() < penaltyConversion {
+			away++
+		}
+	}
+
+	return home, away
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBracketPositions(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{size: 1, want: []int{1}},
+		{size: 2, want: []int{1, 2}},
+		{size: 4, want: []int{1, 4, 2, 3}},
+		{size: 8, want: []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		if got := bracketPositions(tt.size); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("size %d: expected %v, got %v", tt.size, tt.want, got)
+		}
+	}
+}
+
+func TestCupSeededDraw(t *testing.T) {
+	tests := []struct {
+		teams  int
+		rounds int
+		ties   [][2]int // Home and away team of the first round ties, 0 for a bye
+	}{
+		{teams: 2, rounds: 1, ties: [][2]int{{2, 1}}},
+		// Seeds 1 to 4 are teams 4 to 1, the strongest first
+		{teams: 4, rounds: 2, ties: [][2]int{{4, 1}, {3, 2}}},
+		// Three positions of eight are left, the top three seeds get the byes
+		{teams: 5, rounds: 3, ties: [][2]int{{5, 0}, {2, 1}, {4, 0}, {3, 0}}},
+		{teams: 6, rounds: 3, ties: [][2]int{{6, 0}, {3, 2}, {5, 0}, {4, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d teams", tt.teams), func(t *testing.T) {
+			cup, err := NewCup("Seeded Cup", testTeams(tt.teams), CupOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cup.MakeDraw(); err != nil {
+				t.Fatal(err)
+			}
+			if cup.TotalRounds != tt.rounds {
+				t.Fatalf("expected %d rounds, got %d", tt.rounds, cup.TotalRounds)
+			}
+			if len(cup.Ties) != 1<<tt.rounds-1 {
+				t.Errorf("expected %d ties, got %d", 1<<tt.rounds-1, len(cup.Ties))
+			}
+
+			second := cup.RoundTies(2)
+			for slot, tie := range cup.RoundTies(1) {
+				home, away := tt.ties[slot][0], tt.ties[slot][1]
+				if tie.HomeTeamID == nil || *tie.HomeTeamID != home {
+					t.Errorf("tie %d: expected home team %d, got %v", slot, home, tie.HomeTeamID)
+				}
+				if away == 0 {
+					if !tie.Bye || tie.AwayTeamID != nil || tie.WinnerID == nil || *tie.WinnerID != home {
+						t.Errorf("tie %d: expected team %d to advance on a bye, got %+v", slot, home, tie)
+					}
+
+					// The team waits in its tie of the next round
+					next := second[slot/2].HomeTeamID
+					if slot%2 == 1 {
+						next = second[slot/2].AwayTeamID
+					}
+					if next == nil || *next != home {
+						t.Errorf("tie %d: expected team %d in tie %d of round 2, got %v", slot, home, slot/2, next)
+					}
+					continue
+				}
+				if tie.Bye || tie.AwayTeamID == nil || *tie.AwayTeamID != away || tie.WinnerID != nil {
+					t.Errorf("tie %d: expected team %d to host team %d, got %+v", slot, home, away, tie)
+				}
+			}
+		})
+	}
+}
+
+func TestCupDrawErrors(t *testing.T) {
+	if _, err := NewCup("Lonely Cup", testTeams(1), CupOptions{}); err == nil {
+		t.Error("expected an error for a single team")
+	}
+	if _, err := NewCup("Alphabetical Cup", testTeams(4), CupOptions{Draw: "alphabetical"}); err == nil {
+		t.Error("expected an error for an unknown draw type")
+	}
+
+	cup, err := NewCup("Drawn Cup", testTeams(4), CupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cup.DrawInOrder(cup.Teams[:3]); err == nil {
+		t.Error("expected an error for a seeding missing a team")
+	}
+	if err := cup.MakeDraw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cup.MakeDraw(); err == nil {
+		t.Error("expected an error for a second draw")
+	}
+}
+
+func TestCupSimulation(t *testing.T) {
+	cup, err := NewCup("Simulated Cup", testTeams(6), CupOptions{TwoLegged: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cup.Seed = 42
+	if err := cup.MakeDraw(); err != nil {
+		t.Fatal(err)
+	}
+
+	for round := 1; round <= cup.TotalRounds; round++ {
+		ties, err := cup.SimulateRound()
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		for _, tie := range ties {
+			if tie.Bye {
+				continue
+			}
+			if tie.WinnerID == nil || (*tie.WinnerID != *tie.HomeTeamID && *tie.WinnerID != *tie.AwayTeamID) {
+				t.Errorf("round %d tie %d: expected one of the teams to win, got %+v", round, tie.Slot, tie)
+			}
+
+			legs := 2
+			if round == cup.TotalRounds {
+				legs = 1
+			}
+			if len(tie.Legs) != legs {
+				t.Errorf("round %d tie %d: expected %d legs, got %d", round, tie.Slot, legs, len(tie.Legs))
+			}
+
+			// The shootout decides ties level after extra time, and only those
+			home, away := tie.Aggregate()
+			if (tie.HomePenalties != nil) != (home == away) {
+				t.Errorf("round %d tie %d: aggregate %d-%d with penalties %v", round, tie.Slot, home, away, tie.HomePenalties)
+			}
+		}
+	}
+
+	if cup.ChampionID == nil || *cup.ChampionID != *cup.RoundTies(cup.TotalRounds)[0].WinnerID {
+		t.Errorf("expected the winner of the final to be champion, got %v", cup.ChampionID)
+	}
+	if _, err := cup.SimulateRound(); err == nil {
+		t.Error("expected an error after the final")
+	}
+}
+
+func TestPenaltyShootout(t *testing.T) {
+	for seed := int64(1); seed <= 2000; seed++ {
+		home, away := penaltyShootout(rand.New(rand.NewSource(seed)))
+		if home == away {
+			t.Fatalf("seed %d: shootout ended level %d-%d", seed, home, away)
+		}
+
+		// Kicking on after the shootout is decided would never change its winner
+		fullHome, fullAway := fullShootout(rand.New(rand.NewSource(seed)))
+		if (home > away) != (fullHome > fullAway) || home > fullHome || away > fullAway {
+			t.Fatalf("seed %d: shootout ended %d-%d, every kick taken gives %d-%d", seed, home, away, fullHome, fullAway)
+		}
+
+		// A lead of four goals would have decided the shootout a kick earlier
+		if home-away > 3 || away-home > 3 {
+			t.Fatalf("seed %d: shootout went on to %d-%d", seed, home, away)
+		}
+	}
+}
+
+// fullShootout plays all five penalties per team before sudden death
+func fullShootout(rng *rand.Rand) (int, int) {
+	home, away := 0, 0
+	for kick := 0; kick < 5 || home == away; kick++ {
+		if rng.Float64() < penaltyConversion {
+			home++
+		}
+		if rng.Float64() < penaltyConversion {
+			away++
+		}
+	}
+	return home, away
+}
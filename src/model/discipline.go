@@ -0,0 +1,199 @@
+package model
+
+import (
+	"errors"
+	"sort"
+)
+
+// Reasons a player is banned for
+const (
+	BanYellowCards  = "yellow_cards"
+	BanSecondYellow = "second_yellow"
+	BanRedCard      = "red_card"
+)
+
+// Disciplinary points of the cards, as counted by the fair_play tie-breaker
+const (
+	yellowCardPoints = 1
+	redCardPoints    = 3
+)
+
+// DisciplineRules decide the bans players get for their cards. Bans are
+// counted in matches of the player's team.
+type DisciplineRules struct {
+	YellowCardLimit int `json:"yellow_card_limit"` // Yellow cards that earn a ban, 0 disables accumulation bans
+	YellowCardBan   int `json:"yellow_card_ban"`   // Matches banned for every YellowCardLimit yellow cards
+	SecondYellowBan int `json:"second_yellow_ban"` // Matches banned for a red card for a second booking
+	RedCardBan      int `json:"red_card_ban"`      // Matches banned for a straight red card
+}
+
+// DefaultDisciplineRules ban a player for a match after every five yellow
+// cards or a second booking and for three matches after a straight red card
+var DefaultDisciplineRules = DisciplineRules{YellowCardLimit: 5, YellowCardBan: 1, SecondYellowBan: 1, RedCardBan: 3}
+
+// Suspension is a ban a player earned in a match of the league
+type Suspension struct {
+	PlayerID   int    `json:"player_id"`
+	PlayerName string `json:"player_name,omitempty"`
+	TeamID     int    `json:"team_id"`
+	Reason     string `json:"reason"`    // yellow_cards, second_yellow or red_card
+	Week       int    `json:"week"`      // Week of the match the ban was earned in
+	Matches    int    `json:"matches"`   // Matches banned
+	Served     int    `json:"served"`    // Matches of the team played since
+	Remaining  int    `json:"remaining"` // Matches still to serve
+}
+
+// ResolveDisciplineRules validates discipline rules, returning the default rules when none are given
+func ResolveDisciplineRules(rules *DisciplineRules) (DisciplineRules, error) {
+	if rules == nil {
+		return DefaultDisciplineRules, nil
+	}
+
+	r := *rules
+	if r.YellowCardLimit < 0 || r.YellowCardBan < 0 || r.SecondYellowBan < 0 || r.RedCardBan < 0 {
+		return DisciplineRules{}, errors.New("discipline rules cannot be negative")
+	}
+
+	return r, nil
+}
+
+// SetDiscipline validates the discipline rules and stores them on the league
+func (l *League) SetDiscipline(rules *DisciplineRules) error {
+	resolved, err := ResolveDisciplineRules(rules)
+	if err != nil {
+		return err
+	}
+
+	l.Discipline = resolved
+	return nil
+}
+
+// Suspensions returns every ban earned in the played matches of the league,
+// in the order they were earned
+func (l *League) Suspensions() []*Suspension {
+	names := l.playerNames()
+	suspensions := l.bans(l.Matches, 0)
+
+	for _, ban := range suspensions {
+		ban.PlayerName = names[ban.PlayerID]
+		for _, match := range l.Matches {
+			if match.Played && match.Week > ban.Week && (match.HomeTeamID == ban.TeamID || match.AwayTeamID == ban.TeamID) {
+				ban.Served++
+			}
+		}
+		ban.Served = min(ban.Served, ban.Matches)
+		ban.Remaining = ban.Matches - ban.Served
+	}
+
+	return suspensions
+}
+
+// bans returns the bans earned in the played matches, in the order they were
+// earned, by the players of one team or of every team when teamID is 0. The
+// yellow cards that led to a second booking red card do not count towards
+// the yellow card limit.
+func (l *League) bans(matches []*Match, teamID int) []*Suspension {
+	yellows := make(map[int]int)
+	suspensions := make([]*Suspension, 0)
+
+	for _, match := range playedInOrder(matches, teamID) {
+		suspensions = append(suspensions, l.matchBans(match, teamID, yellows)...)
+	}
+
+	return suspensions
+}
+
+// matchBans returns the bans earned in a played match by the players of one
+// team, or of every team when teamID is 0, counting their yellow cards on
+// from the given counts
+func (l *League) matchBans(match *Match, teamID int, yellows map[int]int) []*Suspension {
+	var sentOff map[int]string
+	for _, event := range match.Events {
+		if event.Type == EventRedCard && event.PlayerID != nil {
+			if sentOff == nil {
+				sentOff = make(map[int]string)
+			}
+			sentOff[*event.PlayerID] = event.Detail
+		}
+	}
+
+	var suspensions []*Suspension
+	for _, event := range match.Events {
+		if event.PlayerID == nil || (teamID != 0 && event.TeamID != teamID) {
+			continue
+		}
+		playerID := *event.PlayerID
+
+		var reason string
+		var matches int
+		switch {
+		case event.Type == EventYellowCard && sentOff[playerID] != EventDetailSecondYellow:
+			yellows[playerID]++
+			if l.Discipline.YellowCardLimit == 0 || yellows[playerID]%l.Discipline.YellowCardLimit != 0 {
+				continue
+			}
+			reason, matches = BanYellowCards, l.Discipline.YellowCardBan
+		case event.Type == EventRedCard && event.Detail == EventDetailSecondYellow:
+			reason, matches = BanSecondYellow, l.Discipline.SecondYellowBan
+		case event.Type == EventRedCard:
+			reason, matches = BanRedCard, l.Discipline.RedCardBan
+		default:
+			continue
+		}
+
+		if matches > 0 {
+			suspensions = append(suspensions, &Suspension{PlayerID: playerID, TeamID: event.TeamID, Reason: reason, Week: match.Week, Matches: matches})
+		}
+	}
+
+	return suspensions
+}
+
+// FairPlayPoints counts the disciplinary points of every team in the matches
+// played up to the given week, one for a yellow card and three for a red card
+func FairPlayPoints(matches []*Match, week int) map[int]int {
+	points := make(map[int]int)
+	for _, match := range matches {
+		if !match.Played || match.Week > week {
+			continue
+		}
+		for _, event := range match.Events {
+			switch event.Type {
+			case EventYellowCard:
+				points[event.TeamID] += yellowCardPoints
+			case EventRedCard:
+				points[event.TeamID] += redCardPoints
+			}
+		}
+	}
+	return points
+}
+
+// playedInOrder returns the played matches of one team, or of every team
+// when teamID is 0, ordered by week and ID
+func playedInOrder(matches []*Match, teamID int) []*Match {
+	played := make([]*Match, 0)
+	for _, match := range matches {
+		if match.Played && (teamID == 0 || match.HomeTeamID == teamID || match.AwayTeamID == teamID) {
+			played = append(played, match)
+		}
+	}
+	sort.SliceStable(played, func(i, j int) bool {
+		if played[i].Week != played[j].Week {
+			return played[i].Week < played[j].Week
+		}
+		return played[i].ID < played[j].ID
+	})
+	return played
+}
+
+// playerNames returns the names of the players of the league's squads
+func (l *League) playerNames() map[int]string {
+	names := make(map[int]string)
+	for _, team := range l.Teams {
+		for _, player := range team.Players {
+			names[player.ID] = player.Name
+		}
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// EloSimulator turns the rating difference of the sides into the expected
+// score of the home team, as in the Elo update, and shares the expected
+// goals of the match out in that proportion. Strengths are converted to
+// ratings on the scale of RatingConfig, so leagues with Elo ratings enabled
+// feed their ratings straight back in.
+type EloSimulator struct {
+	TotalGoals        float64 // Expected goals of both teams together
+	HomeAdvantage     float64 // Rating points added to the home team
+	PointsPerStrength float64 // Rating points per point of team strength
+	MaxGoals          int     // Scores are truncated at MaxGoals per team
+}
+
+// NewEloSimulator creates an Elo engine, overriding its defaults with the given parameters
+func NewEloSimulator(params map[string]float64) (*EloSimulator, error) {
+	p, err := engineParams(EngineElo, params, map[string]float64{
+		"total_goals":         2.7,
+		"home_advantage":      60,
+		"points_per_strength": 10,
+		"max_goals":           10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p["total_goals"] <= 0 || p["points_per_strength"] <= 0 {
+		return nil, errors.New("total_goals and points_per_strength must be positive")
+	}
+
+	if p["home_advantage"] < 0 {
+		return nil, errors.New("home_advantage cannot be negative")
+	}
+
+	if p["max_goals"] < 1 {
+		return nil, errors.New("max_goals must be at least 1")
+	}
+
+	return &EloSimulator{
+		TotalGoals:        p["total_goals"],
+		HomeAdvantage:     p["home_advantage"],
+		PointsPerStrength: p["points_per_strength"],
+		MaxGoals:          int(p["max_goals"]),
+	}, nil
+}
+
+// ExpectedScore returns the expected score of the home team, 1 for a certain
+// win and 0 for a certain loss, from the rating difference of the sides
+func (s *EloSimulator) ExpectedScore(home, away MatchSide) float64 {
+	difference := (home.Strength-away.Strength)*s.PointsPerStrength + s.HomeAdvantage
+	return 1 / (1 + math.Pow(10, -difference/400))
+}
+
+// ExpectedGoals shares the expected goals of the match out by the expected score
+func (s *EloSimulator) ExpectedGoals(home, away MatchSide) (float64, float64) {
+	expected := s.ExpectedScore(home, away)
+	return s.TotalGoals * expected, s.TotalGoals * (1 - expected)
+}
+
+// Simulate samples the goals of both sides from Poisson distributions around their expected goals
+func (s *EloSimulator) Simulate(match *Match, home, away MatchSide, rng *rand.Rand) {
+	homeGoals, awayGoals := s.ExpectedGoals(home, away)
+	match.HomeScore = samplePoisson(homeGoals, s.MaxGoals, rng)
+	match.AwayScore = samplePoisson(awayGoals, s.MaxGoals, rng)
+}
+
+// Params returns the parameters of the Elo engine
+func (s *EloSimulator) Params() map[string]float64 {
+	return map[string]float64{
+		"total_goals":         s.TotalGoals,
+		"home_advantage":      s.HomeAdvantage,
+		"points_per_strength": s.PointsPerStrength,
+		"max_goals":           float64(s.MaxGoals),
+	}
+}
+
+// samplePoisson draws from a Poisson distribution with the given mean truncated at maxGoals
+func samplePoisson(mean float64, maxGoals int, rng *rand.Rand) int {
+	target := rng.Float64()
+	cumulative := 0.0
+	p := math.Exp(-mean)
+	for k := 0; k < maxGoals; k++ {
+		cumulative += p
+		if target < cumulative {
+			return k
+		}
+		p *= mean / float64(k+1)
+	}
+	return maxGoals
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Match engines a league can be created with
+const (
+	EngineLinear  = "linear"
+	EnginePoisson = "poisson"
+	EngineEvents  = "events"
+	EngineElo     = "elo"
+)
+
+// Engines lists the names of every match engine
+var Engines = []string{EngineLinear, EnginePoisson, EngineEvents, EngineElo}
+
+// EngineConfig selects the match engine of a league and its parameters.
+// Strength modifiers only apply to leagues.
+type EngineConfig struct {
+	Name      string             `json:"name"`
+	Params    map[string]float64 `json:"params,omitempty"`
+	Modifiers *StrengthModifiers `json:"modifiers,omitempty"`
+}
+
+// MatchSide is one of the two teams of a match as seen by a match engine
+type MatchSide struct {
+	Team     *Team   // With its squad in Players for the event engine
+	Strength float64 // Effective strength on the 1-100 scale of Team.Strength
+}
+
+// MatchSimulator decides the outcome of a single match
+type MatchSimulator interface {
+	// Simulate plays the match between the given sides and records the score on it.
+	// All randomness must come from rng so that results can be reproduced.
+	Simulate(match *Match, home, away MatchSide, rng *rand.Rand)
+	// Params returns the effective parameters of the engine, defaults included
+	Params() map[string]float64
+}
+
+// NewMatchSimulator creates the match engine described by the config.
+// An empty engine name selects the linear engine.
+func NewMatchSimulator(config EngineConfig) (MatchSimulator, error) {
+	switch config.Name {
+	case "", EngineLinear:
+		return NewLinearSimulator(config.Params)
+	case EnginePoisson:
+		return NewPoissonSimulator(config.Params)
+	case EngineEvents:
+		return NewEventSimulator(config.Params)
+	case EngineElo:
+		return NewEloSimulator(config.Params)
+	default:
+		return nil, fmt.Errorf("unknown match engine %q", config.Name)
+	}
+}
+
+// engineParams resolves engine parameters against their defaults,
+// rejecting parameters the engine does not know about
+func engineParams(engine string, params, defaults map[string]float64) (map[string]float64, error) {
+	resolved := make(map[string]float64, len(defaults))
+	for key, value := range defaults {
+		resolved[key] = value
+	}
+
+	// Sort keys so the reported error does not depend on map order
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ok := defaults[key]; !ok {
+			return nil, fmt.Errorf("unknown parameter %q for match engine %q", key, engine)
+		}
+		resolved[key] = params[key]
+	}
+
+	return resolved, nil
+}
+
+// LinearSimulator scales team strengths by a home advantage and a uniform
+// random factor and converts them linearly into goals
+type LinearSimulator struct {
+	HomeAdvantage float64
+	RandomMin     float64
+	RandomMax     float64
+	HomeDivisor   float64
+	AwayDivisor   float64
+	MaxScore      int
+}
+
+// NewLinearSimulator creates a linear engine, overriding its defaults with the given parameters
+func NewLinearSimulator(params map[string]float64) (*LinearSimulator, error) {
+	p, err := engineParams(EngineLinear, params, map[string]float64{
+		"home_advantage": 1.2,
+		"random_min":     0.7,
+		"random_max":     1.3,
+		"home_divisor":   25.0,
+		"away_divisor":   30.0,
+		"max_score":      5,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p["random_min"] < 0 || p["random_max"] < p["random_min"] {
+		return nil, errors.New("random_min must be non-negative and not greater than random_max")
+	}
+
+	if p["home_divisor"] <= 0 || p["away_divisor"] <= 0 {
+		return nil, errors.New("home_divisor and away_divisor must be positive")
+	}
+
+	if p["max_score"] < 0 {
+		return nil, errors.New("max_score cannot be negative")
+	}
+
+	return &LinearSimulator{
+		HomeAdvantage: p["home_advantage"],
+		RandomMin:     p["random_min"],
+		RandomMax:     p["random_max"],
+		HomeDivisor:   p["home_divisor"],
+		AwayDivisor:   p["away_divisor"],
+		MaxScore:      int(p["max_score"]),
+	}, nil
+}
+
+// Simulate simulates a match based on team strengths
+func (s *LinearSimulator) Simulate(match *Match, home, away MatchSide, rng *rand.Rand) {
+	// Calculate effective strengths
+	homeStrength := home.Strength * s.HomeAdvantage
+	awayStrength := away.Strength
+
+	// Random factor
+	homeRandom := s.RandomMin + rng.Float64()*(s.RandomMax-s.RandomMin)
+	awayRandom := s.RandomMin + rng.Float64()*(s.RandomMax-s.RandomMin)
+
+	// Calculate scores based on strengths and randomness
+	homeScoreFactor := homeStrength * homeRandom / s.HomeDivisor
+	awayScoreFactor := awayStrength * awayRandom / s.AwayDivisor
+
+	// Convert to integer scores capped at MaxScore
+	match.HomeScore = min(int(homeScoreFactor), s.MaxScore)
+	match.AwayScore = min(int(awayScoreFactor), s.MaxScore)
+}
+
+// Params returns the parameters of the linear engine
+func (s *LinearSimulator) Params() map[string]float64 {
+	return map[string]float64{
+		"home_advantage": s.HomeAdvantage,
+		"random_min":     s.RandomMin,
+		"random_max":     s.RandomMax,
+		"home_divisor":   s.HomeDivisor,
+		"away_divisor":   s.AwayDivisor,
+		"max_score":      float64(s.MaxScore),
+	}
+}
@@ -0,0 +1,86 @@
+package model
+
+// Kinds of match events
+const (
+	EventGoal         = "goal"
+	EventYellowCard   = "yellow_card"
+	EventRedCard      = "red_card"
+	EventSubstitution = "substitution"
+	EventInjury       = "injury"
+)
+
+// EventDetailSecondYellow marks the red card shown for a second yellow card
+const EventDetailSecondYellow = "second_yellow"
+
+// MatchEvent is a single moment of a match timeline. Players are only known
+// for teams with a squad.
+type MatchEvent struct {
+	ID         int    `json:"id"`
+	MatchID    int    `json:"match_id"`
+	Minute     int    `json:"minute"`
+	Type       string `json:"type"` // goal, yellow_card, red_card, substitution or injury
+	TeamID     int    `json:"team_id"`
+	PlayerID   *int   `json:"player_id,omitempty"` // Scorer, booked or injured player, or the player going off
+	PlayerName string `json:"player_name,omitempty"`
+	Detail     string `json:"detail,omitempty"` // second_yellow for the red card of a second booking
+	Weeks      int    `json:"weeks,omitempty"`  // Weeks an injured player is out for after the match
+
+	// Player assisting a goal, or the player coming on for a substitution
+	RelatedPlayerID   *int   `json:"related_player_id,omitempty"`
+	RelatedPlayerName string `json:"related_player_name,omitempty"`
+}
+
+// EventScore returns the score of the match counted from its goal events
+func (m *Match) EventScore() (int, int) {
+	home, away := 0, 0
+	for _, event := range m.Events {
+		if event.Type != EventGoal {
+			continue
+		}
+		if event.TeamID == m.HomeTeamID {
+			home++
+		} else if event.TeamID == m.AwayTeamID {
+			away++
+		}
+	}
+	return home, away
+}
+
+// DropGoalEvents removes the goals of one team from the timeline of a match
+// whose score of that team was edited, so that the timeline does not
+// contradict the score. The goals of the other team, cards, substitutions
+// and injuries are kept.
+func (m *Match) DropGoalEvents(teamID int) {
+	if m.Events == nil {
+		return
+	}
+
+	kept := make([]*MatchEvent, 0, len(m.Events))
+	for _, event := range m.Events {
+		if event.Type != EventGoal || event.TeamID != teamID {
+			kept = append(kept, event)
+		}
+	}
+	m.Events = kept
+}
+
+// newEvent creates an event of the given player, who may be nil for a team without a squad
+func newEvent(minute int, kind string, teamID int, player *Player) *MatchEvent {
+	event := &MatchEvent{Minute: minute, Type: kind, TeamID: teamID}
+	if player != nil {
+		id := player.ID
+		event.PlayerID = &id
+		event.PlayerName = player.Name
+	}
+	return event
+}
+
+// setRelated records the assisting or incoming player of an event
+func (e *MatchEvent) setRelated(player *Player) {
+	if player == nil {
+		return
+	}
+	id := player.ID
+	e.RelatedPlayerID = &id
+	e.RelatedPlayerName = player.Name
+}
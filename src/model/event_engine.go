@@ -0,0 +1,465 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// matchMinutes is the length of a match simulated by the event engine
+const matchMinutes = 90
+
+// minimumPlayers is the number of players below which a side cannot be reduced by red cards, see dismiss
+const minimumPlayers = 7
+
+// EventSimulator plays a match minute by minute, producing a timeline of
+// goals, assists, cards, substitutions and injuries from which the score is
+// derived. Every minute each side scores with the probability of its
+// expected goals, computed as in the Poisson engine from the strength it has
+// left: a side that loses a player to a red card, or to an injury it cannot
+// replace, is weaker for the rest of the match. Sides with a squad field
+// their best line-up, see BestLineup, and name the players of their events.
+type EventSimulator struct {
+	goals *PoissonSimulator // Expected goals of the sides at full strength
+
+	YellowCards   float64 // Expected yellow cards per team and match
+	RedCards      float64 // Expected straight red cards per team and match
+	Injuries      float64 // Expected injuries per team and match
+	InjuryWeeks   float64 // Mean weeks an injured player of a squad is out for
+	Substitutions int     // Substitutions allowed per team and match
+	AssistRate    float64 // Share of goals with an assist
+	ShortPenalty  float64 // Share of its strength a side loses per player it is short
+	InjuryPenalty float64 // Share of its strength a side without a squad loses per replaced injury
+}
+
+// eventSide is the state of one side during a match of the event engine
+type eventSide struct {
+	teamID        int
+	strength      float64
+	lineup        []int     // IDs of the starting players, nil for a side without a squad
+	onPitch       []*Player // Nil for a side without a squad
+	bench         []*Player
+	lineupRating  float64 // Mean rating of the starting line-up
+	booked        map[int]bool
+	cameOn        map[*Player]bool // Substitutes, who are not taken off again
+	short         int              // Players sent off or injured without a replacement
+	injuries      int              // Replaced injuries of a side without a squad
+	substitutions int
+	planned       []int // Minutes of the tactical substitutions
+}
+
+// NewEventSimulator creates an event engine, overriding its defaults with the given parameters
+func NewEventSimulator(params map[string]float64) (*EventSimulator, error) {
+	p, err := engineParams(EngineEvents, params, map[string]float64{
+		"base_goals":     1.35,
+		"home_advantage": 1.25,
+		"attack_weight":  1.0,
+		"defence_weight": 1.0,
+		"yellow_cards":   1.8,
+		"red_cards":      0.06,
+		"injuries":       0.15,
+		"injury_weeks":   2,
+		"substitutions":  5,
+		"assist_rate":    0.75,
+		"short_penalty":  0.12,
+		"injury_penalty": 0.03,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p["base_goals"] <= 0 || p["home_advantage"] <= 0 {
+		return nil, errors.New("base_goals and home_advantage must be positive")
+	}
+
+	if p["attack_weight"] < 0 || p["defence_weight"] < 0 {
+		return nil, errors.New("attack_weight and defence_weight cannot be negative")
+	}
+
+	if p["yellow_cards"] < 0 || p["red_cards"] < 0 || p["injuries"] < 0 {
+		return nil, errors.New("yellow_cards, red_cards and injuries cannot be negative")
+	}
+
+	if p["injury_weeks"] < 0 {
+		return nil, errors.New("injury_weeks cannot be negative")
+	}
+
+	if p["substitutions"] < 0 || p["substitutions"] > 11 {
+		return nil, errors.New("substitutions must be between 0 and 11")
+	}
+
+	if p["assist_rate"] < 0 || p["assist_rate"] > 1 {
+		return nil, errors.New("assist_rate must be between 0 and 1")
+	}
+
+	if p["short_penalty"] < 0 || p["short_penalty"] > 0.25 || p["injury_penalty"] < 0 || p["injury_penalty"] > 1 {
+		return nil, errors.New("short_penalty must be between 0 and 0.25 and injury_penalty between 0 and 1")
+	}
+
+	return &EventSimulator{
+		goals: &PoissonSimulator{
+			BaseGoals:     p["base_goals"],
+			HomeAdvantage: p["home_advantage"],
+			AttackWeight:  p["attack_weight"],
+			DefenceWeight: p["defence_weight"],
+		},
+		YellowCards:   p["yellow_cards"],
+		RedCards:      p["red_cards"],
+		Injuries:      p["injuries"],
+		InjuryWeeks:   p["injury_weeks"],
+		Substitutions: int(p["substitutions"]),
+		AssistRate:    p["assist_rate"],
+		ShortPenalty:  p["short_penalty"],
+		InjuryPenalty: p["injury_penalty"],
+	}, nil
+}
+
+// Simulate plays the match minute by minute and records its timeline and score
+func (s *EventSimulator) Simulate(match *Match, home, away MatchSide, rng *rand.Rand) {
+	sides := [2]*eventSide{
+		s.newSide(match.HomeTeamID, home, rng),
+		s.newSide(match.AwayTeamID, away, rng),
+	}
+
+	events := make([]*MatchEvent, 0)
+	for minute := 1; minute <= matchMinutes; minute++ {
+		homeGoals, awayGoals := s.goals.ExpectedGoals(
+			MatchSide{Team: home.Team, Strength: s.strength(sides[0])},
+			MatchSide{Team: away.Team, Strength: s.strength(sides[1])},
+		)
+		expected := [2]float64{homeGoals, awayGoals}
+
+		for i, side := range sides {
+			if rng.Float64() < expected[i]/matchMinutes {
+				events = append(events, s.goal(side, minute, rng))
+			}
+			if rng.Float64() < s.YellowCards/matchMinutes {
+				events = append(events, s.booking(side, minute, rng)...)
+			}
+			if rng.Float64() < s.RedCards/matchMinutes {
+				events = append(events, s.sendOff(side, minute, rng)...)
+			}
+			if rng.Float64() < s.Injuries/matchMinutes {
+				events = append(events, s.injury(side, minute, rng)...)
+			}
+			for len(side.planned) > 0 && side.planned[0] == minute {
+				side.planned = side.planned[1:]
+				events = append(events, s.substitution(side, minute, rng)...)
+			}
+		}
+	}
+
+	match.Events = events
+	match.HomeLineup, match.AwayLineup = sides[0].lineup, sides[1].lineup
+	match.HomeScore, match.AwayScore = match.EventScore()
+}
+
+// Params returns the parameters of the event engine
+func (s *EventSimulator) Params() map[string]float64 {
+	return map[string]float64{
+		"base_goals":     s.goals.BaseGoals,
+		"home_advantage": s.goals.HomeAdvantage,
+		"attack_weight":  s.goals.AttackWeight,
+		"defence_weight": s.goals.DefenceWeight,
+		"yellow_cards":   s.YellowCards,
+		"red_cards":      s.RedCards,
+		"injuries":       s.Injuries,
+		"injury_weeks":   s.InjuryWeeks,
+		"substitutions":  float64(s.Substitutions),
+		"assist_rate":    s.AssistRate,
+		"short_penalty":  s.ShortPenalty,
+		"injury_penalty": s.InjuryPenalty,
+	}
+}
+
+// newSide lines a side up and plans its tactical substitutions in the second half
+func (s *EventSimulator) newSide(teamID int, side MatchSide, rng *rand.Rand) *eventSide {
+	state := &eventSide{teamID: teamID, strength: side.Strength, booked: make(map[int]bool), cameOn: make(map[*Player]bool)}
+	if side.Team == nil {
+		return state
+	}
+
+	lineup, bench, ok := BestLineup(side.Team.Players)
+	if !ok {
+		return state
+	}
+
+	state.onPitch = lineup
+	for _, player := range lineup {
+		state.lineup = append(state.lineup, player.ID)
+	}
+	state.bench = bench
+	state.lineupRating = meanRating(lineup)
+
+	for i := 0; i < s.Substitutions; i++ {
+		state.planned = append(state.planned, 46+rng.Intn(40))
+	}
+	sort.Ints(state.planned)
+
+	return state
+}
+
+// strength returns the strength a side has left
+func (s *EventSimulator) strength(side *eventSide) float64 {
+	strength := side.strength * (1 - s.ShortPenalty*float64(side.short))
+	if side.onPitch != nil {
+		strength *= meanRating(side.onPitch) / side.lineupRating
+	} else {
+		strength *= math.Pow(1-s.InjuryPenalty, float64(side.injuries))
+	}
+	return math.Max(strength, 1)
+}
+
+// goal records a goal of the side, scored mostly by forwards and set up with an assist at the assist rate
+func (s *EventSimulator) goal(side *eventSide, minute int, rng *rand.Rand) *MatchEvent {
+	scorer := pickPlayer(side.onPitch, nil, rng, func(p *Player) float64 {
+		return float64(p.Attack) * scoringWeight(p.Position)
+	})
+
+	event := newEvent(minute, EventGoal, side.teamID, scorer)
+	if rng.Float64() < s.AssistRate {
+		event.setRelated(pickPlayer(side.onPitch, scorer, rng, func(p *Player) float64 {
+			return float64(p.Attack+p.Defence) / 2 * assistWeight(p.Position)
+		}))
+	}
+	return event
+}
+
+// booking shows a yellow card to an outfield player of the side, followed by a red card for a second booking
+func (s *EventSimulator) booking(side *eventSide, minute int, rng *rand.Rand) []*MatchEvent {
+	if side.onPitch == nil {
+		return []*MatchEvent{newEvent(minute, EventYellowCard, side.teamID, nil)}
+	}
+
+	player := pickPlayer(side.onPitch, nil, rng, outfieldWeight)
+	if player == nil {
+		return nil
+	}
+
+	events := []*MatchEvent{newEvent(minute, EventYellowCard, side.teamID, player)}
+	if side.booked[player.ID] {
+		if dismissal := s.dismiss(side, player, minute, EventDetailSecondYellow); dismissal != nil {
+			events = append(events, dismissal)
+		}
+	}
+	side.booked[player.ID] = true
+	return events
+}
+
+// sendOff shows a straight red card to an outfield player of the side
+func (s *EventSimulator) sendOff(side *eventSide, minute int, rng *rand.Rand) []*MatchEvent {
+	var player *Player
+	if side.onPitch != nil {
+		player = pickPlayer(side.onPitch, nil, rng, outfieldWeight)
+		if player == nil {
+			return nil
+		}
+	}
+
+	if dismissal := s.dismiss(side, player, minute, ""); dismissal != nil {
+		return []*MatchEvent{dismissal}
+	}
+	return nil
+}
+
+// dismiss takes a player off the pitch for a red card, leaving the side
+// short, and returns the red card. Player is nil for sides without a squad.
+// A side down to minimumPlayers is not reduced any further, neither by a
+// straight red card nor by a second booking, and nil is returned.
+func (s *EventSimulator) dismiss(side *eventSide, player *Player, minute int, detail string) *MatchEvent {
+	if playersLeft(side) <= minimumPlayers {
+		return nil
+	}
+
+	if player != nil {
+		side.onPitch = removePlayer(side.onPitch, player)
+	}
+	side.short++
+
+	event := newEvent(minute, EventRedCard, side.teamID, player)
+	event.Detail = detail
+	return event
+}
+
+// injury takes a player of the side off injured, replaced from the bench
+// while substitutions are left and leaving the side short otherwise
+func (s *EventSimulator) injury(side *eventSide, minute int, rng *rand.Rand) []*MatchEvent {
+	if side.onPitch == nil {
+		events := []*MatchEvent{newEvent(minute, EventInjury, side.teamID, nil)}
+		if side.substitutions < s.Substitutions {
+			side.substitutions++
+			side.injuries++
+			events = append(events, newEvent(minute, EventSubstitution, side.teamID, nil))
+		} else {
+			side.short++
+		}
+		return events
+	}
+
+	player := pickPlayer(side.onPitch, nil, rng, func(*Player) float64 { return 1 })
+	if player == nil {
+		return nil
+	}
+	injury := newEvent(minute, EventInjury, side.teamID, player)
+	injury.Weeks = int(math.Round(rng.ExpFloat64() * s.InjuryWeeks))
+	events := []*MatchEvent{injury}
+	if substitution := s.replace(side, player, minute); substitution != nil {
+		return append(events, substitution)
+	}
+
+	side.onPitch = removePlayer(side.onPitch, player)
+	side.short++
+	return events
+}
+
+// substitution makes a tactical substitution, replacing an outfield player
+// of the starting line-up with the best bench player of the same position
+func (s *EventSimulator) substitution(side *eventSide, minute int, rng *rand.Rand) []*MatchEvent {
+	player := pickPlayer(side.onPitch, nil, rng, func(p *Player) float64 {
+		if p.Position == PositionGoalkeeper || side.cameOn[p] || benchPlayer(side.bench, p.Position, true) == nil {
+			return 0
+		}
+		return 1
+	})
+	if player == nil {
+		return nil
+	}
+
+	if substitution := s.replace(side, player, minute); substitution != nil {
+		return []*MatchEvent{substitution}
+	}
+	return nil
+}
+
+// replace brings on the best bench player for the given player while
+// substitutions are left, preferring one of the same position
+func (s *EventSimulator) replace(side *eventSide, player *Player, minute int) *MatchEvent {
+	if side.substitutions >= s.Substitutions {
+		return nil
+	}
+
+	replacement := benchPlayer(side.bench, player.Position, false)
+	if replacement == nil {
+		return nil
+	}
+
+	side.substitutions++
+	side.bench = removePlayer(side.bench, replacement)
+	side.cameOn[replacement] = true
+	for i, p := range side.onPitch {
+		if p == player {
+			side.onPitch[i] = replacement
+		}
+	}
+
+	event := newEvent(minute, EventSubstitution, side.teamID, player)
+	event.setRelated(replacement)
+	return event
+}
+
+// playersLeft returns the number of players a side has on the pitch
+func playersLeft(side *eventSide) int {
+	return startingOutfielders + 1 - side.short
+}
+
+// benchPlayer returns the best bench player of the given position, or of
+// any position unless samePosition is set. The bench is sorted best first.
+func benchPlayer(bench []*Player, position string, samePosition bool) *Player {
+	for _, p := range bench {
+		if p.Position == position {
+			return p
+		}
+	}
+	if samePosition || len(bench) == 0 {
+		return nil
+	}
+	return bench[0]
+}
+
+// pickPlayer draws a player other than except with a probability proportional to
+// its weight. It returns nil when no player has a positive weight.
+func pickPlayer(players []*Player, except *Player, rng *rand.Rand, weight func(*Player) float64) *Player {
+	total := 0.0
+	for _, p := range players {
+		if p != except {
+			total += weight(p)
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	target := rng.Float64() * total
+	var last *Player
+	for _, p := range players {
+		if p == except || weight(p) <= 0 {
+			continue
+		}
+		last = p
+		target -= weight(p)
+		if target < 0 {
+			return p
+		}
+	}
+	return last
+}
+
+// removePlayer returns the players without the given one
+func removePlayer(players []*Player, player *Player) []*Player {
+	kept := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if p != player {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// meanRating returns the mean rating of the players in their positions
+func meanRating(players []*Player) float64 {
+	if len(players) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, p := range players {
+		total += p.Rating()
+	}
+	return total / float64(len(players))
+}
+
+// outfieldWeight gives every outfield player the same chance of being booked or sent off
+func outfieldWeight(p *Player) float64 {
+	if p.Position == PositionGoalkeeper {
+		return 0
+	}
+	return 1
+}
+
+// scoringWeight is how often players of a position score relative to their attack rating
+func scoringWeight(position string) float64 {
+	switch position {
+	case PositionForward:
+		return 4
+	case PositionMidfielder:
+		return 2
+	case PositionDefender:
+		return 0.7
+	default:
+		return 0
+	}
+}
+
+// assistWeight is how often players of a position assist relative to their rating
+func assistWeight(position string) float64 {
+	switch position {
+	case PositionMidfielder:
+		return 3
+	case PositionForward:
+		return 2
+	case PositionDefender:
+		return 1
+	default:
+		return 0.1
+	}
+}
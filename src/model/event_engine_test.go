@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestEventSimulatorMinimumPlayers(t *testing.T) {
+	tests := []struct {
+		name   string
+		squads bool
+		params map[string]float64
+	}{
+		{name: "straight reds", params: map[string]float64{"red_cards": 30, "yellow_cards": 0, "injuries": 0}},
+		{name: "straight reds with squads", squads: true, params: map[string]float64{"red_cards": 30, "yellow_cards": 0, "injuries": 0}},
+		{name: "second bookings", squads: true, params: map[string]float64{"red_cards": 0, "yellow_cards": 60, "injuries": 0}},
+		{name: "injuries and reds", squads: true, params: map[string]float64{"red_cards": 10, "injuries": 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without substitutions every injury leaves a side short
+			tt.params["substitutions"] = 0
+			simulator, err := NewEventSimulator(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			home, away := &Team{ID: 1, Strength: 70}, &Team{ID: 2, Strength: 60}
+			if tt.squads {
+				home.Players, away.Players = testSquad(home), testSquad(away)
+			}
+
+			held := 0
+			for seed := int64(1); seed <= 50; seed++ {
+				match := &Match{HomeTeamID: home.ID, AwayTeamID: away.ID}
+				simulator.Simulate(match, MatchSide{Team: home, Strength: 70}, MatchSide{Team: away, Strength: 60}, rand.New(rand.NewSource(seed)))
+
+				left := map[int]int{home.ID: 11, away.ID: 11}
+				booked := make(map[int]int)
+				for i, event := range match.Events {
+					switch event.Type {
+					case EventInjury:
+						left[event.TeamID]--
+					case EventRedCard:
+						if left[event.TeamID] <= minimumPlayers {
+							t.Fatalf("seed %d: team %d shown a red card with %d players left", seed, event.TeamID, left[event.TeamID])
+						}
+						left[event.TeamID]--
+					case EventYellowCard:
+						if event.PlayerID == nil {
+							break
+						}
+						booked[*event.PlayerID]++
+						if booked[*event.PlayerID] != 2 {
+							break
+						}
+
+						// A second booking is followed by a red card unless the side is down to the minimum
+						dismissed := i+1 < len(match.Events) && match.Events[i+1].Type == EventRedCard && match.Events[i+1].Detail == EventDetailSecondYellow
+						if dismissed == (left[event.TeamID] <= minimumPlayers) {
+							t.Fatalf("seed %d: player %d booked twice with %d players left, sent off %v", seed, *event.PlayerID, left[event.TeamID], dismissed)
+						}
+						if !dismissed {
+							held++
+						}
+					}
+				}
+
+				for teamID, players := range left {
+					if players == minimumPlayers {
+						held++
+					}
+					if players < minimumPlayers && tt.params["injuries"] == 0 {
+						t.Fatalf("seed %d: team %d down to %d players", seed, teamID, players)
+					}
+				}
+			}
+
+			// The cards are frequent enough to take sides down to the minimum
+			if held == 0 {
+				t.Error("expected a side to be held at the minimum of players")
+			}
+		})
+	}
+}
+
+// testSquad returns a goalkeeper and ten outfield players for the team
+func testSquad(team *Team) []*Player {
+	positions := []string{
+		PositionGoalkeeper,
+		PositionDefender, PositionDefender, PositionDefender, PositionDefender,
+		PositionMidfielder, PositionMidfielder, PositionMidfielder, PositionMidfielder,
+		PositionForward, PositionForward,
+	}
+
+	players := make([]*Player, len(positions))
+	for i, position := range positions {
+		players[i] = &Player{
+			ID:          100*team.ID + i + 1,
+			TeamID:      team.ID,
+			Name:        fmt.Sprintf("Player %d-%d", team.ID, i+1),
+			Position:    position,
+			ShirtNumber: i + 1,
+			Attack:      team.Strength,
+			Defence:     team.Strength,
+			Goalkeeping: team.Strength,
+			Age:         25,
+		}
+	}
+	return players
+}
@@ -0,0 +1,95 @@
+package model
+
+// flowNetwork is a directed graph with integer edge capacities whose
+// maximum flow is found with Dinic's algorithm
+type flowNetwork struct {
+	to       []int   // Head of every edge, each followed by its reverse edge
+	capacity []int   // Capacity left on every edge
+	edges    [][]int // Edges leaving every node
+	level    []int   // Distance from the source in the current phase
+	next     []int   // Next edge of every node to try in the current phase
+}
+
+// newFlowNetwork creates a network of the given number of nodes without edges
+func newFlowNetwork(nodes int) *flowNetwork {
+	return &flowNetwork{
+		edges: make([][]int, nodes),
+		level: make([]int, nodes),
+		next:  make([]int, nodes),
+	}
+}
+
+// addEdge adds an edge of the given capacity
+func (f *flowNetwork) addEdge(from, to, capacity int) {
+	f.edges[from] = append(f.edges[from], len(f.to))
+	f.to = append(f.to, to)
+	f.capacity = append(f.capacity, capacity)
+
+	f.edges[to] = append(f.edges[to], len(f.to))
+	f.to = append(f.to, from)
+	f.capacity = append(f.capacity, 0)
+}
+
+// maxFlow returns the largest flow from source to sink
+func (f *flowNetwork) maxFlow(source, sink int) int {
+	total := 0
+	for f.levels(source, sink) {
+		for i := range f.next {
+			f.next[i] = 0
+		}
+		for {
+			pushed := f.push(source, sink, int(^uint(0)>>1))
+			if pushed == 0 {
+				break
+			}
+			total += pushed
+		}
+	}
+	return total
+}
+
+// levels numbers the nodes by their distance from the source over edges
+// with capacity left and reports whether the sink can still be reached
+func (f *flowNetwork) levels(source, sink int) bool {
+	for i := range f.level {
+		f.level[i] = -1
+	}
+	f.level[source] = 0
+
+	queue := []int{source}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range f.edges[node] {
+			if f.capacity[edge] > 0 && f.level[f.to[edge]] < 0 {
+				f.level[f.to[edge]] = f.level[node] + 1
+				queue = append(queue, f.to[edge])
+			}
+		}
+	}
+
+	return f.level[sink] >= 0
+}
+
+// push sends up to limit units from node to the sink along edges leading one
+// level further and returns the units sent
+func (f *flowNetwork) push(node, sink, limit int) int {
+	if node == sink {
+		return limit
+	}
+
+	for ; f.next[node] < len(f.edges[node]); f.next[node]++ {
+		edge := f.edges[node][f.next[node]]
+		to := f.to[edge]
+		if f.capacity[edge] == 0 || f.level[to] != f.level[node]+1 {
+			continue
+		}
+		if pushed := f.push(to, sink, min(limit, f.capacity[edge])); pushed > 0 {
+			f.capacity[edge] -= pushed
+			f.capacity[edge^1] += pushed
+			return pushed
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,56 @@
+package model
+
+import "testing"
+
+func TestMaxFlow(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes int
+		edges [][3]int // From, to and capacity
+		want  int
+	}{
+		{
+			name:  "textbook network",
+			nodes: 6,
+			edges: [][3]int{
+				{0, 1, 16}, {0, 2, 13}, {1, 2, 10}, {2, 1, 4}, {1, 3, 12},
+				{3, 2, 9}, {2, 4, 14}, {4, 3, 7}, {3, 5, 20}, {4, 5, 4},
+			},
+			want: 23,
+		},
+		{name: "bottleneck", nodes: 4, edges: [][3]int{{0, 1, 10}, {1, 2, 1}, {2, 3, 10}}, want: 1},
+		{name: "parallel edges", nodes: 3, edges: [][3]int{{0, 1, 3}, {0, 1, 4}, {1, 2, 10}}, want: 7},
+		{name: "sink out of reach", nodes: 4, edges: [][3]int{{0, 1, 5}, {2, 3, 5}}, want: 0},
+		{name: "zero capacity", nodes: 2, edges: [][3]int{{0, 1, 0}}, want: 0},
+		{
+			// Sending the first unit over 1->2 blocks both paths unless it is pushed back
+			name:  "undoing a path",
+			nodes: 4,
+			edges: [][3]int{{0, 1, 1}, {0, 2, 1}, {1, 2, 1}, {1, 3, 1}, {2, 3, 1}},
+			want:  2,
+		},
+		{
+			// Three matches handing out two points each to teams taking one point at most
+			name:  "match network",
+			nodes: 8,
+			edges: [][3]int{
+				{0, 1, 2}, {0, 2, 2}, {0, 3, 2},
+				{1, 4, 2}, {1, 5, 2}, {2, 4, 2}, {2, 6, 2}, {3, 5, 2}, {3, 6, 2},
+				{4, 7, 1}, {5, 7, 1}, {6, 7, 1},
+			},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := newFlowNetwork(tt.nodes)
+			for _, edge := range tt.edges {
+				network.addEdge(edge[0], edge[1], edge[2])
+			}
+			if got := network.maxFlow(0, tt.nodes-1); got != tt.want {
+				t.Errorf("expected a maximum flow of %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
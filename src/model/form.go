@@ -0,0 +1,273 @@
+package model
+
+import (
+	"errors"
+	"math"
+)
+
+// Results of a team's recent matches as listed in its form
+const (
+	FormWin  = "W"
+	FormDraw = "D"
+	FormLoss = "L"
+)
+
+// Windows of the form and fatigue modifiers when not given, also used to
+// report the form of teams of leagues without them
+const (
+	defaultFormMatches  = 5
+	defaultFatigueWeeks = 3
+)
+
+// StrengthModifiers adjust the strength of a team for its recent matches.
+// Each modifier is off unless enabled, and its weight is the largest share
+// of strength it adds or takes away.
+type StrengthModifiers struct {
+	Form     FormModifier     `json:"form"`
+	Momentum MomentumModifier `json:"momentum"`
+	Fatigue  FatigueModifier  `json:"fatigue"`
+}
+
+// FormModifier makes a team stronger the more points it took from its last
+// matches: the full weight for winning them all, minus the weight for losing them all
+type FormModifier struct {
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight,omitempty"`  // 0.1 by default
+	Matches int     `json:"matches,omitempty"` // Last matches counted, 5 by default
+}
+
+// MomentumModifier lifts a team after a big win and drops it after a big
+// loss in its last match by the full weight
+type MomentumModifier struct {
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight,omitempty"` // 0.05 by default
+	Margin  int     `json:"margin,omitempty"` // Goal difference of a big result, 3 by default
+}
+
+// FatigueModifier tires a team for the matches it played in the last weeks:
+// a team that played in every week of the window loses the full weight,
+// while a week without a match, a bye or a postponed match, rests it
+type FatigueModifier struct {
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight,omitempty"` // 0.05 by default
+	Weeks   int     `json:"weeks,omitempty"`  // Weeks looked back on, 3 by default
+}
+
+// ModifierEffect is how a modifier scales a team's strength for its next match
+type ModifierEffect struct {
+	Enabled bool    `json:"enabled"`
+	Value   float64 `json:"value"`  // Share of points taken, goal difference of the last match or share of weeks played
+	Factor  float64 `json:"factor"` // Factor applied to the strength, 1 when disabled
+}
+
+// TeamForm holds the recent results of a team and the effect of the
+// league's strength modifiers on its next match
+type TeamForm struct {
+	LeagueID         int             `json:"league_id"`
+	TeamID           int             `json:"team_id"`
+	TeamName         string          `json:"team_name"`
+	Week             int             `json:"week"`   // Week the modifiers apply to, the league's next week
+	Recent           []string        `json:"recent"` // Results of the last matches, latest first: W, D or L
+	Form             *ModifierEffect `json:"form"`
+	Momentum         *ModifierEffect `json:"momentum"`
+	Fatigue          *ModifierEffect `json:"fatigue"`
+	Factor           float64         `json:"factor"`            // Product of the modifier factors
+	Strength         float64         `json:"strength"`          // Effective strength before the modifiers
+	AdjustedStrength float64         `json:"adjusted_strength"` // Effective strength after the modifiers
+}
+
+// Resolve validates the modifiers and fills in the defaults of the enabled
+// ones. Disabled modifiers resolve to their zero value and nil is returned
+// when none is enabled.
+func (m *StrengthModifiers) Resolve() (*StrengthModifiers, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	for _, weight := range []float64{m.Form.Weight, m.Momentum.Weight, m.Fatigue.Weight} {
+		if weight < 0 || weight > 0.5 {
+			return nil, errors.New("modifier weights must be between 0 and 0.5")
+		}
+	}
+
+	if m.Form.Matches < 0 || m.Momentum.Margin < 0 || m.Fatigue.Weeks < 0 {
+		return nil, errors.New("modifier matches, margin and weeks cannot be negative")
+	}
+
+	resolved := StrengthModifiers{}
+	if m.Form.Enabled {
+		resolved.Form = FormModifier{Enabled: true, Weight: m.Form.Weight, Matches: m.Form.Matches}
+		if resolved.Form.Weight == 0 {
+			resolved.Form.Weight = 0.1
+		}
+		if resolved.Form.Matches == 0 {
+			resolved.Form.Matches = defaultFormMatches
+		}
+	}
+	if m.Momentum.Enabled {
+		resolved.Momentum = MomentumModifier{Enabled: true, Weight: m.Momentum.Weight, Margin: m.Momentum.Margin}
+		if resolved.Momentum.Weight == 0 {
+			resolved.Momentum.Weight = 0.05
+		}
+		if resolved.Momentum.Margin == 0 {
+			resolved.Momentum.Margin = 3
+		}
+	}
+	if m.Fatigue.Enabled {
+		resolved.Fatigue = FatigueModifier{Enabled: true, Weight: m.Fatigue.Weight, Weeks: m.Fatigue.Weeks}
+		if resolved.Fatigue.Weight == 0 {
+			resolved.Fatigue.Weight = 0.05
+		}
+		if resolved.Fatigue.Weeks == 0 {
+			resolved.Fatigue.Weeks = defaultFatigueWeeks
+		}
+	}
+
+	if !resolved.Form.Enabled && !resolved.Momentum.Enabled && !resolved.Fatigue.Enabled {
+		return nil, nil
+	}
+	return &resolved, nil
+}
+
+// TeamForm returns the recent results of a team of the league and the effect
+// of the league's strength modifiers on its match of the next week
+func (l *League) TeamForm(teamID int) (*TeamForm, error) {
+	var team *Team
+	for _, t := range l.Teams {
+		if t.ID == teamID {
+			team = t
+		}
+	}
+	if team == nil {
+		return nil, errors.New("team is not part of the league")
+	}
+
+	week := l.CurrentWeek + 1
+	form := l.teamForm(l.SeasonStateBefore(l.Matches, week).team(teamID), week)
+	form.LeagueID = l.ID
+	form.TeamID = teamID
+	form.TeamName = team.Name
+	form.Week = week
+	form.Strength = l.EffectiveStrength(team)
+	form.AdjustedStrength = form.Strength * form.Factor
+
+	return form, nil
+}
+
+// formWindow returns the number of recent results the form modifier counts,
+// defaultFormMatches when it is disabled
+func (l *League) formWindow() int {
+	if l.Engine.Modifiers != nil && l.Engine.Modifiers.Form.Enabled && l.Engine.Modifiers.Form.Matches > 0 {
+		return l.Engine.Modifiers.Form.Matches
+	}
+	return defaultFormMatches
+}
+
+// fatigueWindow returns the number of weeks the fatigue modifier looks back
+// on, defaultFatigueWeeks when it is disabled
+func (l *League) fatigueWindow() int {
+	if l.Engine.Modifiers != nil && l.Engine.Modifiers.Fatigue.Enabled && l.Engine.Modifiers.Fatigue.Weeks > 0 {
+		return l.Engine.Modifiers.Fatigue.Weeks
+	}
+	return defaultFatigueWeeks
+}
+
+// formFactor returns the factor the league's strength modifiers scale the
+// strength of a team by for its match of the given week, given what the
+// season state has recorded for it
+func (l *League) formFactor(team *teamState, week int) float64 {
+	if l.Engine.Modifiers == nil {
+		return 1
+	}
+	form, momentum, fatigue := l.formEffects(team, week)
+	return form.Factor * momentum.Factor * fatigue.Factor
+}
+
+// teamForm works out the modifiers of a team for its match of the given week
+// from the recent matches the season state has recorded for it
+func (l *League) teamForm(team *teamState, week int) *TeamForm {
+	form, momentum, fatigue := l.formEffects(team, week)
+	teamForm := &TeamForm{
+		Recent:   make([]string, 0, l.formWindow()),
+		Form:     &form,
+		Momentum: &momentum,
+		Fatigue:  &fatigue,
+		Factor:   form.Factor * momentum.Factor * fatigue.Factor,
+	}
+	for i := len(team.recent) - 1; i >= 0 && len(teamForm.Recent) < l.formWindow(); i-- {
+		teamForm.Recent = append(teamForm.Recent, formResult(team.recent[i].difference))
+	}
+	return teamForm
+}
+
+// formEffects returns the effects of the form, momentum and fatigue
+// modifiers on a team with the given recent matches for its match of the
+// given week
+func (l *League) formEffects(team *teamState, week int) (form, momentum, fatigue ModifierEffect) {
+	modifiers := StrengthModifiers{}
+	if l.Engine.Modifiers != nil {
+		modifiers = *l.Engine.Modifiers
+	}
+	form = ModifierEffect{Enabled: modifiers.Form.Enabled, Factor: 1}
+	momentum = ModifierEffect{Enabled: modifiers.Momentum.Enabled, Factor: 1}
+	fatigue = ModifierEffect{Enabled: modifiers.Fatigue.Enabled, Factor: 1}
+
+	// The state keeps at most one match a week, so the matches of the
+	// fatigue window are among its latest ones
+	weeks := l.fatigueWindow()
+	played := 0
+	for i := len(team.recent) - 1; i >= 0 && team.recent[i].week >= week-weeks; i-- {
+		played++
+	}
+	fatigue.Value = float64(played) / float64(weeks)
+	if modifiers.Fatigue.Enabled {
+		fatigue.Factor = 1 - modifiers.Fatigue.Weight*fatigue.Value
+	}
+
+	if len(team.recent) == 0 {
+		return form, momentum, fatigue
+	}
+
+	taken, counted := 0.0, 0
+	for i := len(team.recent) - 1; i >= 0 && counted < l.formWindow(); i-- {
+		switch formResult(team.recent[i].difference) {
+		case FormWin:
+			taken++
+		case FormDraw:
+			taken += 0.5
+		}
+		counted++
+	}
+	form.Value = taken / float64(counted)
+	if modifiers.Form.Enabled {
+		form.Factor = 1 + modifiers.Form.Weight*(2*form.Value-1)
+	}
+
+	difference := team.recent[len(team.recent)-1].difference
+	momentum.Value = float64(difference)
+	if modifiers.Momentum.Enabled && math.Abs(momentum.Value) >= float64(modifiers.Momentum.Margin) {
+		momentum.Factor = 1 + modifiers.Momentum.Weight*math.Copysign(1, float64(difference))
+	}
+
+	return form, momentum, fatigue
+}
+
+// formResult returns the result of a match with the given goal difference: W, D or L
+func formResult(difference int) string {
+	switch {
+	case difference > 0:
+		return FormWin
+	case difference == 0:
+		return FormDraw
+	default:
+		return FormLoss
+	}
+}
+
+// goalDifferenceFor returns the goal difference of a match from the side of the given team
+func goalDifferenceFor(match *Match, teamID int) int {
+	if match.HomeTeamID == teamID {
+		return match.HomeScore - match.AwayScore
+	}
+	return match.AwayScore - match.HomeScore
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolveModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		modifiers *StrengthModifiers
+		want      *StrengthModifiers
+		wantErr   bool
+	}{
+		{name: "none", modifiers: nil, want: nil},
+		{name: "none enabled", modifiers: &StrengthModifiers{Fatigue: FatigueModifier{Weeks: 4}}, want: nil},
+		{
+			name:      "defaults",
+			modifiers: &StrengthModifiers{Form: FormModifier{Enabled: true}, Momentum: MomentumModifier{Enabled: true}, Fatigue: FatigueModifier{Enabled: true}},
+			want: &StrengthModifiers{
+				Form:     FormModifier{Enabled: true, Weight: 0.1, Matches: 5},
+				Momentum: MomentumModifier{Enabled: true, Weight: 0.05, Margin: 3},
+				Fatigue:  FatigueModifier{Enabled: true, Weight: 0.05, Weeks: 3},
+			},
+		},
+		{
+			name:      "fatigue only",
+			modifiers: &StrengthModifiers{Form: FormModifier{Matches: 4}, Fatigue: FatigueModifier{Enabled: true, Weight: 0.2, Weeks: 2}},
+			want:      &StrengthModifiers{Fatigue: FatigueModifier{Enabled: true, Weight: 0.2, Weeks: 2}},
+		},
+		{name: "fatigue weight above half", modifiers: &StrengthModifiers{Fatigue: FatigueModifier{Enabled: true, Weight: 0.6}}, wantErr: true},
+		{name: "negative fatigue weeks", modifiers: &StrengthModifiers{Fatigue: FatigueModifier{Enabled: true, Weeks: -1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.modifiers.Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTeamFormFatigue(t *testing.T) {
+	// Five teams play four matches in five weeks, each sitting one week out
+	league, err := NewLeague("Fatigue League", testTeams(5), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modifiers := &StrengthModifiers{Fatigue: FatigueModifier{Enabled: true, Weight: 0.3, Weeks: 3}}
+	if err := league.SetEngine(EngineConfig{Name: EngineLinear, Modifiers: modifiers}); err != nil {
+		t.Fatal(err)
+	}
+	for week := 1; week <= 3; week++ {
+		if err := league.SimulateWeek(int64(week)); err != nil {
+			t.Fatalf("week %d: %v", week, err)
+		}
+	}
+
+	rested := make(map[int]bool)
+	for _, bye := range league.Byes {
+		if bye.Week <= 3 {
+			rested[bye.TeamID] = true
+		}
+	}
+
+	for _, team := range league.Teams {
+		form, err := league.TeamForm(team.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, factor := 1.0, 0.7
+		if rested[team.ID] {
+			value, factor = 2.0/3, 0.8
+		}
+		if !form.Fatigue.Enabled || !closeTo(form.Fatigue.Value, value) || !closeTo(form.Fatigue.Factor, factor) {
+			t.Errorf("team %d, rested %v: expected fatigue %.3f with factor %.3f, got %+v", team.ID, rested[team.ID], value, factor, form.Fatigue)
+		}
+		if !closeTo(form.Factor, form.Form.Factor*form.Momentum.Factor*form.Fatigue.Factor) {
+			t.Errorf("team %d: factor %.3f is not the product of the modifier factors", team.ID, form.Factor)
+		}
+	}
+}
+
+func TestTeamFormWindow(t *testing.T) {
+	league, err := NewLeague("Form League", testTeams(4), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modifiers := &StrengthModifiers{Form: FormModifier{Enabled: true, Weight: 0.2, Matches: 2}, Momentum: MomentumModifier{Enabled: true, Margin: 1}}
+	if err := league.SetEngine(EngineConfig{Name: EngineLinear, Modifiers: modifiers}); err != nil {
+		t.Fatal(err)
+	}
+	for week := 1; week <= 4; week++ {
+		if err := league.SimulateWeek(int64(week)); err != nil {
+			t.Fatalf("week %d: %v", week, err)
+		}
+	}
+
+	for _, team := range league.Teams {
+		form, err := league.TeamForm(team.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The last two results, latest first
+		played := playedInOrder(league.Matches, team.ID)
+		want := []string{
+			formResult(goalDifferenceFor(played[len(played)-1], team.ID)),
+			formResult(goalDifferenceFor(played[len(played)-2], team.ID)),
+		}
+		if fmt.Sprint(form.Recent) != fmt.Sprint(want) {
+			t.Errorf("team %d: expected recent results %v, got %v", team.ID, want, form.Recent)
+		}
+
+		if difference := goalDifferenceFor(played[len(played)-1], team.ID); form.Momentum.Value != float64(difference) {
+			t.Errorf("team %d: expected momentum %d, got %v", team.ID, difference, form.Momentum.Value)
+		}
+		if form.Fatigue.Enabled || form.Fatigue.Factor != 1 {
+			t.Errorf("team %d: expected fatigue to be disabled, got %+v", team.ID, form.Fatigue)
+		}
+	}
+}
+
+// closeTo reports whether two floats are equal up to rounding
+func closeTo(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
@@ -3,34 +3,96 @@ package model
 import (
 	"errors"
 	"math/rand"
+	"sort"
 	"time"
 )
 
+// Schedule formats, expressed as the number of times each pair of teams
+// meets during a season. Any positive number is accepted for N-fold round robins.
+const (
+	SingleRoundRobin = 1
+	DoubleRoundRobin = 2
+)
+
+// LeagueOptions holds the settings a league is created with
+type LeagueOptions struct {
+	TeamIDs     []int            // Teams taking part, all teams when empty
+	Rounds      int              // Number of round robins
+	Engine      EngineConfig     // Match engine, linear when empty
+	Rating      RatingConfig     // Elo ratings, disabled when empty
+	TieBreakers []string         // Order of the table, DefaultTieBreakers when empty
+	Scoring     *ScoringRules    // Points for results, DefaultScoringRules when nil
+	Discipline  *DisciplineRules // Bans for cards, DefaultDisciplineRules when nil
+	Zones       []PositionBand   // Qualification and relegation zones, DefaultPositionBands when empty
+	Seed        *int64           // League seed, random when nil
+}
+
 // League represents a football league
 type League struct {
-	ID        int      `json:"id"`
-	Name      string   `json:"name"`
-	Teams     []*Team  `json:"teams"`
-	Matches   []*Match `json:"matches,omitempty"`
-	Standings Standings `json:"standings"`
-	CurrentWeek int     `json:"current_week"`
-	TotalWeeks  int     `json:"total_weeks"`
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	Teams        []*Team           `json:"teams"`
+	Matches      []*Match          `json:"matches,omitempty"`
+	Standings    Standings         `json:"standings"`
+	CurrentWeek  int               `json:"current_week"`
+	TotalWeeks   int               `json:"total_weeks"`
+	Rounds       int               `json:"rounds"` // Number of round robins, every second one mirrored
+	Byes         []*Bye            `json:"byes,omitempty"`
+	Engine       EngineConfig      `json:"engine"`
+	Rating       RatingConfig      `json:"rating"`
+	Ratings      []*TeamRating     `json:"ratings,omitempty"` // Ratings after the current week when enabled
+	TieBreakers  []string          `json:"tie_breakers"`      // Rules the table is ordered by, see SortStandings
+	Scoring      ScoringRules      `json:"scoring"`
+	Discipline   DisciplineRules   `json:"discipline"`      // Bans for cards, see Suspensions
+	Zones        []PositionBand    `json:"zones,omitempty"` // Qualification and relegation zones, see PositionZones
+	Deductions   []*PointDeduction `json:"deductions,omitempty"`
+	Seed         int64             `json:"seed"`                    // Week seeds are derived from it, see WeekSeed
+	TournamentID *int              `json:"tournament_id,omitempty"` // Set when the league is a tournament group
+
+	simulator MatchSimulator
+}
+
+// Bye records a team sitting out a week in a league with an odd number of teams
+type Bye struct {
+	TeamID   int    `json:"team_id"`
+	TeamName string `json:"team_name,omitempty"`
+	Week     int    `json:"week"`
+}
+
+// WeeklyFixtures holds the matches and byes of a single week
+type WeeklyFixtures struct {
+	Week    int      `json:"week"`
+	Matches []*Match `json:"matches"`
+	Byes    []*Bye   `json:"byes"`
 }
 
-// NewLeague creates a new league with the given teams
-func NewLeague(name string, teams []*Team) (*League, error) {
+// NewLeague creates a new league with the given teams playing the given number of round robins
+func NewLeague(name string, teams []*Team, rounds int) (*League, error) {
 	if len(teams) < 2 {
 		return nil, errors.New("league must have at least 2 teams")
 	}
 
-	// Calculate total weeks based on round-robin tournament
-	totalWeeks := len(teams) - 1
+	if rounds < 1 {
+		return nil, errors.New("league must play at least one round robin")
+	}
+
+	// Calculate total weeks based on round-robin tournament.
+	// With an odd number of teams every team sits out one week per round robin.
+	weeksPerRound := len(teams) - 1
+	if len(teams)%2 != 0 {
+		weeksPerRound = len(teams)
+	}
+	totalWeeks := weeksPerRound * rounds
 
 	league := &League{
-		Name:       name,
-		Teams:      teams,
+		Name:        name,
+		Teams:       teams,
 		CurrentWeek: 0,
 		TotalWeeks:  totalWeeks,
+		Rounds:      rounds,
+		TieBreakers: append([]string(nil), DefaultTieBreakers...),
+		Scoring:     DefaultScoringRules,
+		Discipline:  DefaultDisciplineRules,
 		Standings: Standings{
 			Teams: make([]TeamStanding, len(teams)),
 			Week:  0,
@@ -51,73 +113,271 @@ func NewLeague(name string, teams []*Team) (*League, error) {
 	return league, nil
 }
 
-// generateSchedule creates a round-robin tournament schedule
+// generateSchedule creates a round-robin tournament schedule using the circle method.
+// Every round robin after the first repeats the pairings of the first one,
+// with home and away swapped in every second one. A team paired with the
+// dummy team of an odd-sized league gets a bye for that week.
 func (l *League) generateSchedule() {
 	numTeams := len(l.Teams)
-	
+
 	// For odd number of teams, add a dummy team
 	if numTeams%2 != 0 {
 		numTeams++
 	}
-	
-	// Generate matches for each week
-	for week := 1; week <= l.TotalWeeks; week++ {
+
+	weeksPerRound := l.TotalWeeks / l.Rounds
+
+	// Team indices in circle order, the first one stays fixed
+	circle := make([]int, numTeams)
+	for i := range circle {
+		circle[i] = i
+	}
+
+	for week := 1; week <= weeksPerRound; week++ {
 		for i := 0; i < numTeams/2; i++ {
-			// Skip matches involving the dummy team
-			if i == 0 && numTeams > len(l.Teams) {
-				continue
-			}
-			
-			// Calculate indices for home and away teams
-			home := (week + i) % (numTeams - 1)
-			away := (numTeams - 1 - i + week) % (numTeams - 1)
-			
-			// Last team stays fixed
-			if i == 0 {
-				away = numTeams - 1
+			home := circle[i]
+			away := circle[numTeams-1-i]
+
+			// The fixed team alternates between home and away
+			if i == 0 && week%2 == 0 {
+				home, away = away, home
 			}
-			
-			// Skip if either team is the dummy team
+
+			// Matches against the dummy team are byes
 			if home >= len(l.Teams) || away >= len(l.Teams) {
+				team := l.Teams[min(home, away)]
+				for round := 0; round < l.Rounds; round++ {
+					l.Byes = append(l.Byes, &Bye{
+						TeamID:   team.ID,
+						TeamName: team.Name,
+						Week:     round*weeksPerRound + week,
+					})
+				}
 				continue
 			}
-			
-			// Create the match
-			match := &Match{
-				HomeTeamID: l.Teams[home].ID,
-				AwayTeamID: l.Teams[away].ID,
-				Week:       week,
-				Played:     false,
+
+			for round := 0; round < l.Rounds; round++ {
+				match := &Match{
+					HomeTeamID: l.Teams[home].ID,
+					AwayTeamID: l.Teams[away].ID,
+					Week:       round*weeksPerRound + week,
+					Played:     false,
+				}
+
+				// Mirror the second leg
+				if round%2 == 1 {
+					match.HomeTeamID, match.AwayTeamID = match.AwayTeamID, match.HomeTeamID
+				}
+
+				l.Matches = append(l.Matches, match)
+			}
+		}
+
+		// Rotate every team except the fixed one
+		last := circle[numTeams-1]
+		copy(circle[2:], circle[1:numTeams-1])
+		circle[1] = last
+	}
+
+	// Keep matches and byes ordered by week
+	sort.SliceStable(l.Matches, func(i, j int) bool {
+		return l.Matches[i].Week < l.Matches[j].Week
+	})
+	sort.SliceStable(l.Byes, func(i, j int) bool {
+		return l.Byes[i].Week < l.Byes[j].Week
+	})
+}
+
+// ByesForWeek returns the byes of the given week
+func (l *League) ByesForWeek(week int) []*Bye {
+	byes := make([]*Bye, 0)
+	for _, bye := range l.Byes {
+		if bye.Week == week {
+			byes = append(byes, bye)
+		}
+	}
+	return byes
+}
+
+// RebuildStandings recomputes the standings from scratch with the played
+// matches, byes and deductions of every week up to the current week and
+// returns the snapshots of fromWeek onwards, each ordered by the league's
+// tie-breakers. The league is left with the standings of its current week.
+func (l *League) RebuildStandings(fromWeek int) []*Standings {
+	standings := Standings{Teams: make([]TeamStanding, len(l.Teams))}
+	for i, team := range l.Teams {
+		standings.Teams[i] = TeamStanding{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+		}
+	}
+
+	var snapshots []*Standings
+	for week := 0; week <= l.CurrentWeek; week++ {
+		for _, match := range l.Matches {
+			if match.Week == week && match.Played {
+				standings.UpdateStandings(match, l.Scoring)
+			}
+		}
+		byes := l.ByesForWeek(week)
+		for _, bye := range byes {
+			standings.RecordBye(bye.TeamID)
+		}
+		for _, deduction := range l.Deductions {
+			if deduction.Week == week {
+				standings.ApplyDeduction(deduction)
 			}
-			
-			l.Matches = append(l.Matches, match)
+		}
+
+		if week < fromWeek {
+			continue
+		}
+		snapshot := &Standings{
+			Teams: append([]TeamStanding(nil), standings.Teams...),
+			Week:  week,
+			Byes:  byes,
+		}
+		l.SortStandings(snapshot, l.Matches)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if len(snapshots) > 0 {
+		l.Standings = *snapshots[len(snapshots)-1]
+	}
+
+	return snapshots
+}
+
+// Rewind takes the league back to the end of the given week. Matches of later
+// weeks become unplayed and lose their events and the standings are rebuilt
+// without the deductions of later weeks, which are kept and apply again once
+// their week is played. It returns the matches that were reset.
+func (l *League) Rewind(week int) ([]*Match, error) {
+	if week < 0 || week > l.CurrentWeek {
+		return nil, errors.New("can only rewind to a week between 0 and the current week")
+	}
+
+	reset := make([]*Match, 0)
+	for _, match := range l.Matches {
+		if match.Week > week && match.Played {
+			match.HomeScore = 0
+			match.AwayScore = 0
+			match.HomePenalties = nil
+			match.AwayPenalties = nil
+			match.Played = false
+			match.PlayedAt = time.Time{}
+			match.Seed = nil
+			match.Events = nil
+			match.HomeLineup = nil
+			match.AwayLineup = nil
+			reset = append(reset, match)
 		}
 	}
+
+	l.CurrentWeek = week
+	l.RebuildStandings(week)
+
+	return reset, nil
 }
 
-// SimulateWeek simulates all matches for the current week
-func (l *League) SimulateWeek() error {
+// SimulateWeek simulates all matches for the current week with a random
+// generator seeded by the given week seed, which is recorded on the matches
+func (l *League) SimulateWeek(seed int64) error {
 	if l.CurrentWeek >= l.TotalWeeks {
 		return errors.New("all weeks have been played")
 	}
-	
+
 	l.CurrentWeek++
-	
+	rng := rand.New(rand.NewSource(seed))
+
 	// Find matches for the current week
 	for _, match := range l.Matches {
 		if match.Week == l.CurrentWeek && !match.Played {
-			l.SimulateMatch(match)
-			l.Standings.UpdateStandings(match)
+			if err := l.SimulateMatch(match, rng); err != nil {
+				return err
+			}
+			match.Seed = &seed
+			l.Standings.UpdateStandings(match, l.Scoring)
+			l.UpdateRatings(match)
 		}
 	}
-	
+
+	// Record teams sitting out the current week
+	byes := l.ByesForWeek(l.CurrentWeek)
+	for _, bye := range byes {
+		l.Standings.RecordBye(bye.TeamID)
+	}
+
 	l.Standings.Week = l.CurrentWeek
-	
+	l.Standings.Byes = byes
+	l.ApplyDeductions(l.CurrentWeek)
+
+	return nil
+}
+
+// ApplyDeductions takes the deductions of the given week off the standings.
+// Deductions are applied when made, so only those a rewind kept for a later
+// week are left to apply when that week is played again.
+func (l *League) ApplyDeductions(week int) {
+	for _, deduction := range l.Deductions {
+		if deduction.Week == week {
+			l.Standings.ApplyDeduction(deduction)
+		}
+	}
+}
+
+// Simulator returns the match engine configured for the league
+func (l *League) Simulator() (MatchSimulator, error) {
+	if l.simulator == nil {
+		simulator, err := NewMatchSimulator(l.Engine)
+		if err != nil {
+			return nil, err
+		}
+		l.simulator = simulator
+	}
+	return l.simulator, nil
+}
+
+// SetEngine validates the engine config and stores it on the league
+// together with the engine's effective parameters and strength modifiers
+func (l *League) SetEngine(config EngineConfig) error {
+	simulator, err := NewMatchSimulator(config)
+	if err != nil {
+		return err
+	}
+
+	modifiers, err := config.Modifiers.Resolve()
+	if err != nil {
+		return err
+	}
+
+	if config.Name == "" {
+		config.Name = EngineLinear
+	}
+
+	l.Engine = EngineConfig{Name: config.Name, Params: simulator.Params(), Modifiers: modifiers}
+	l.simulator = simulator
 	return nil
 }
 
-// SimulateMatch simulates a single match based on team strengths
-func (l *League) SimulateMatch(match *Match) {
+// SimulateMatch simulates a single match with the league's match engine
+// after the league's earlier matches, see SimulateMatchAfter
+func (l *League) SimulateMatch(match *Match, rng *rand.Rand) error {
+	return l.SimulateMatchAfter(match, l.Matches, rng)
+}
+
+// SimulateMatchAfter simulates a single match following the given history,
+// whose results drive the strength modifiers and whose injuries and bans
+// decide the players available to both teams
+func (l *League) SimulateMatchAfter(match *Match, history []*Match, rng *rand.Rand) error {
+	return l.SimulateMatchWith(match, l.SeasonStateBefore(history, match.Week), rng)
+}
+
+// SimulateMatchWith simulates a single match following a season state of the
+// matches before its week, which decides the players available to both teams
+// and drives the strength modifiers. Simulating a run of matches records each
+// of them in the state, see SeasonState.
+func (l *League) SimulateMatchWith(match *Match, state *SeasonState, rng *rand.Rand) error {
 	// Find the teams
 	var homeTeam, awayTeam *Team
 	for _, team := range l.Teams {
@@ -128,32 +388,33 @@ func (l *League) SimulateMatch(match *Match) {
 			awayTeam = team
 		}
 	}
-	
+
 	if homeTeam == nil || awayTeam == nil {
-		return
-	}
-	
-	// Home advantage factor (1.2x)
-	homeAdvantage := 1.2
-	
-	// Calculate effective strengths
-	homeStrength := float64(homeTeam.Strength) * homeAdvantage
-	awayStrength := float64(awayTeam.Strength)
-	
-	// Random factor (0.7 to 1.3)
-	// Use a local random generator (Go 1.20+ recommendation)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	homeRandom := 0.7 + r.Float64()*0.6
-	awayRandom := 0.7 + r.Float64()*0.6
-	
-	// Calculate scores based on strengths and randomness
-	homeScoreFactor := homeStrength * homeRandom / 25.0
-	awayScoreFactor := awayStrength * awayRandom / 30.0
-	
-	// Convert to integer scores (0-5 range is common in football)
-	match.HomeScore = min(int(homeScoreFactor), 5)
-	match.AwayScore = min(int(awayScoreFactor), 5)
-	
+		return errors.New("match teams are not part of the league")
+	}
+
+	simulator, err := l.Simulator()
+	if err != nil {
+		return err
+	}
+
+	match.Events = nil
+	simulator.Simulate(
+		match,
+		l.matchSide(homeTeam, state, match.Week),
+		l.matchSide(awayTeam, state, match.Week),
+		rng,
+	)
+
+	// Decide a draw on penalties when the league's scoring rules ask for it
+	match.HomePenalties, match.AwayPenalties = nil, nil
+	if l.Scoring.DrawShootout && match.HomeScore == match.AwayScore {
+		homePenalties, awayPenalties := penaltyShootout(rng)
+		match.HomePenalties, match.AwayPenalties = &homePenalties, &awayPenalties
+	}
+
 	match.Played = true
 	match.PlayedAt = time.Now()
+
+	return nil
 }
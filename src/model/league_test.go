@@ -0,0 +1,149 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testTeams returns the given number of teams with IDs from 1 and strengths
+// rising with their IDs
+func testTeams(n int) []*Team {
+	teams := make([]*Team, n)
+	for i := range teams {
+		teams[i] = &Team{ID: i + 1, Name: fmt.Sprintf("Team %d", i+1), Strength: 40 + 50*i/n}
+	}
+	return teams
+}
+
+func TestNewLeagueSchedule(t *testing.T) {
+	tests := []struct {
+		teams      int
+		rounds     int
+		totalWeeks int
+	}{
+		{teams: 2, rounds: SingleRoundRobin, totalWeeks: 1},
+		{teams: 4, rounds: SingleRoundRobin, totalWeeks: 3},
+		{teams: 4, rounds: DoubleRoundRobin, totalWeeks: 6},
+		{teams: 6, rounds: 3, totalWeeks: 15},
+		{teams: 20, rounds: DoubleRoundRobin, totalWeeks: 38},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d teams %d rounds", tt.teams, tt.rounds), func(t *testing.T) {
+			league, err := NewLeague("Schedule League", testTeams(tt.teams), tt.rounds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if league.TotalWeeks != tt.totalWeeks {
+				t.Fatalf("expected %d weeks, got %d", tt.totalWeeks, league.TotalWeeks)
+			}
+			if len(league.Byes) != 0 {
+				t.Errorf("expected no byes in an even league, got %d", len(league.Byes))
+			}
+			checkSchedule(t, league)
+		})
+	}
+}
+
+func TestNewLeagueByes(t *testing.T) {
+	tests := []struct {
+		teams      int
+		rounds     int
+		totalWeeks int
+	}{
+		{teams: 3, rounds: SingleRoundRobin, totalWeeks: 3},
+		{teams: 5, rounds: SingleRoundRobin, totalWeeks: 5},
+		{teams: 5, rounds: DoubleRoundRobin, totalWeeks: 10},
+		{teams: 7, rounds: 3, totalWeeks: 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d teams %d rounds", tt.teams, tt.rounds), func(t *testing.T) {
+			league, err := NewLeague("Bye League", testTeams(tt.teams), tt.rounds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if league.TotalWeeks != tt.totalWeeks {
+				t.Fatalf("expected %d weeks, got %d", tt.totalWeeks, league.TotalWeeks)
+			}
+			checkSchedule(t, league)
+
+			// Each team sits out exactly one week per round robin
+			weeksPerRound := league.TotalWeeks / league.Rounds
+			byes := make(map[[2]int]int)
+			for _, bye := range league.Byes {
+				byes[[2]int{bye.TeamID, (bye.Week - 1) / weeksPerRound}]++
+			}
+			for _, team := range league.Teams {
+				for round := 0; round < league.Rounds; round++ {
+					if count := byes[[2]int{team.ID, round}]; count != 1 {
+						t.Errorf("team %d: expected one bye in round robin %d, got %d", team.ID, round+1, count)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewLeagueErrors(t *testing.T) {
+	if _, err := NewLeague("Small League", testTeams(1), SingleRoundRobin); err == nil {
+		t.Error("expected an error for a single team")
+	}
+	if _, err := NewLeague("Empty League", testTeams(4), 0); err == nil {
+		t.Error("expected an error for no round robins")
+	}
+}
+
+// checkSchedule checks that every team plays or sits out each week exactly
+// once and that every pair meets once per round robin, the second of every
+// two round robins mirroring the home and away teams of the first
+func checkSchedule(t *testing.T, league *League) {
+	t.Helper()
+
+	weeksPerRound := league.TotalWeeks / league.Rounds
+	for week := 1; week <= league.TotalWeeks; week++ {
+		busy := make(map[int]int)
+		for _, match := range league.Matches {
+			if match.Week == week {
+				busy[match.HomeTeamID]++
+				busy[match.AwayTeamID]++
+			}
+		}
+		for _, bye := range league.ByesForWeek(week) {
+			busy[bye.TeamID]++
+		}
+		for _, team := range league.Teams {
+			if busy[team.ID] != 1 {
+				t.Errorf("week %d: expected team %d to play or sit out once, got %d", week, team.ID, busy[team.ID])
+			}
+		}
+	}
+
+	// Home team of every pair in every round robin
+	hosts := make(map[[3]int]int)
+	for _, match := range league.Matches {
+		if match.Week < 1 || match.Week > league.TotalWeeks {
+			t.Fatalf("match %d-%d: week %d out of the season", match.HomeTeamID, match.AwayTeamID, match.Week)
+		}
+		pair := [3]int{min(match.HomeTeamID, match.AwayTeamID), max(match.HomeTeamID, match.AwayTeamID), (match.Week - 1) / weeksPerRound}
+		if _, ok := hosts[pair]; ok {
+			t.Errorf("teams %d and %d meet twice in round robin %d", pair[0], pair[1], pair[2]+1)
+		}
+		hosts[pair] = match.HomeTeamID
+	}
+
+	for i, home := range league.Teams {
+		for _, away := range league.Teams[i+1:] {
+			for round := 0; round < league.Rounds; round++ {
+				host, ok := hosts[[3]int{home.ID, away.ID, round}]
+				if !ok {
+					t.Errorf("teams %d and %d do not meet in round robin %d", home.ID, away.ID, round+1)
+					continue
+				}
+				if round%2 == 1 && host == hosts[[3]int{home.ID, away.ID, round - 1}] {
+					t.Errorf("teams %d and %d: round robin %d does not mirror the one before", home.ID, away.ID, round+1)
+				}
+			}
+		}
+	}
+}
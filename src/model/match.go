@@ -8,6 +8,7 @@ import (
 // Match represents a football match between two teams
 type Match struct {
 	ID         int       `json:"id"`
+	LeagueID   int       `json:"league_id"`
 	HomeTeamID int       `json:"home_team_id"`
 	AwayTeamID int       `json:"away_team_id"`
 	HomeTeam   *Team     `json:"home_team,omitempty"`
@@ -17,10 +18,23 @@ type Match struct {
 	Week       int       `json:"week"`
 	Played     bool      `json:"played"`
 	PlayedAt   time.Time `json:"played_at,omitempty"`
+	Seed       *int64    `json:"seed,omitempty"` // Seed of the random generator the match's week was simulated with
+
+	HomePenalties *int `json:"home_penalties,omitempty"` // Shootout of a drawn match in leagues deciding draws on penalties
+	AwayPenalties *int `json:"away_penalties,omitempty"`
+
+	Events []*MatchEvent `json:"-"` // Timeline of matches simulated by the event engine, see GET /matches/{id}/events
+
+	HomeLineup []int `json:"home_lineup,omitempty"` // Starting players of matches simulated by the event engine, goalkeeper first
+	AwayLineup []int `json:"away_lineup,omitempty"`
 }
 
 // Validate checks if the match data is valid
 func (m *Match) Validate() error {
+	if m.LeagueID < 1 {
+		return errors.New("league id must be a positive number")
+	}
+
 	if m.HomeTeamID == m.AwayTeamID {
 		return errors.New("home team and away team cannot be the same")
 	}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Playing positions of a player
+const (
+	PositionGoalkeeper = "GK"
+	PositionDefender   = "DF"
+	PositionMidfielder = "MF"
+	PositionForward    = "FW"
+)
+
+// startingOutfielders is the number of outfield players in a starting line-up
+const startingOutfielders = 10
+
+// Player represents a member of a team's squad. Ratings use the 1-100 scale of Team.Strength.
+type Player struct {
+	ID          int    `json:"id"`
+	TeamID      int    `json:"team_id"`
+	Name        string `json:"name"`
+	Position    string `json:"position"` // GK, DF, MF or FW
+	ShirtNumber int    `json:"shirt_number"`
+	Attack      int    `json:"attack"`
+	Defence     int    `json:"defence"`
+	Goalkeeping int    `json:"goalkeeping"`
+	Age         int    `json:"age"`
+}
+
+// Validate checks if the player data is valid
+func (p *Player) Validate() error {
+	if p.Name == "" {
+		return errors.New("player name cannot be empty")
+	}
+
+	switch p.Position {
+	case PositionGoalkeeper, PositionDefender, PositionMidfielder, PositionForward:
+	default:
+		return fmt.Errorf("unknown position %q, expected GK, DF, MF or FW", p.Position)
+	}
+
+	if p.ShirtNumber < 1 || p.ShirtNumber > 99 {
+		return errors.New("shirt number must be between 1 and 99")
+	}
+
+	ratings := []struct {
+		name  string
+		value int
+	}{{"attack", p.Attack}, {"defence", p.Defence}, {"goalkeeping", p.Goalkeeping}}
+	for _, rating := range ratings {
+		if rating.value < 1 || rating.value > 100 {
+			return fmt.Errorf("%s rating must be between 1 and 100", rating.name)
+		}
+	}
+
+	if p.Age < 15 || p.Age > 50 {
+		return errors.New("player age must be between 15 and 50")
+	}
+
+	return nil
+}
+
+// Rating returns how good the player is in their position: goalkeeping for
+// goalkeepers, defence for defenders, attack for forwards and the average of
+// attack and defence for midfielders
+func (p *Player) Rating() float64 {
+	switch p.Position {
+	case PositionGoalkeeper:
+		return float64(p.Goalkeeping)
+	case PositionDefender:
+		return float64(p.Defence)
+	case PositionForward:
+		return float64(p.Attack)
+	default:
+		return float64(p.Attack+p.Defence) / 2
+	}
+}
+
+// BestLineup picks the best line-up of the given players: the best
+// goalkeeper and the ten best outfield players, each rated in their
+// position. The others make up the bench, best first. It returns false when
+// the players do not make up a line-up with a goalkeeper and ten outfield
+// players.
+func BestLineup(players []*Player) (lineup, bench []*Player, ok bool) {
+	var goalkeeper *Player
+	outfield := make([]*Player, 0, len(players))
+	for _, player := range players {
+		if player.Position == PositionGoalkeeper {
+			if goalkeeper == nil || player.Rating() > goalkeeper.Rating() {
+				if goalkeeper != nil {
+					bench = append(bench, goalkeeper)
+				}
+				goalkeeper = player
+				continue
+			}
+			bench = append(bench, player)
+			continue
+		}
+		outfield = append(outfield, player)
+	}
+
+	if goalkeeper == nil || len(outfield) < startingOutfielders {
+		return nil, nil, false
+	}
+
+	sort.SliceStable(outfield, func(i, j int) bool {
+		return outfield[i].Rating() > outfield[j].Rating()
+	})
+
+	lineup = append([]*Player{goalkeeper}, outfield[:startingOutfielders]...)
+	bench = append(bench, outfield[startingOutfielders:]...)
+	sort.SliceStable(bench, func(i, j int) bool {
+		return bench[i].Rating() > bench[j].Rating()
+	})
+
+	return lineup, bench, true
+}
+
+// SquadStrength derives a team strength from the best line-up of the given
+// players, see BestLineup. It returns false when the players do not make up
+// a line-up.
+func SquadStrength(players []*Player) (int, bool) {
+	lineup, _, ok := BestLineup(players)
+	if !ok {
+		return 0, false
+	}
+
+	total := 0.0
+	for _, player := range lineup {
+		total += player.Rating()
+	}
+
+	strength := int(math.Round(total / float64(len(lineup))))
+	if strength < 1 {
+		strength = 1
+	}
+	if strength > 100 {
+		strength = 100
+	}
+	return strength, true
+}
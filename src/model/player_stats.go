@@ -0,0 +1,153 @@
+package model
+
+import "sort"
+
+// PlayerStats holds the league statistics of a player
+type PlayerStats struct {
+	PlayerID    int    `json:"player_id"`
+	PlayerName  string `json:"player_name"`
+	TeamID      int    `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	Position    string `json:"position,omitempty"`
+	Appearances int    `json:"appearances"` // Starts and substitute appearances
+	Goals       int    `json:"goals"`
+	Assists     int    `json:"assists"`
+	CleanSheets int    `json:"clean_sheets"` // Matches a goalkeeper played throughout without conceding
+	YellowCards int    `json:"yellow_cards"`
+	RedCards    int    `json:"red_cards"`
+}
+
+// PlayerStatsReport holds the player leaderboards of a league, built from
+// the timelines of its matches simulated by the event engine
+type PlayerStatsReport struct {
+	LeagueID    int            `json:"league_id"`
+	Week        int            `json:"week"`
+	TopScorers  []*PlayerStats `json:"top_scorers"`
+	Assists     []*PlayerStats `json:"assists"`
+	CleanSheets []*PlayerStats `json:"clean_sheets"`
+	Discipline  []*PlayerStats `json:"discipline"`  // Players with a card, most red and then yellow cards first
+	Suspensions []*Suspension  `json:"suspensions"` // Bans still to be served
+}
+
+// PlayerStats builds the player leaderboards of the league from its played
+// matches, so that edited and rewound results are always reflected
+func (l *League) PlayerStats() *PlayerStatsReport {
+	stats := make(map[int]*PlayerStats)
+	teamNames := make(map[int]string, len(l.Teams))
+	for _, team := range l.Teams {
+		teamNames[team.ID] = team.Name
+		for _, player := range team.Players {
+			stats[player.ID] = &PlayerStats{
+				PlayerID:   player.ID,
+				PlayerName: player.Name,
+				TeamID:     team.ID,
+				TeamName:   team.Name,
+				Position:   player.Position,
+			}
+		}
+	}
+
+	// Players who have since left the squads are known from the events only
+	get := func(playerID int, name string, teamID int) *PlayerStats {
+		s, ok := stats[playerID]
+		if !ok {
+			s = &PlayerStats{PlayerID: playerID, PlayerName: name, TeamID: teamID, TeamName: teamNames[teamID]}
+			stats[playerID] = s
+		}
+		return s
+	}
+
+	for _, match := range playedInOrder(l.Matches, 0) {
+		for _, lineup := range [][]int{match.HomeLineup, match.AwayLineup} {
+			for _, playerID := range lineup {
+				if s, ok := stats[playerID]; ok {
+					s.Appearances++
+				}
+			}
+		}
+
+		// Goalkeepers taken off or sent off did not play throughout
+		left := make(map[int]bool)
+		for _, event := range match.Events {
+			if event.PlayerID == nil {
+				continue
+			}
+			s := get(*event.PlayerID, event.PlayerName, event.TeamID)
+			switch event.Type {
+			case EventGoal:
+				s.Goals++
+				if event.RelatedPlayerID != nil {
+					get(*event.RelatedPlayerID, event.RelatedPlayerName, event.TeamID).Assists++
+				}
+			case EventYellowCard:
+				s.YellowCards++
+			case EventRedCard:
+				s.RedCards++
+				left[s.PlayerID] = true
+			case EventSubstitution:
+				left[s.PlayerID] = true
+				if event.RelatedPlayerID != nil {
+					get(*event.RelatedPlayerID, event.RelatedPlayerName, event.TeamID).Appearances++
+				}
+			}
+		}
+
+		if len(match.HomeLineup) > 0 && match.AwayScore == 0 && !left[match.HomeLineup[0]] {
+			if s, ok := stats[match.HomeLineup[0]]; ok {
+				s.CleanSheets++
+			}
+		}
+		if len(match.AwayLineup) > 0 && match.HomeScore == 0 && !left[match.AwayLineup[0]] {
+			if s, ok := stats[match.AwayLineup[0]]; ok {
+				s.CleanSheets++
+			}
+		}
+	}
+
+	all := make([]*PlayerStats, 0, len(stats))
+	for _, s := range stats {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].PlayerID < all[j].PlayerID })
+
+	report := &PlayerStatsReport{
+		LeagueID:    l.ID,
+		Week:        l.CurrentWeek,
+		TopScorers:  leaderboard(all, func(s *PlayerStats) []int { return []int{s.Goals, s.Assists} }),
+		Assists:     leaderboard(all, func(s *PlayerStats) []int { return []int{s.Assists, s.Goals} }),
+		CleanSheets: leaderboard(all, func(s *PlayerStats) []int { return []int{s.CleanSheets} }),
+		Discipline:  leaderboard(all, func(s *PlayerStats) []int { return []int{s.RedCards, s.YellowCards} }),
+		Suspensions: make([]*Suspension, 0),
+	}
+
+	for _, ban := range l.Suspensions() {
+		if ban.Remaining > 0 {
+			report.Suspensions = append(report.Suspensions, ban)
+		}
+	}
+
+	return report
+}
+
+// leaderboard returns the players with a positive first key, ordered by the
+// keys in turn from the highest and then by name
+func leaderboard(players []*PlayerStats, keys func(*PlayerStats) []int) []*PlayerStats {
+	board := make([]*PlayerStats, 0)
+	for _, s := range players {
+		if keys(s)[0] > 0 {
+			board = append(board, s)
+		}
+	}
+
+	sort.SliceStable(board, func(i, j int) bool {
+		a, b := keys(board[i]), keys(board[j])
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] > b[k]
+			}
+		}
+		return board[i].PlayerName < board[j].PlayerName
+	})
+
+	return board
+}
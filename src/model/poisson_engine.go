@@ -0,0 +1,206 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// scoreCacheSize caps the number of scoreline distributions a Poisson engine
+// keeps, as strength modifiers can make the expected goals of every match differ
+const scoreCacheSize = 4096
+
+// PoissonSimulator samples scorelines from independent Poisson distributions
+// of home and away goals, optionally corrected for low scores as proposed by
+// Dixon and Coles (1997)
+type PoissonSimulator struct {
+	BaseGoals     float64 // Expected goals per team between two average teams at a neutral venue
+	HomeAdvantage float64 // Multiplier on the home team's expected goals
+	AttackWeight  float64 // How strongly strength translates into scoring
+	DefenceWeight float64 // How strongly strength translates into preventing goals
+	Rho           float64 // Dixon-Coles low-score dependence, 0 disables the correction
+	MaxGoals      int     // Scores are truncated at MaxGoals per team
+
+	cache *scoreCache // Set by NewPoissonSimulator, distributions are rebuilt every match without it
+}
+
+// scoreCache keeps the cumulative scoreline distributions of the expected goals
+// an engine has simulated. The teams of a league meet with the same strengths
+// in every run of a prediction, so the distributions are built once per pairing
+// rather than once per match. It is safe for concurrent use.
+type scoreCache struct {
+	distributions sync.Map // Expected home and away goals to cumulative probabilities
+	size          atomic.Int64
+}
+
+// NewPoissonSimulator creates a Poisson engine, overriding its defaults with the given parameters
+func NewPoissonSimulator(params map[string]float64) (*PoissonSimulator, error) {
+	p, err := engineParams(EnginePoisson, params, map[string]float64{
+		"base_goals":     1.35,
+		"home_advantage": 1.25,
+		"attack_weight":  1.0,
+		"defence_weight": 1.0,
+		"rho":            -0.1,
+		"max_goals":      10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p["base_goals"] <= 0 || p["home_advantage"] <= 0 {
+		return nil, errors.New("base_goals and home_advantage must be positive")
+	}
+
+	if p["attack_weight"] < 0 || p["defence_weight"] < 0 {
+		return nil, errors.New("attack_weight and defence_weight cannot be negative")
+	}
+
+	if p["rho"] < -1 || p["rho"] > 1 {
+		return nil, errors.New("rho must be between -1 and 1")
+	}
+
+	if p["max_goals"] < 1 {
+		return nil, errors.New("max_goals must be at least 1")
+	}
+
+	return &PoissonSimulator{
+		BaseGoals:     p["base_goals"],
+		HomeAdvantage: p["home_advantage"],
+		AttackWeight:  p["attack_weight"],
+		DefenceWeight: p["defence_weight"],
+		Rho:           p["rho"],
+		MaxGoals:      int(p["max_goals"]),
+		cache:         &scoreCache{},
+	}, nil
+}
+
+// ExpectedGoals derives the expected goals of both sides from their strengths.
+// A team of strength 50 is average: its attack and defence ratings are 1.
+func (s *PoissonSimulator) ExpectedGoals(home, away MatchSide) (float64, float64) {
+	attack := func(side MatchSide) float64 {
+		return math.Pow(side.Strength/50.0, s.AttackWeight)
+	}
+	defence := func(side MatchSide) float64 {
+		return math.Pow(side.Strength/50.0, s.DefenceWeight)
+	}
+
+	homeGoals := s.BaseGoals * s.HomeAdvantage * attack(home) / defence(away)
+	awayGoals := s.BaseGoals * attack(away) / defence(home)
+
+	return homeGoals, awayGoals
+}
+
+// ScoreProbabilities returns the probability of every scoreline up to MaxGoals,
+// indexed as [homeGoals][awayGoals]
+func (s *PoissonSimulator) ScoreProbabilities(homeGoals, awayGoals float64) [][]float64 {
+	homeProbabilities := poissonProbabilities(homeGoals, s.MaxGoals)
+	awayProbabilities := poissonProbabilities(awayGoals, s.MaxGoals)
+
+	total := 0.0
+	probabilities := make([][]float64, s.MaxGoals+1)
+	for h := range probabilities {
+		probabilities[h] = make([]float64, s.MaxGoals+1)
+		for a := range probabilities[h] {
+			p := homeProbabilities[h] * awayProbabilities[a] * s.tau(h, a, homeGoals, awayGoals)
+			probabilities[h][a] = p
+			total += p
+		}
+	}
+
+	// Normalize away the truncated tail and the correction
+	for h := range probabilities {
+		for a := range probabilities[h] {
+			probabilities[h][a] /= total
+		}
+	}
+
+	return probabilities
+}
+
+// tau is the Dixon-Coles adjustment of the low scorelines 0-0, 1-0, 0-1 and 1-1
+func (s *PoissonSimulator) tau(homeScore, awayScore int, homeGoals, awayGoals float64) float64 {
+	var t float64
+	switch {
+	case homeScore == 0 && awayScore == 0:
+		t = 1 - homeGoals*awayGoals*s.Rho
+	case homeScore == 0 && awayScore == 1:
+		t = 1 + homeGoals*s.Rho
+	case homeScore == 1 && awayScore == 0:
+		t = 1 + awayGoals*s.Rho
+	case homeScore == 1 && awayScore == 1:
+		t = 1 - s.Rho
+	default:
+		t = 1
+	}
+	return math.Max(t, 0)
+}
+
+// Simulate samples a scoreline for the match
+func (s *PoissonSimulator) Simulate(match *Match, home, away MatchSide, rng *rand.Rand) {
+	cumulative := s.cumulativeProbabilities(s.ExpectedGoals(home, away))
+
+	target := rng.Float64()
+	i := sort.Search(len(cumulative), func(i int) bool { return target < cumulative[i] })
+	if i == len(cumulative) {
+		// Rounding left the target just above the last cumulative value
+		match.HomeScore = s.MaxGoals
+		match.AwayScore = s.MaxGoals
+		return
+	}
+
+	match.HomeScore = i / (s.MaxGoals + 1)
+	match.AwayScore = i % (s.MaxGoals + 1)
+}
+
+// cumulativeProbabilities returns the running total of the scoreline
+// probabilities in the order of ScoreProbabilities, home goals first,
+// taking it from the cache when the expected goals have been seen before
+func (s *PoissonSimulator) cumulativeProbabilities(homeGoals, awayGoals float64) []float64 {
+	key := [2]float64{homeGoals, awayGoals}
+	if s.cache != nil {
+		if cached, ok := s.cache.distributions.Load(key); ok {
+			return cached.([]float64)
+		}
+	}
+
+	probabilities := s.ScoreProbabilities(homeGoals, awayGoals)
+	cumulative := make([]float64, 0, (s.MaxGoals+1)*(s.MaxGoals+1))
+	total := 0.0
+	for h := range probabilities {
+		for a := range probabilities[h] {
+			total += probabilities[h][a]
+			cumulative = append(cumulative, total)
+		}
+	}
+
+	if s.cache != nil && s.cache.size.Add(1) <= scoreCacheSize {
+		s.cache.distributions.Store(key, cumulative)
+	}
+	return cumulative
+}
+
+// Params returns the parameters of the Poisson engine
+func (s *PoissonSimulator) Params() map[string]float64 {
+	return map[string]float64{
+		"base_goals":     s.BaseGoals,
+		"home_advantage": s.HomeAdvantage,
+		"attack_weight":  s.AttackWeight,
+		"defence_weight": s.DefenceWeight,
+		"rho":            s.Rho,
+		"max_goals":      float64(s.MaxGoals),
+	}
+}
+
+// poissonProbabilities returns P(X = k) for k = 0..maxGoals of a Poisson distribution with the given mean
+func poissonProbabilities(mean float64, maxGoals int) []float64 {
+	probabilities := make([]float64, maxGoals+1)
+	p := math.Exp(-mean)
+	for k := 0; k <= maxGoals; k++ {
+		probabilities[k] = p
+		p *= mean / float64(k+1)
+	}
+	return probabilities
+}
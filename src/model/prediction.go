@@ -1,10 +1,42 @@
 package model
 
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Limits of the Monte Carlo predictions
+const (
+	DefaultPredictionIterations = 100
+	MaxPredictionIterations     = 1000000
+	MaxPredictionWorkers        = 256
+)
+
+// PredictionOptions holds the settings of a Monte Carlo prediction
+type PredictionOptions struct {
+	Seed       *int64 // Seed of the simulations, the league's prediction seed when nil
+	Iterations int    // Number of simulated seasons, DefaultPredictionIterations when zero
+	Workers    int    // Goroutines running the simulations, one per CPU when zero
+}
+
+// Validate checks the options are within the prediction limits
+func (o PredictionOptions) Validate() error {
+	if o.Iterations < 0 || o.Iterations > MaxPredictionIterations {
+		return fmt.Errorf("iterations must be between 1 and %d", MaxPredictionIterations)
+	}
+	if o.Workers < 0 || o.Workers > MaxPredictionWorkers {
+		return fmt.Errorf("workers must be between 1 and %d", MaxPredictionWorkers)
+	}
+	return nil
+}
+
 // PredictionResult - 4. hafta sonrası tahmin sonuçları
 type PredictionResult struct {
 	CurrentWeek       int               `json:"current_week"`      // Şu anki hafta
 	TotalWeeks        int               `json:"total_weeks"`       // Toplam hafta sayısı
 	PredictionType    string            `json:"prediction_type"`   // Tahmin türü
+	Iterations        int               `json:"iterations,omitempty"` // Simülasyon sayısı
 	Standings         *Standings        `json:"predicted_standings"` // Tahmini puan tablosu
 	TeamPredictions   []*TeamPrediction `json:"team_predictions"`  // Takım bazlı tahminler
 	Confidence        float64           `json:"confidence_percentage"` // Güven yüzdesi
@@ -18,7 +50,114 @@ type TeamPrediction struct {
 	PredictedPoints          int     `json:"predicted_points"`          // Tahmini final puanı
 	MostLikelyPosition       int     `json:"most_likely_position"`      // En olası sıralaması
 	ChampionshipProbability  float64 `json:"championship_probability"`  // Şampiyonluk olasılığı
-	TopThreeProbability      float64 `json:"top_three_probability"`     // İlk 3'e girme olasılığı
-	RelegationProbability    float64 `json:"relegation_probability"`    // Küme düşme olasılığı
-	PositionCounts           []int   `json:"-"`                         // Hesaplama için kullanılır
+	TopThreeProbability      float64 `json:"top_three_probability"`     // Tablonun tepesindeki en geniş bölgeye (bölge yoksa ilk 3'e) girme olasılığı, bkz. SummaryZones
+	RelegationProbability    float64 `json:"relegation_probability"`    // Tablonun dibindeki en geniş bölgeye (bölge yoksa son sıraya) düşme olasılığı
+	ZoneProbabilities        []*ZoneProbability `json:"zone_probabilities"` // Ligin bölgelerinde bitirme olasılıkları
+	PositionCounts           []int   `json:"position_counts"`           // Her sırada bitirdiği simülasyon sayısı
+	PositionProbabilities    []float64 `json:"position_probabilities"`  // Her sırada bitirme olasılığı, 1. sıradan başlayarak
+	StandardErrors           *PredictionErrors `json:"standard_errors"` // Olasılıkların Monte Carlo standart hataları
+	Points                   *Distribution `json:"points_distribution"`         // Final puanlarının dağılımı
+	GoalDifference           *Distribution `json:"goal_difference_distribution"` // Final averajlarının dağılımı
+	Clinch                   []*ClinchStatus `json:"clinch"`            // Matematiksel olarak kesinleşen durumlar
+}
+
+// PredictionErrors holds the Monte Carlo standard errors of a team's
+// probabilities, in percentage points
+type PredictionErrors struct {
+	Championship float64   `json:"championship"`
+	TopThree     float64   `json:"top_three"`
+	Relegation   float64   `json:"relegation"`
+	Positions    []float64 `json:"positions"` // Per position, 1st first
+}
+
+// Distribution describes how a value is spread over the simulated seasons
+type Distribution struct {
+	Histogram   []HistogramBucket `json:"histogram"` // Every value reached, lowest first
+	Mean        float64           `json:"mean"`
+	StdDev      float64           `json:"std_dev"`
+	StdError    float64           `json:"standard_error"` // Monte Carlo standard error of the mean
+	Percentiles Percentiles       `json:"percentiles"`
+}
+
+// HistogramBucket is the number of simulations ending on a value
+type HistogramBucket struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// Percentiles of a distribution, by the nearest rank
+type Percentiles struct {
+	P5  int `json:"p5"`
+	P25 int `json:"p25"`
+	P50 int `json:"p50"`
+	P75 int `json:"p75"`
+	P95 int `json:"p95"`
+}
+
+// NewDistribution builds the distribution of the values counted in the histogram
+func NewDistribution(counts map[int]int) *Distribution {
+	d := &Distribution{Histogram: make([]HistogramBucket, 0, len(counts))}
+	n := 0
+	for value, count := range counts {
+		d.Histogram = append(d.Histogram, HistogramBucket{Value: value, Count: count})
+		n += count
+	}
+	if n == 0 {
+		return d
+	}
+	sort.Slice(d.Histogram, func(i, j int) bool {
+		return d.Histogram[i].Value < d.Histogram[j].Value
+	})
+
+	sum := 0.0
+	for _, bucket := range d.Histogram {
+		sum += float64(bucket.Value * bucket.Count)
+	}
+	d.Mean = sum / float64(n)
+
+	if n > 1 {
+		squares := 0.0
+		for _, bucket := range d.Histogram {
+			deviation := float64(bucket.Value) - d.Mean
+			squares += deviation * deviation * float64(bucket.Count)
+		}
+		d.StdDev = math.Sqrt(squares / float64(n-1))
+		d.StdError = d.StdDev / math.Sqrt(float64(n))
+	}
+
+	d.Percentiles = Percentiles{
+		P5:  d.percentile(n, 5),
+		P25: d.percentile(n, 25),
+		P50: d.percentile(n, 50),
+		P75: d.percentile(n, 75),
+		P95: d.percentile(n, 95),
+	}
+
+	return d
+}
+
+// percentile returns the value at the given percentile of n simulations
+func (d *Distribution) percentile(n int, p int) int {
+	rank := int(math.Ceil(float64(p) / 100 * float64(n)))
+	if rank < 1 {
+		rank = 1
+	}
+	seen := 0
+	for _, bucket := range d.Histogram {
+		seen += bucket.Count
+		if seen >= rank {
+			return bucket.Value
+		}
+	}
+	return d.Histogram[len(d.Histogram)-1].Value
+}
+
+// ProbabilityStdError returns the standard error, in percentage points, of a
+// probability estimated from the given number of hits in n simulations
+func ProbabilityStdError(hits, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	p := float64(hits) / float64(n)
+	return math.Sqrt(p*(1-p)/float64(n)) * 100
 } 
\ No newline at end of file
@@ -0,0 +1,247 @@
+package model
+
+import (
+	"errors"
+	"math"
+)
+
+// EloBaseRating is the rating of a team of average strength (50)
+const EloBaseRating = 1500.0
+
+// RatingConfig enables Elo ratings for a league. Ratings start from the
+// team strengths, change after every match and replace the static strengths
+// in the match engine while enabled.
+type RatingConfig struct {
+	Enabled           bool    `json:"enabled"`
+	KFactor           float64 `json:"k_factor,omitempty"`            // Maximum rating change of a one-goal result, 20 by default
+	HomeAdvantage     float64 `json:"home_advantage,omitempty"`      // Rating points added to the home team's expectation, 60 by default
+	PointsPerStrength float64 `json:"points_per_strength,omitempty"` // Rating points per point of team strength, 10 by default
+}
+
+// TeamRating is the rating of a team after a week, week 0 holding the initial rating
+type TeamRating struct {
+	TeamID   int     `json:"team_id"`
+	TeamName string  `json:"team_name"`
+	Week     int     `json:"week"`
+	Rating   float64 `json:"rating"`
+}
+
+// RatingPoint is a single week of a team's rating history
+type RatingPoint struct {
+	Week   int     `json:"week"`
+	Rating float64 `json:"rating"`
+}
+
+// TeamRatingHistory holds the ratings of a team over the season
+type TeamRatingHistory struct {
+	TeamID   int            `json:"team_id"`
+	TeamName string         `json:"team_name"`
+	Rating   float64        `json:"rating"`   // Latest rating
+	Strength float64        `json:"strength"` // Latest rating on the team strength scale
+	History  []*RatingPoint `json:"history"`
+}
+
+// RatingHistory holds the rating histories of all teams of a league
+type RatingHistory struct {
+	LeagueID int                  `json:"league_id"`
+	Config   RatingConfig         `json:"config"`
+	Teams    []*TeamRatingHistory `json:"teams"`
+}
+
+// Resolve validates the config and fills in the defaults of unset parameters.
+// A disabled config resolves to the zero config.
+func (c RatingConfig) Resolve() (RatingConfig, error) {
+	if !c.Enabled {
+		return RatingConfig{}, nil
+	}
+
+	if c.KFactor < 0 || c.HomeAdvantage < 0 || c.PointsPerStrength < 0 {
+		return RatingConfig{}, errors.New("rating parameters cannot be negative")
+	}
+
+	if c.KFactor == 0 {
+		c.KFactor = 20
+	}
+	if c.HomeAdvantage == 0 {
+		c.HomeAdvantage = 60
+	}
+	if c.PointsPerStrength == 0 {
+		c.PointsPerStrength = 10
+	}
+
+	return c, nil
+}
+
+// SetRating validates the rating config, stores it on the league with its
+// defaults resolved and sets the initial ratings when enabled
+func (l *League) SetRating(config RatingConfig) error {
+	config, err := config.Resolve()
+	if err != nil {
+		return err
+	}
+
+	l.Rating = config
+	l.Ratings = l.InitialRatings()
+	return nil
+}
+
+// InitialRatings returns the week 0 ratings derived from the team strengths,
+// or nil when ratings are disabled
+func (l *League) InitialRatings() []*TeamRating {
+	if !l.Rating.Enabled {
+		return nil
+	}
+
+	ratings := make([]*TeamRating, len(l.Teams))
+	for i, team := range l.Teams {
+		ratings[i] = &TeamRating{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			Week:     0,
+			Rating:   EloBaseRating + (float64(team.Strength)-50)*l.Rating.PointsPerStrength,
+		}
+	}
+	return ratings
+}
+
+// TeamRating returns the current rating of a team, or nil when it has none
+func (l *League) TeamRating(teamID int) *TeamRating {
+	for _, rating := range l.Ratings {
+		if rating.TeamID == teamID {
+			return rating
+		}
+	}
+	return nil
+}
+
+// EffectiveStrength returns the strength the match engine uses for a team:
+// its rating converted to the strength scale when ratings are enabled,
+// its static strength otherwise
+func (l *League) EffectiveStrength(team *Team) float64 {
+	if l.Rating.Enabled {
+		if rating := l.TeamRating(team.ID); rating != nil {
+			return ratingToStrength(rating.Rating, l.Rating.PointsPerStrength)
+		}
+	}
+	return float64(team.Strength)
+}
+
+// UpdateRatings applies the Elo update of a played match to both teams.
+// The change is scaled up for wins by two or more goals.
+func (l *League) UpdateRatings(match *Match) {
+	if !l.Rating.Enabled || !match.Played {
+		return
+	}
+
+	home := l.TeamRating(match.HomeTeamID)
+	away := l.TeamRating(match.AwayTeamID)
+	if home == nil || away == nil {
+		return
+	}
+
+	expected := 1 / (1 + math.Pow(10, (away.Rating-home.Rating-l.Rating.HomeAdvantage)/400))
+
+	actual := 0.5
+	if match.HomeScore > match.AwayScore {
+		actual = 1
+	} else if match.HomeScore < match.AwayScore {
+		actual = 0
+	}
+
+	margin := 1.0
+	goalDifference := math.Abs(float64(match.HomeScore - match.AwayScore))
+	if goalDifference == 2 {
+		margin = 1.5
+	} else if goalDifference > 2 {
+		margin = (11 + goalDifference) / 8
+	}
+
+	change := l.Rating.KFactor * margin * (actual - expected)
+	home.Rating += change
+	away.Rating -= change
+	home.Week = match.Week
+	away.Week = match.Week
+}
+
+// RatingsSnapshot returns a copy of the current ratings recorded for the given week
+func (l *League) RatingsSnapshot(week int) []*TeamRating {
+	snapshot := make([]*TeamRating, len(l.Ratings))
+	for i, rating := range l.Ratings {
+		snapshot[i] = &TeamRating{
+			TeamID:   rating.TeamID,
+			TeamName: rating.TeamName,
+			Week:     week,
+			Rating:   rating.Rating,
+		}
+	}
+	return snapshot
+}
+
+// RebuildRatings replays the played matches of every week up to the current
+// week on top of the given initial ratings and returns the snapshot of every
+// week. The league is left with the ratings of its current week.
+func (l *League) RebuildRatings(initial []*TeamRating) []*TeamRating {
+	if !l.Rating.Enabled {
+		return nil
+	}
+
+	l.Ratings = make([]*TeamRating, len(initial))
+	for i, rating := range initial {
+		l.Ratings[i] = &TeamRating{
+			TeamID:   rating.TeamID,
+			TeamName: rating.TeamName,
+			Rating:   rating.Rating,
+		}
+	}
+
+	var snapshots []*TeamRating
+	for week := 1; week <= l.CurrentWeek; week++ {
+		for _, match := range l.Matches {
+			if match.Week == week {
+				l.UpdateRatings(match)
+			}
+		}
+		snapshots = append(snapshots, l.RatingsSnapshot(week)...)
+	}
+
+	return snapshots
+}
+
+// NewRatingHistory groups the rating snapshots of a league by team.
+// The snapshots must be ordered by week; weeks after the current week are ignored.
+func NewRatingHistory(league *League, ratings []*TeamRating) *RatingHistory {
+	history := &RatingHistory{
+		LeagueID: league.ID,
+		Config:   league.Rating,
+		Teams:    make([]*TeamRatingHistory, 0, len(league.Teams)),
+	}
+
+	for _, team := range league.Teams {
+		teamHistory := &TeamRatingHistory{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			History:  make([]*RatingPoint, 0),
+		}
+
+		for _, rating := range ratings {
+			if rating.TeamID != team.ID || rating.Week > league.CurrentWeek {
+				continue
+			}
+			teamHistory.History = append(teamHistory.History, &RatingPoint{Week: rating.Week, Rating: rating.Rating})
+			teamHistory.Rating = rating.Rating
+		}
+
+		if len(teamHistory.History) > 0 {
+			teamHistory.Strength = ratingToStrength(teamHistory.Rating, league.Rating.PointsPerStrength)
+		}
+
+		history.Teams = append(history.Teams, teamHistory)
+	}
+
+	return history
+}
+
+// ratingToStrength converts a rating back to the 1-100 strength scale
+func ratingToStrength(rating, pointsPerStrength float64) float64 {
+	return math.Max(50+(rating-EloBaseRating)/pointsPerStrength, 1)
+}
@@ -0,0 +1,150 @@
+package model
+
+// Random streams derived from a league, cup or tournament seed
+const (
+	seedStreamWeek = iota + 1
+	seedStreamPrediction
+	seedStreamCupDraw
+	seedStreamCupRound
+	seedStreamTournamentDraw
+	seedStreamTournamentGroup
+	seedStreamTournamentKnockout
+	seedStreamDrawingOfLots
+)
+
+// ReplayResult compares a league's stored results with a replay from its seeds
+type ReplayResult struct {
+	LeagueID      int               `json:"league_id"`
+	Seed          int64             `json:"seed"`
+	WeeksReplayed int               `json:"weeks_replayed"`
+	Identical     bool              `json:"identical"`
+	Mismatches    []*ReplayMismatch `json:"mismatches"`
+	Standings     *Standings        `json:"replayed_standings"`
+}
+
+// ReplayMismatch describes a match whose replayed score differs from the stored one
+type ReplayMismatch struct {
+	MatchID           int `json:"match_id"`
+	Week              int `json:"week"`
+	HomeTeamID        int `json:"home_team_id"`
+	AwayTeamID        int `json:"away_team_id"`
+	StoredHomeScore   int `json:"stored_home_score"`
+	StoredAwayScore   int `json:"stored_away_score"`
+	ReplayedHomeScore int `json:"replayed_home_score"`
+	ReplayedAwayScore int `json:"replayed_away_score"`
+}
+
+// DeriveSeed mixes the given values into seed with the SplitMix64 finalizer,
+// so that independent random streams can be derived from a single seed
+func DeriveSeed(seed int64, values ...int64) int64 {
+	z := uint64(seed)
+	for _, value := range values {
+		z += (uint64(value) + 1) * 0x9E3779B97F4A7C15
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		z ^= z >> 31
+	}
+	return int64(z)
+}
+
+// WeekSeed returns the seed the given week was simulated with, or the seed
+// derived from the league seed for weeks that have not been played yet
+func (l *League) WeekSeed(week int) int64 {
+	for _, match := range l.Matches {
+		if match.Week == week && match.Played && match.Seed != nil {
+			return *match.Seed
+		}
+	}
+	return DeriveSeed(l.Seed, seedStreamWeek, int64(week))
+}
+
+// PredictionSeed returns the default seed for predictions from the league's current state
+func (l *League) PredictionSeed() int64 {
+	return DeriveSeed(l.Seed, seedStreamPrediction, int64(l.CurrentWeek))
+}
+
+// Replay re-simulates every played week of the league on a fresh copy of its
+// schedule, using the recorded week seeds, and reports matches whose replayed
+// scores differ from the stored ones. The league itself is not modified.
+func (l *League) Replay() (*ReplayResult, error) {
+	replay := &League{
+		ID:          l.ID,
+		Name:        l.Name,
+		Teams:       l.Teams,
+		TotalWeeks:  l.TotalWeeks,
+		Rounds:      l.Rounds,
+		Byes:        l.Byes,
+		Engine:      l.Engine,
+		Rating:      l.Rating,
+		TieBreakers: l.TieBreakers,
+		Scoring:     l.Scoring,
+		Discipline:  l.Discipline,
+		Seed:        l.Seed,
+		Standings: Standings{
+			Teams: make([]TeamStanding, len(l.Teams)),
+		},
+	}
+
+	for i, team := range l.Teams {
+		replay.Standings.Teams[i] = TeamStanding{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+		}
+	}
+
+	replay.Ratings = replay.InitialRatings()
+
+	replay.Matches = make([]*Match, len(l.Matches))
+	for i, match := range l.Matches {
+		replay.Matches[i] = &Match{
+			ID:         match.ID,
+			LeagueID:   match.LeagueID,
+			HomeTeamID: match.HomeTeamID,
+			AwayTeamID: match.AwayTeamID,
+			Week:       match.Week,
+		}
+	}
+
+	for replay.CurrentWeek < l.CurrentWeek {
+		if err := replay.SimulateWeek(l.WeekSeed(replay.CurrentWeek + 1)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, deduction := range l.Deductions {
+		if deduction.Week <= replay.CurrentWeek {
+			replay.Standings.ApplyDeduction(deduction)
+		}
+	}
+	replay.SortStandings(&replay.Standings, replay.Matches)
+
+	result := &ReplayResult{
+		LeagueID:      l.ID,
+		Seed:          l.Seed,
+		WeeksReplayed: replay.CurrentWeek,
+		Mismatches:    make([]*ReplayMismatch, 0),
+		Standings:     &replay.Standings,
+	}
+
+	for i, stored := range l.Matches {
+		replayed := replay.Matches[i]
+		if stored.Played != replayed.Played {
+			continue
+		}
+		if stored.HomeScore != replayed.HomeScore || stored.AwayScore != replayed.AwayScore {
+			result.Mismatches = append(result.Mismatches, &ReplayMismatch{
+				MatchID:           stored.ID,
+				Week:              stored.Week,
+				HomeTeamID:        stored.HomeTeamID,
+				AwayTeamID:        stored.AwayTeamID,
+				StoredHomeScore:   stored.HomeScore,
+				StoredAwayScore:   stored.AwayScore,
+				ReplayedHomeScore: replayed.HomeScore,
+				ReplayedAwayScore: replayed.AwayScore,
+			})
+		}
+	}
+	result.Identical = len(result.Mismatches) == 0
+
+	return result, nil
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ForcedResult fixes the result of a fixture in a what-if scenario. The match
+// is identified by its ID, or by its teams and, when they meet at the same
+// ground more than once, its week.
+type ForcedResult struct {
+	MatchID       int  `json:"match_id,omitempty"`
+	HomeTeamID    int  `json:"home_team_id,omitempty"`
+	AwayTeamID    int  `json:"away_team_id,omitempty"`
+	Week          int  `json:"week,omitempty"`
+	HomeScore     int  `json:"home_score"`
+	AwayScore     int  `json:"away_score"`
+	HomePenalties *int `json:"home_penalties,omitempty"` // Required for draws in leagues deciding draws on penalties
+	AwayPenalties *int `json:"away_penalties,omitempty"`
+}
+
+// ScenarioPrediction compares the predictions of a what-if scenario with the
+// predictions from the real state of the league
+type ScenarioPrediction struct {
+	Results  []*Match              `json:"results"` // Matches with their forced results
+	Baseline *PredictionResult     `json:"baseline"`
+	Scenario *PredictionResult     `json:"scenario"`
+	Diff     []*TeamPredictionDiff `json:"diff"`
+}
+
+// TeamPredictionDiff is the change in a team's prediction under a scenario,
+// probabilities in percentage points
+type TeamPredictionDiff struct {
+	TeamID                  int     `json:"team_id"`
+	TeamName                string  `json:"team_name"`
+	PredictedPoints         int     `json:"predicted_points"`
+	MostLikelyPosition      int     `json:"most_likely_position"`
+	ChampionshipProbability float64 `json:"championship_probability"`
+	TopThreeProbability     float64 `json:"top_three_probability"`
+	RelegationProbability   float64 `json:"relegation_probability"`
+
+	ZoneProbabilities map[string]float64 `json:"zone_probabilities"` // Per zone of the league
+}
+
+// ApplyScenario plays the given results in memory and returns the forced
+// matches. Results of played matches replace the real ones and the current
+// standings are rebuilt; results of later weeks are left for predictions to
+// pick up.
+func (l *League) ApplyScenario(results []ForcedResult) ([]*Match, error) {
+	forced := make([]*Match, 0, len(results))
+	seen := make(map[int]bool, len(results))
+	rebuild := false
+
+	for _, result := range results {
+		match, err := l.findScenarioMatch(result)
+		if err != nil {
+			return nil, err
+		}
+		if seen[match.ID] {
+			return nil, fmt.Errorf("match %d is listed more than once", match.ID)
+		}
+		seen[match.ID] = true
+
+		if result.HomeScore < 0 || result.AwayScore < 0 {
+			return nil, errors.New("scores cannot be negative")
+		}
+
+		homePenalties, awayPenalties := result.HomePenalties, result.AwayPenalties
+		if result.HomeScore == result.AwayScore && l.Scoring.DrawShootout {
+			if homePenalties == nil || awayPenalties == nil {
+				return nil, fmt.Errorf("the draw of match %d needs a penalty shootout result", match.ID)
+			}
+			if *homePenalties < 0 || *awayPenalties < 0 || *homePenalties == *awayPenalties {
+				return nil, fmt.Errorf("the penalty shootout of match %d needs a winner", match.ID)
+			}
+		} else {
+			homePenalties, awayPenalties = nil, nil
+		}
+
+		match.HomeScore = result.HomeScore
+		match.AwayScore = result.AwayScore
+		match.HomePenalties = homePenalties
+		match.AwayPenalties = awayPenalties
+		if !match.Played {
+			match.Played = true
+			match.PlayedAt = time.Time{}
+		}
+		if match.Week <= l.CurrentWeek {
+			rebuild = true
+		}
+
+		forced = append(forced, match)
+	}
+
+	if rebuild {
+		l.RebuildStandings(l.CurrentWeek)
+	}
+
+	// The stored clinches do not know the forced results
+	for i := range l.Standings.Teams {
+		l.Standings.Teams[i].Clinch = nil
+	}
+
+	return forced, nil
+}
+
+// findScenarioMatch returns the match of the league a forced result refers to
+func (l *League) findScenarioMatch(result ForcedResult) (*Match, error) {
+	var found *Match
+	for _, match := range l.Matches {
+		if result.MatchID != 0 {
+			if match.ID == result.MatchID {
+				return match, nil
+			}
+			continue
+		}
+
+		if match.HomeTeamID != result.HomeTeamID || match.AwayTeamID != result.AwayTeamID {
+			continue
+		}
+		if result.Week != 0 && match.Week != result.Week {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("teams %d and %d meet more than once at the same ground, the week is required",
+				result.HomeTeamID, result.AwayTeamID)
+		}
+		found = match
+	}
+
+	if found == nil {
+		if result.MatchID != 0 {
+			return nil, fmt.Errorf("match %d is not part of the league", result.MatchID)
+		}
+		return nil, fmt.Errorf("no match of team %d at home to team %d", result.HomeTeamID, result.AwayTeamID)
+	}
+
+	return found, nil
+}
+
+// DiffPredictions returns the change of every team's prediction from the
+// baseline to the scenario, in the order of the scenario's predictions
+func DiffPredictions(baseline, scenario *PredictionResult) []*TeamPredictionDiff {
+	before := make(map[int]*TeamPrediction, len(baseline.TeamPredictions))
+	for _, pred := range baseline.TeamPredictions {
+		before[pred.TeamID] = pred
+	}
+
+	diff := make([]*TeamPredictionDiff, 0, len(scenario.TeamPredictions))
+	for _, pred := range scenario.TeamPredictions {
+		base, ok := before[pred.TeamID]
+		if !ok {
+			continue
+		}
+		zones := make(map[string]float64, len(pred.ZoneProbabilities))
+		for _, zone := range base.ZoneProbabilities {
+			zones[zone.Zone] -= zone.Probability
+		}
+		for _, zone := range pred.ZoneProbabilities {
+			zones[zone.Zone] += zone.Probability
+		}
+
+		diff = append(diff, &TeamPredictionDiff{
+			TeamID:                  pred.TeamID,
+			TeamName:                pred.TeamName,
+			PredictedPoints:         pred.PredictedPoints - base.PredictedPoints,
+			MostLikelyPosition:      pred.MostLikelyPosition - base.MostLikelyPosition,
+			ChampionshipProbability: pred.ChampionshipProbability - base.ChampionshipProbability,
+			TopThreeProbability:     pred.TopThreeProbability - base.TopThreeProbability,
+			RelegationProbability:   pred.RelegationProbability - base.RelegationProbability,
+			ZoneProbabilities:       zones,
+		})
+	}
+
+	return diff
+}
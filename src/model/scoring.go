@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// ScoringRules decide how many points a result is worth
+type ScoringRules struct {
+	WinPoints          int  `json:"win_points"`
+	DrawPoints         int  `json:"draw_points"`
+	LossPoints         int  `json:"loss_points"`
+	ScoringBonusGoals  int  `json:"scoring_bonus_goals"`  // Goals a team must score for a bonus point, 0 disables the bonus
+	ScoringBonusPoints int  `json:"scoring_bonus_points"` // Points for scoring at least ScoringBonusGoals goals
+	LosingBonusMargin  int  `json:"losing_bonus_margin"`  // Largest defeat still earning a bonus point, 0 disables the bonus
+	LosingBonusPoints  int  `json:"losing_bonus_points"`  // Points for losing by at most LosingBonusMargin goals
+	DrawShootout       bool `json:"draw_shootout"`        // Drawn matches are decided by a penalty shootout
+	ShootoutWinPoints  int  `json:"shootout_win_points"`  // Points for winning the shootout of a drawn match
+	ShootoutLossPoints int  `json:"shootout_loss_points"` // Points for losing the shootout of a drawn match
+}
+
+// DefaultScoringRules awards 3 points for a win and 1 for a draw
+var DefaultScoringRules = ScoringRules{WinPoints: 3, DrawPoints: 1}
+
+// PointDeduction is an administrative penalty taking points off a team from a week onwards
+type PointDeduction struct {
+	ID        int       `json:"id"`
+	LeagueID  int       `json:"league_id"`
+	TeamID    int       `json:"team_id"`
+	TeamName  string    `json:"team_name,omitempty"`
+	Points    int       `json:"points"` // Points taken off, a negative value gives points back
+	Reason    string    `json:"reason"`
+	Week      int       `json:"week"` // First standings week the deduction applies to
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResolveScoringRules validates scoring rules, returning the default rules when none are given
+func ResolveScoringRules(rules *ScoringRules) (ScoringRules, error) {
+	if rules == nil {
+		return DefaultScoringRules, nil
+	}
+
+	r := *rules
+	if r.WinPoints < 0 || r.DrawPoints < 0 || r.LossPoints < 0 ||
+		r.ScoringBonusGoals < 0 || r.ScoringBonusPoints < 0 ||
+		r.LosingBonusMargin < 0 || r.LosingBonusPoints < 0 ||
+		r.ShootoutWinPoints < 0 || r.ShootoutLossPoints < 0 {
+		return ScoringRules{}, errors.New("scoring rules cannot be negative")
+	}
+
+	if r.WinPoints <= r.LossPoints {
+		return ScoringRules{}, errors.New("a win must be worth more points than a loss")
+	}
+
+	if r.DrawShootout {
+		if r.ShootoutWinPoints > r.WinPoints || r.ShootoutLossPoints > r.ShootoutWinPoints || r.LossPoints > r.ShootoutLossPoints {
+			return ScoringRules{}, errors.New("shootout points must lie between the points for a loss and a win")
+		}
+	} else if r.DrawPoints > r.WinPoints || r.LossPoints > r.DrawPoints {
+		return ScoringRules{}, errors.New("draw points must lie between the points for a loss and a win")
+	}
+
+	return r, nil
+}
+
+// SetScoring validates the scoring rules and stores them on the league
+func (l *League) SetScoring(rules *ScoringRules) error {
+	resolved, err := ResolveScoringRules(rules)
+	if err != nil {
+		return err
+	}
+
+	l.Scoring = resolved
+	return nil
+}
+
+// result returns the points and the bonus points among them a team earns
+// for scoring goalsFor and conceding goalsAgainst. shootout is the outcome
+// of the penalty shootout of a drawn match, 0 when there was none.
+func (r ScoringRules) result(goalsFor, goalsAgainst, shootout int) (points, bonus int) {
+	switch {
+	case goalsFor > goalsAgainst:
+		points = r.WinPoints
+	case goalsFor < goalsAgainst:
+		points = r.LossPoints
+		if r.LosingBonusMargin > 0 && goalsAgainst-goalsFor <= r.LosingBonusMargin {
+			bonus += r.LosingBonusPoints
+		}
+	case r.DrawShootout && shootout > 0:
+		points = r.ShootoutWinPoints
+	case r.DrawShootout && shootout < 0:
+		points = r.ShootoutLossPoints
+	default:
+		points = r.DrawPoints
+	}
+
+	if r.ScoringBonusGoals > 0 && goalsFor >= r.ScoringBonusGoals {
+		bonus += r.ScoringBonusPoints
+	}
+
+	return points + bonus, bonus
+}
+
+// ApplyDeduction takes the points of a deduction off its team
+func (s *Standings) ApplyDeduction(deduction *PointDeduction) {
+	for i := range s.Teams {
+		if s.Teams[i].TeamID == deduction.TeamID {
+			s.Teams[i].Points -= deduction.Points
+			s.Teams[i].PointsDeducted += deduction.Points
+			return
+		}
+	}
+}
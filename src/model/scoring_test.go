@@ -0,0 +1,99 @@
+package model
+
+import "testing"
+
+func TestScoringRulesResult(t *testing.T) {
+	// Four points for a win and bonus points for four goals or a defeat by two at most
+	bonus := ScoringRules{WinPoints: 4, DrawPoints: 2, ScoringBonusGoals: 4, ScoringBonusPoints: 1, LosingBonusMargin: 2, LosingBonusPoints: 1}
+	shootout := ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 2, ShootoutLossPoints: 1}
+
+	tests := []struct {
+		name         string
+		rules        ScoringRules
+		goalsFor     int
+		goalsAgainst int
+		shootout     int
+		points       int
+		bonus        int
+	}{
+		{name: "win", rules: DefaultScoringRules, goalsFor: 2, goalsAgainst: 1, points: 3},
+		{name: "draw", rules: DefaultScoringRules, goalsFor: 1, goalsAgainst: 1, points: 1},
+		{name: "loss", rules: DefaultScoringRules, goalsFor: 0, goalsAgainst: 3, points: 0},
+		{name: "draw without a shootout rule", rules: DefaultScoringRules, goalsFor: 0, goalsAgainst: 0, shootout: 1, points: 1},
+		{name: "win with scoring bonus", rules: bonus, goalsFor: 4, goalsAgainst: 0, points: 5, bonus: 1},
+		{name: "win without bonus", rules: bonus, goalsFor: 3, goalsAgainst: 0, points: 4},
+		{name: "draw with scoring bonus", rules: bonus, goalsFor: 4, goalsAgainst: 4, points: 3, bonus: 1},
+		{name: "narrow loss", rules: bonus, goalsFor: 1, goalsAgainst: 3, points: 1, bonus: 1},
+		{name: "heavy loss", rules: bonus, goalsFor: 1, goalsAgainst: 4, points: 0},
+		{name: "narrow loss with scoring bonus", rules: bonus, goalsFor: 4, goalsAgainst: 5, points: 2, bonus: 2},
+		{name: "shootout win", rules: shootout, goalsFor: 2, goalsAgainst: 2, shootout: 1, points: 2},
+		{name: "shootout loss", rules: shootout, goalsFor: 2, goalsAgainst: 2, shootout: -1, points: 1},
+		{name: "win in a shootout league", rules: shootout, goalsFor: 1, goalsAgainst: 0, shootout: 1, points: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, bonus := tt.rules.result(tt.goalsFor, tt.goalsAgainst, tt.shootout)
+			if points != tt.points || bonus != tt.bonus {
+				t.Errorf("expected %d points with %d bonus, got %d with %d", tt.points, tt.bonus, points, bonus)
+			}
+		})
+	}
+}
+
+func TestResolveScoringRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   *ScoringRules
+		wantErr bool
+	}{
+		{name: "default", rules: nil},
+		{name: "two points for a win", rules: &ScoringRules{WinPoints: 2, DrawPoints: 1}},
+		{name: "shootout", rules: &ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 2, ShootoutLossPoints: 1}},
+		{name: "negative", rules: &ScoringRules{WinPoints: 3, LossPoints: -1}, wantErr: true},
+		{name: "win not above loss", rules: &ScoringRules{WinPoints: 1, LossPoints: 1, DrawPoints: 1}, wantErr: true},
+		{name: "draw above win", rules: &ScoringRules{WinPoints: 2, DrawPoints: 3}, wantErr: true},
+		{name: "shootout win above win", rules: &ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 4}, wantErr: true},
+		{name: "shootout loss above shootout win", rules: &ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 1, ShootoutLossPoints: 2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ResolveScoringRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.rules == nil && rules != DefaultScoringRules {
+				t.Errorf("expected the default rules, got %+v", rules)
+			}
+		})
+	}
+}
+
+func TestUpdateStandingsShootout(t *testing.T) {
+	rules := ScoringRules{WinPoints: 3, DrawShootout: true, ShootoutWinPoints: 2, ShootoutLossPoints: 1}
+	standings := Standings{Teams: []TeamStanding{{TeamID: 1}, {TeamID: 2}}}
+
+	homePenalties, awayPenalties := 3, 4
+	standings.UpdateStandings(&Match{HomeTeamID: 1, AwayTeamID: 2, HomeScore: 1, AwayScore: 1, Played: true, HomePenalties: &homePenalties, AwayPenalties: &awayPenalties}, rules)
+
+	// The shootout decides the points, the match still counts as a draw
+	home, away := standings.Teams[0], standings.Teams[1]
+	if home.Points != 1 || away.Points != 2 || home.Draws != 1 || away.Draws != 1 {
+		t.Errorf("expected a draw worth 1 and 2 points, got %+v and %+v", home, away)
+	}
+}
+
+func TestApplyDeduction(t *testing.T) {
+	standings := Standings{Teams: []TeamStanding{{TeamID: 1, Points: 10}, {TeamID: 2, Points: 7}}}
+
+	standings.ApplyDeduction(&PointDeduction{TeamID: 1, Points: 6})
+	standings.ApplyDeduction(&PointDeduction{TeamID: 1, Points: -2})
+
+	if team := standings.Teams[0]; team.Points != 6 || team.PointsDeducted != 4 {
+		t.Errorf("expected 6 points after a net deduction of 4, got %+v", team)
+	}
+	if team := standings.Teams[1]; team.Points != 7 || team.PointsDeducted != 0 {
+		t.Errorf("expected the other team to keep its points, got %+v", team)
+	}
+}
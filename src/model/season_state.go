@@ -0,0 +1,75 @@
+package model
+
+import "slices"
+
+// SeasonState carries what a league's played matches leave its teams with
+// into their next matches: the players injured or banned and the recent
+// results the strength modifiers follow. Simulating a run of matches moves
+// it on match by match, so that no match goes through the history of the
+// season again.
+type SeasonState struct {
+	league *League
+	teams  map[int]*teamState
+}
+
+// teamState is what the recorded matches leave a team with
+type teamState struct {
+	availability availability
+	recent       []recentMatch // Last matches of the form and fatigue windows, latest last
+}
+
+// recentMatch is a match the form and fatigue modifiers follow
+type recentMatch struct {
+	week       int
+	difference int // Goal difference from the side of the team
+}
+
+// SeasonStateBefore returns the state of the league after the played
+// matches of history before the given week
+func (l *League) SeasonStateBefore(history []*Match, week int) *SeasonState {
+	state := &SeasonState{league: l, teams: make(map[int]*teamState, len(l.Teams))}
+	for _, match := range playedInOrder(history, 0) {
+		if match.Week >= week {
+			break
+		}
+		state.Record(match)
+	}
+	return state
+}
+
+// Clone returns a copy of the state to move on separately
+func (s *SeasonState) Clone() *SeasonState {
+	clone := &SeasonState{league: s.league, teams: make(map[int]*teamState, len(s.teams))}
+	for teamID, team := range s.teams {
+		clone.teams[teamID] = &teamState{availability: team.availability.clone(), recent: slices.Clone(team.recent)}
+	}
+	return clone
+}
+
+// Record moves the state on past a played match. Matches are recorded in
+// the order of their weeks, each team playing once a week.
+func (s *SeasonState) Record(match *Match) {
+	window := max(s.league.formWindow(), s.league.fatigueWindow())
+	for _, teamID := range []int{match.HomeTeamID, match.AwayTeamID} {
+		team, ok := s.teams[teamID]
+		if !ok {
+			team = &teamState{}
+			s.teams[teamID] = team
+		}
+
+		team.availability.record(s.league, match, teamID)
+
+		team.recent = append(team.recent, recentMatch{week: match.Week, difference: goalDifferenceFor(match, teamID)})
+		if len(team.recent) > window {
+			team.recent = slices.Delete(team.recent, 0, len(team.recent)-window)
+		}
+	}
+}
+
+// team returns what the recorded matches leave a team with
+func (s *SeasonState) team(teamID int) *teamState {
+	if team, ok := s.teams[teamID]; ok {
+		return team
+	}
+	return &teamState{}
+}
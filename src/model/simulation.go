@@ -15,6 +15,7 @@ type LeagueSimulationResult struct {
 type WeeklyResult struct {
 	Week            int            `json:"week"`              // Hangi hafta
 	Matches         []*MatchResult `json:"matches"`           // O haftanın maçları
+	Byes            []*Bye         `json:"byes,omitempty"`    // O hafta bay geçen takımlar
 	StandingsBefore *Standings     `json:"standings_before"`  // Hafta öncesi puan durumu
 	StandingsAfter  *Standings     `json:"standings_after"`   // Hafta sonrası puan durumu
 }
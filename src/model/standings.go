@@ -2,26 +2,43 @@ package model
 
 // TeamStanding represents a team's position in the league standings
 type TeamStanding struct {
-	TeamID        int    `json:"team_id"`
-	TeamName      string `json:"team_name"`
-	Points        int    `json:"points"`
-	Played        int    `json:"played"`
-	Wins          int    `json:"wins"`
-	Draws         int    `json:"draws"`
-	Losses        int    `json:"losses"`
-	GoalsFor      int    `json:"goals_for"`
-	GoalsAgainst  int    `json:"goals_against"`
+	TeamID         int    `json:"team_id"`
+	TeamName       string `json:"team_name"`
+	Points         int    `json:"points"`
+	Played         int    `json:"played"`
+	Wins           int    `json:"wins"`
+	Draws          int    `json:"draws"`
+	Losses         int    `json:"losses"`
+	GoalsFor       int    `json:"goals_for"`
+	GoalsAgainst   int    `json:"goals_against"`
 	GoalDifference int    `json:"goal_difference"`
+	Byes           int    `json:"byes"`
+	BonusPoints    int    `json:"bonus_points"`    // Bonus points included in Points
+	PointsDeducted int    `json:"points_deducted"` // Points taken off by deductions
+
+	Zones  []string        `json:"zones,omitempty"`  // Zones of the league the position falls in, see AnnotateZones
+	Clinch []*ClinchStatus `json:"clinch,omitempty"` // Decided position bands of the current standings, see AnnotateClinches
 }
 
 // Standings represents the league standings
 type Standings struct {
 	Teams []TeamStanding `json:"teams"`
 	Week  int            `json:"week"`
+	Byes  []*Bye         `json:"byes,omitempty"` // Teams sitting out Week
+}
+
+// RecordBye counts a bye for the given team
+func (s *Standings) RecordBye(teamID int) {
+	for i := range s.Teams {
+		if s.Teams[i].TeamID == teamID {
+			s.Teams[i].Byes++
+			return
+		}
+	}
 }
 
-// UpdateStandings updates the standings based on a match result
-func (s *Standings) UpdateStandings(match *Match) {
+// UpdateStandings updates the standings based on a match result scored with the given rules
+func (s *Standings) UpdateStandings(match *Match, rules ScoringRules) {
 	if !match.Played {
 		return
 	}
@@ -37,6 +54,16 @@ func (s *Standings) UpdateStandings(match *Match) {
 		}
 	}
 
+	// Outcome of the shootout of a drawn match from the home team's perspective
+	shootout := 0
+	if match.HomePenalties != nil && match.AwayPenalties != nil {
+		if *match.HomePenalties > *match.AwayPenalties {
+			shootout = 1
+		} else if *match.HomePenalties < *match.AwayPenalties {
+			shootout = -1
+		}
+	}
+
 	// Update home team stats
 	if homeTeamIndex >= 0 {
 		s.Teams[homeTeamIndex].Played++
@@ -46,13 +73,15 @@ func (s *Standings) UpdateStandings(match *Match) {
 
 		if match.HomeScore > match.AwayScore {
 			s.Teams[homeTeamIndex].Wins++
-			s.Teams[homeTeamIndex].Points += 3
 		} else if match.HomeScore == match.AwayScore {
 			s.Teams[homeTeamIndex].Draws++
-			s.Teams[homeTeamIndex].Points += 1
 		} else {
 			s.Teams[homeTeamIndex].Losses++
 		}
+
+		points, bonus := rules.result(match.HomeScore, match.AwayScore, shootout)
+		s.Teams[homeTeamIndex].Points += points
+		s.Teams[homeTeamIndex].BonusPoints += bonus
 	}
 
 	// Update away team stats
@@ -64,12 +93,14 @@ func (s *Standings) UpdateStandings(match *Match) {
 
 		if match.AwayScore > match.HomeScore {
 			s.Teams[awayTeamIndex].Wins++
-			s.Teams[awayTeamIndex].Points += 3
 		} else if match.AwayScore == match.HomeScore {
 			s.Teams[awayTeamIndex].Draws++
-			s.Teams[awayTeamIndex].Points += 1
 		} else {
 			s.Teams[awayTeamIndex].Losses++
 		}
+
+		points, bonus := rules.result(match.AwayScore, match.HomeScore, -shootout)
+		s.Teams[awayTeamIndex].Points += points
+		s.Teams[awayTeamIndex].BonusPoints += bonus
 	}
 }
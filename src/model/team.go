@@ -9,6 +9,12 @@ type Team struct {
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
 	Strength int    `json:"strength"` // 1-100 scale representing team's strength
+
+	// SquadStrength derives Strength from the squad, see SquadStrength, once
+	// the squad makes up a full line-up
+	SquadStrength bool `json:"squad_strength"`
+
+	Players []*Player `json:"-"` // Squad, loaded with leagues for the event engine
 }
 
 // Validate checks if the team data is valid
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tie-breaker rules. Head-to-head rules only count the matches between the
+// teams that are still level when the rule is applied.
+const (
+	TieBreakPoints                   = "points"
+	TieBreakGoalDifference           = "goal_difference"
+	TieBreakGoalsFor                 = "goals_for"
+	TieBreakWins                     = "wins"
+	TieBreakHeadToHeadPoints         = "head_to_head_points"
+	TieBreakHeadToHeadGoalDifference = "head_to_head_goal_difference"
+	TieBreakHeadToHeadAwayGoals      = "head_to_head_away_goals"
+	TieBreakFairPlay                 = "fair_play"
+	TieBreakDrawingOfLots            = "drawing_of_lots"
+)
+
+// DefaultTieBreakers is the order tables are sorted in unless a league configures its own
+var DefaultTieBreakers = []string{TieBreakPoints, TieBreakGoalDifference, TieBreakGoalsFor}
+
+var tieBreakers = map[string]bool{
+	TieBreakPoints:                   true,
+	TieBreakGoalDifference:           true,
+	TieBreakGoalsFor:                 true,
+	TieBreakWins:                     true,
+	TieBreakHeadToHeadPoints:         true,
+	TieBreakHeadToHeadGoalDifference: true,
+	TieBreakHeadToHeadAwayGoals:      true,
+	TieBreakFairPlay:                 true,
+	TieBreakDrawingOfLots:            true,
+}
+
+// ResolveTieBreakers validates a tie-breaker chain, returning the default chain when it is empty
+func ResolveTieBreakers(rules []string) ([]string, error) {
+	if len(rules) == 0 {
+		return append([]string(nil), DefaultTieBreakers...), nil
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if !tieBreakers[rule] {
+			return nil, fmt.Errorf("unknown tie-breaker %q", rule)
+		}
+		if seen[rule] {
+			return nil, fmt.Errorf("tie-breaker %q is listed more than once", rule)
+		}
+		seen[rule] = true
+	}
+
+	return append([]string(nil), rules...), nil
+}
+
+// TableOrder holds everything needed to order a table
+type TableOrder struct {
+	TieBreakers []string     // Rules applied in turn, DefaultTieBreakers when empty
+	Matches     []*Match     // Results the table was built from, for head-to-head rules
+	Scoring     ScoringRules // Points of the head-to-head results, DefaultScoringRules when empty
+	FairPlay    map[int]int  // Disciplinary points per team, lower is better; teams without any are level
+	Seed        int64        // Seed of the drawing of lots
+}
+
+// SortBy orders the standings by the given tie-breaker chain. Teams level on
+// every rule are ordered by name.
+func (s *Standings) SortBy(order TableOrder) {
+	rules := order.TieBreakers
+	if len(rules) == 0 {
+		rules = DefaultTieBreakers
+	}
+	if order.Scoring == (ScoringRules{}) {
+		order.Scoring = DefaultScoringRules
+	}
+	order.rank(s.Teams, rules, s.Week)
+}
+
+// SetTieBreakers validates the tie-breaker chain and stores it on the league
+func (l *League) SetTieBreakers(rules []string) error {
+	rules, err := ResolveTieBreakers(rules)
+	if err != nil {
+		return err
+	}
+
+	l.TieBreakers = rules
+	return nil
+}
+
+// SortStandings orders standings built from the given matches by the league's tie-breakers
+func (l *League) SortStandings(standings *Standings, matches []*Match) {
+	standings.SortBy(TableOrder{
+		TieBreakers: l.TieBreakers,
+		Matches:     matches,
+		Scoring:     l.Scoring,
+		FairPlay:    FairPlayPoints(matches, standings.Week),
+		Seed:        l.Seed,
+	})
+}
+
+// rank sorts the teams by the first rule and ranks every group of teams
+// still level on it by the remaining rules
+func (o TableOrder) rank(teams []TeamStanding, rules []string, week int) {
+	if len(teams) < 2 {
+		return
+	}
+
+	if len(rules) == 0 {
+		sort.SliceStable(teams, func(i, j int) bool {
+			return teams[i].TeamName < teams[j].TeamName
+		})
+		return
+	}
+
+	keys := o.keys(rules[0], teams, week)
+	sort.SliceStable(teams, func(i, j int) bool {
+		return keys[teams[i].TeamID] > keys[teams[j].TeamID]
+	})
+
+	for start := 0; start < len(teams); {
+		end := start + 1
+		for end < len(teams) && keys[teams[end].TeamID] == keys[teams[start].TeamID] {
+			end++
+		}
+		o.rank(teams[start:end], rules[1:], week)
+		start = end
+	}
+}
+
+// keys returns the value of a rule for every team, higher ranks first
+func (o TableOrder) keys(rule string, teams []TeamStanding, week int) map[int]int64 {
+	keys := make(map[int]int64, len(teams))
+
+	switch rule {
+	case TieBreakHeadToHeadPoints, TieBreakHeadToHeadGoalDifference, TieBreakHeadToHeadAwayGoals:
+		tied := make(map[int]bool, len(teams))
+		for _, team := range teams {
+			tied[team.TeamID] = true
+		}
+
+		// Mini table of the matches between the tied teams
+		table := Standings{Teams: make([]TeamStanding, len(teams))}
+		for i, team := range teams {
+			table.Teams[i] = TeamStanding{TeamID: team.TeamID}
+		}
+		awayGoals := make(map[int]int, len(teams))
+		for _, match := range o.Matches {
+			if !match.Played || match.Week > week || !tied[match.HomeTeamID] || !tied[match.AwayTeamID] {
+				continue
+			}
+			table.UpdateStandings(match, o.Scoring)
+			awayGoals[match.AwayTeamID] += match.AwayScore
+		}
+
+		for _, standing := range table.Teams {
+			switch rule {
+			case TieBreakHeadToHeadPoints:
+				keys[standing.TeamID] = int64(standing.Points)
+			case TieBreakHeadToHeadGoalDifference:
+				keys[standing.TeamID] = int64(standing.GoalDifference)
+			default:
+				keys[standing.TeamID] = int64(awayGoals[standing.TeamID])
+			}
+		}
+
+	default:
+		for _, team := range teams {
+			switch rule {
+			case TieBreakPoints:
+				keys[team.TeamID] = int64(team.Points)
+			case TieBreakGoalDifference:
+				keys[team.TeamID] = int64(team.GoalDifference)
+			case TieBreakGoalsFor:
+				keys[team.TeamID] = int64(team.GoalsFor)
+			case TieBreakWins:
+				keys[team.TeamID] = int64(team.Wins)
+			case TieBreakFairPlay:
+				keys[team.TeamID] = -int64(o.FairPlay[team.TeamID])
+			case TieBreakDrawingOfLots:
+				keys[team.TeamID] = DeriveSeed(o.Seed, seedStreamDrawingOfLots, int64(team.TeamID))
+			}
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSortByTieBreakers(t *testing.T) {
+	// Teams 1, 2 and 3 finish on 5 points, team 1 best between them and
+	// team 2 best on goal difference
+	level := [][4]int{
+		{1, 2, 2, 0}, {2, 3, 1, 1}, {3, 1, 0, 0},
+		{1, 4, 1, 1}, {1, 5, 0, 3},
+		{2, 4, 4, 0}, {2, 5, 2, 2},
+		{3, 4, 3, 0}, {3, 5, 0, 1},
+		{4, 5, 0, 0},
+	}
+	// Team 1 beats both others, who draw 1-1 at team 2
+	mini := [][4]int{{1, 2, 1, 0}, {3, 1, 0, 4}, {2, 3, 1, 1}}
+
+	tests := []struct {
+		name    string
+		teams   int
+		results [][4]int // Home team, away team and their goals
+		rules   []string
+		want    []int
+	}{
+		{name: "default", teams: 5, results: level, want: []int{5, 2, 3, 1, 4}},
+		{
+			// 4, 2 and 1 points from the matches between the three
+			name:    "head-to-head points",
+			teams:   5,
+			results: level,
+			rules:   []string{TieBreakPoints, TieBreakHeadToHeadPoints, TieBreakGoalDifference},
+			want:    []int{5, 1, 3, 2, 4},
+		},
+		{
+			// Teams 2 and 3 are level on their own match, team 3 scoring away
+			name:    "head-to-head sub-table",
+			teams:   3,
+			results: mini,
+			rules:   []string{TieBreakHeadToHeadPoints, TieBreakHeadToHeadGoalDifference, TieBreakHeadToHeadAwayGoals},
+			want:    []int{1, 3, 2},
+		},
+		{
+			// Between the three, team 2 loses by one goal and team 3 by four
+			name:    "goal difference after head-to-head",
+			teams:   3,
+			results: mini,
+			rules:   []string{TieBreakHeadToHeadPoints, TieBreakGoalDifference},
+			want:    []int{1, 2, 3},
+		},
+		{
+			// Teams 2 and 3 are ordered by name
+			name:    "level on every rule",
+			teams:   3,
+			results: mini,
+			rules:   []string{TieBreakHeadToHeadPoints, TieBreakHeadToHeadGoalDifference},
+			want:    []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standings := Standings{Week: 1}
+			for _, team := range testTeams(tt.teams) {
+				standings.Teams = append(standings.Teams, TeamStanding{TeamID: team.ID, TeamName: team.Name})
+			}
+			matches := make([]*Match, len(tt.results))
+			for i, result := range tt.results {
+				matches[i] = &Match{HomeTeamID: result[0], AwayTeamID: result[1], HomeScore: result[2], AwayScore: result[3], Week: 1, Played: true}
+				standings.UpdateStandings(matches[i], DefaultScoringRules)
+			}
+
+			standings.SortBy(TableOrder{TieBreakers: tt.rules, Matches: matches})
+
+			got := make([]int, len(standings.Teams))
+			for i, team := range standings.Teams {
+				got[i] = team.TeamID
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveTieBreakers(t *testing.T) {
+	rules, err := ResolveTieBreakers(nil)
+	if err != nil || fmt.Sprint(rules) != fmt.Sprint(DefaultTieBreakers) {
+		t.Errorf("expected the default tie-breakers, got %v, %v", rules, err)
+	}
+
+	if _, err := ResolveTieBreakers([]string{TieBreakPoints, "coin_toss"}); err == nil {
+		t.Error("expected an error for an unknown tie-breaker")
+	}
+	if _, err := ResolveTieBreakers([]string{TieBreakPoints, TieBreakWins, TieBreakPoints}); err == nil {
+		t.Error("expected an error for a repeated tie-breaker")
+	}
+}
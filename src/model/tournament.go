@@ -0,0 +1,290 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Phases of a tournament
+const (
+	PhaseGroupStage = "group_stage"
+	PhaseKnockout   = "knockout"
+	PhaseFinished   = "finished"
+)
+
+// TournamentOptions holds the settings a tournament is created with
+type TournamentOptions struct {
+	TeamIDs         []int        // Teams taking part, all teams when empty
+	Groups          int          // Number of groups
+	AdvancePerGroup int          // Teams of every group advancing to the knockout stage
+	GroupRounds     int          // Round robins played within a group, 1 by default
+	TwoLegged       bool         // Knockout ties before the final are played home and away
+	TwoLeggedFinal  bool         // The final is played home and away
+	Engine          EngineConfig // Match engine of all phases, linear when empty
+	TieBreakers     []string     // Order of the group tables, DefaultTieBreakers when empty
+	Seed            *int64       // Tournament seed, random when nil
+}
+
+// Tournament is a group stage of round-robin leagues followed by a knockout cup
+// between the best teams of every group
+type Tournament struct {
+	ID              int          `json:"id"`
+	Name            string       `json:"name"`
+	GroupCount      int          `json:"group_count"`
+	AdvancePerGroup int          `json:"advance_per_group"`
+	GroupRounds     int          `json:"group_rounds"`
+	TwoLegged       bool         `json:"two_legged"`
+	TwoLeggedFinal  bool         `json:"two_legged_final"`
+	Engine          EngineConfig `json:"engine"`
+	Seed            int64        `json:"seed"`
+	Groups          []*League    `json:"groups,omitempty"`
+	Knockout        *Cup         `json:"knockout,omitempty"` // Created once every group has finished
+}
+
+// TournamentGroup is a group of a tournament with its sorted table
+type TournamentGroup struct {
+	LeagueID    int        `json:"league_id"`
+	Name        string     `json:"name"`
+	CurrentWeek int        `json:"current_week"`
+	TotalWeeks  int        `json:"total_weeks"`
+	Standings   *Standings `json:"standings"`
+	Qualified   []int      `json:"qualified"` // Teams advancing, known once the group has finished
+}
+
+// TournamentView combines the group tables and the knockout bracket of a tournament
+type TournamentView struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Phase           string             `json:"phase"`
+	AdvancePerGroup int                `json:"advance_per_group"`
+	Groups          []*TournamentGroup `json:"groups"`
+	Knockout        *CupBracket        `json:"knockout,omitempty"`
+	Champion        *Team              `json:"champion,omitempty"`
+}
+
+// NewTournament draws the teams into groups and creates a league for every group.
+// Teams are split into pots by strength, one pot per group size, and every group
+// receives one team of every full pot, drawn at random with the tournament seed.
+// The teams of a short last pot go to groups drawn at random as well.
+func NewTournament(name string, teams []*Team, opts TournamentOptions, seed int64) (*Tournament, error) {
+	if name == "" {
+		return nil, errors.New("tournament name cannot be empty")
+	}
+
+	if opts.Groups < 1 || opts.Groups > 26 {
+		return nil, errors.New("a tournament must have between 1 and 26 groups")
+	}
+
+	if len(teams) < 2*opts.Groups {
+		return nil, fmt.Errorf("%d groups need at least %d teams", opts.Groups, 2*opts.Groups)
+	}
+
+	// The smallest group decides how many teams can advance
+	smallestGroup := len(teams) / opts.Groups
+	if opts.AdvancePerGroup < 1 || opts.AdvancePerGroup > smallestGroup {
+		return nil, fmt.Errorf("between 1 and %d teams per group can advance", smallestGroup)
+	}
+
+	if opts.Groups*opts.AdvancePerGroup < 2 {
+		return nil, errors.New("at least 2 teams must advance to the knockout stage")
+	}
+
+	groupRounds := opts.GroupRounds
+	if groupRounds == 0 {
+		groupRounds = SingleRoundRobin
+	}
+
+	tournament := &Tournament{
+		Name:            name,
+		GroupCount:      opts.Groups,
+		AdvancePerGroup: opts.AdvancePerGroup,
+		GroupRounds:     groupRounds,
+		TwoLegged:       opts.TwoLegged,
+		TwoLeggedFinal:  opts.TwoLeggedFinal,
+		Seed:            seed,
+	}
+
+	simulator, err := NewMatchSimulator(opts.Engine)
+	if err != nil {
+		return nil, err
+	}
+	tournament.Engine = EngineConfig{Name: opts.Engine.Name, Params: simulator.Params()}
+	if tournament.Engine.Name == "" {
+		tournament.Engine.Name = EngineLinear
+	}
+
+	// Draw the groups from pots of decreasing strength
+	pots := make([]*Team, len(teams))
+	copy(pots, teams)
+	sort.SliceStable(pots, func(i, j int) bool {
+		if pots[i].Strength != pots[j].Strength {
+			return pots[i].Strength > pots[j].Strength
+		}
+		return pots[i].ID < pots[j].ID
+	})
+
+	rng := rand.New(rand.NewSource(DeriveSeed(seed, seedStreamTournamentDraw)))
+	groupTeams := make([][]*Team, opts.Groups)
+	for start := 0; start < len(pots); start += opts.Groups {
+		pot := pots[start:min(start+opts.Groups, len(pots))]
+		rng.Shuffle(len(pot), func(i, j int) {
+			pot[i], pot[j] = pot[j], pot[i]
+		})
+
+		// A short last pot goes to groups drawn at random
+		groups := make([]int, opts.Groups)
+		for i := range groups {
+			groups[i] = i
+		}
+		if len(pot) < opts.Groups {
+			groups = rng.Perm(opts.Groups)
+		}
+
+		for i, team := range pot {
+			groupTeams[groups[i]] = append(groupTeams[groups[i]], team)
+		}
+	}
+
+	for i, teams := range groupTeams {
+		group, err := NewLeague(fmt.Sprintf("%s - Group %c", name, 'A'+i), teams, groupRounds)
+		if err != nil {
+			return nil, err
+		}
+		if err := group.SetEngine(opts.Engine); err != nil {
+			return nil, err
+		}
+		if err := group.SetTieBreakers(opts.TieBreakers); err != nil {
+			return nil, err
+		}
+		group.Seed = DeriveSeed(seed, seedStreamTournamentGroup, int64(i))
+		tournament.Groups = append(tournament.Groups, group)
+	}
+
+	return tournament, nil
+}
+
+// Phase returns the current phase of the tournament
+func (t *Tournament) Phase() string {
+	if t.Knockout == nil {
+		return PhaseGroupStage
+	}
+	if t.Knockout.ChampionID == nil {
+		return PhaseKnockout
+	}
+	return PhaseFinished
+}
+
+// GroupStageFinished reports whether every group has played all of its weeks
+func (t *Tournament) GroupStageFinished() bool {
+	for _, group := range t.Groups {
+		if group.CurrentWeek < group.TotalWeeks {
+			return false
+		}
+	}
+	return true
+}
+
+// Qualifiers returns the teams advancing from the groups in seeding order:
+// every group winner in group order, then every runner-up, and so on.
+// Teams of the same group therefore never meet in the first knockout round
+// as long as the bracket is full and the number of groups is even.
+func (t *Tournament) Qualifiers() ([]*Team, error) {
+	if !t.GroupStageFinished() {
+		return nil, errors.New("the group stage has not finished yet")
+	}
+
+	qualifiers := make([]*Team, 0, t.GroupCount*t.AdvancePerGroup)
+	for position := 0; position < t.AdvancePerGroup; position++ {
+		for _, group := range t.Groups {
+			standings := groupTable(group)
+			for _, team := range group.Teams {
+				if team.ID == standings.Teams[position].TeamID {
+					qualifiers = append(qualifiers, team)
+				}
+			}
+		}
+	}
+
+	return qualifiers, nil
+}
+
+// NewKnockout creates and draws the knockout cup between the qualifiers of the groups
+func (t *Tournament) NewKnockout() (*Cup, error) {
+	if t.Knockout != nil {
+		return nil, errors.New("the knockout stage has already been created")
+	}
+
+	qualifiers, err := t.Qualifiers()
+	if err != nil {
+		return nil, err
+	}
+
+	cup, err := NewCup(t.Name+" - Knockout Stage", qualifiers, CupOptions{
+		TwoLegged:      t.TwoLegged,
+		TwoLeggedFinal: t.TwoLeggedFinal,
+		Draw:           DrawSeeded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cup.SetEngine(t.Engine); err != nil {
+		return nil, err
+	}
+	cup.Seed = DeriveSeed(t.Seed, seedStreamTournamentKnockout)
+	cup.TournamentID = &t.ID
+
+	if err := cup.DrawInOrder(qualifiers); err != nil {
+		return nil, err
+	}
+
+	return cup, nil
+}
+
+// View returns the group tables and the knockout bracket of the tournament
+func (t *Tournament) View() *TournamentView {
+	view := &TournamentView{
+		ID:              t.ID,
+		Name:            t.Name,
+		Phase:           t.Phase(),
+		AdvancePerGroup: t.AdvancePerGroup,
+		Groups:          make([]*TournamentGroup, 0, len(t.Groups)),
+	}
+
+	for _, group := range t.Groups {
+		standings := groupTable(group)
+		tournamentGroup := &TournamentGroup{
+			LeagueID:    group.ID,
+			Name:        group.Name,
+			CurrentWeek: group.CurrentWeek,
+			TotalWeeks:  group.TotalWeeks,
+			Standings:   &standings,
+			Qualified:   make([]int, 0, t.AdvancePerGroup),
+		}
+
+		if group.CurrentWeek >= group.TotalWeeks {
+			for _, standing := range standings.Teams[:t.AdvancePerGroup] {
+				tournamentGroup.Qualified = append(tournamentGroup.Qualified, standing.TeamID)
+			}
+		}
+
+		view.Groups = append(view.Groups, tournamentGroup)
+	}
+
+	if t.Knockout != nil {
+		view.Knockout = t.Knockout.Bracket()
+		view.Champion = view.Knockout.Champion
+	}
+
+	return view
+}
+
+// groupTable returns a sorted copy of the standings of a group
+func groupTable(group *League) Standings {
+	standings := group.Standings
+	standings.Teams = append([]TeamStanding(nil), group.Standings.Teams...)
+	group.SortStandings(&standings, group.Matches)
+	return standings
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewTournamentGroupDraw(t *testing.T) {
+	tests := []struct {
+		teams  int
+		groups int
+	}{
+		{teams: 8, groups: 2},
+		{teams: 16, groups: 4},
+		// A short last pot of two teams for three groups
+		{teams: 11, groups: 3},
+		{teams: 13, groups: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d teams %d groups", tt.teams, tt.groups), func(t *testing.T) {
+			tournament, err := NewTournament("Draw Tournament", testTeams(tt.teams), TournamentOptions{Groups: tt.groups, AdvancePerGroup: 1}, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tournament.Groups) != tt.groups {
+				t.Fatalf("expected %d groups, got %d", tt.groups, len(tournament.Groups))
+			}
+
+			// Pots are filled strongest first, and testTeams makes the last team the strongest
+			pot := func(team *Team) int {
+				return (tt.teams - team.ID) / tt.groups
+			}
+
+			drawn := make(map[int]int)
+			for i, group := range tournament.Groups {
+				if want := fmt.Sprintf("Draw Tournament - Group %c", 'A'+i); group.Name != want {
+					t.Errorf("expected group %q, got %q", want, group.Name)
+				}
+
+				size := len(group.Teams)
+				if size != tt.teams/tt.groups && size != tt.teams/tt.groups+1 {
+					t.Errorf("group %d: unexpected size %d", i, size)
+				}
+
+				pots := make(map[int]bool)
+				for _, team := range group.Teams {
+					drawn[team.ID]++
+					if pots[pot(team)] {
+						t.Errorf("group %d: two teams of pot %d", i, pot(team))
+					}
+					pots[pot(team)] = true
+				}
+			}
+
+			for _, team := range testTeams(tt.teams) {
+				if drawn[team.ID] != 1 {
+					t.Errorf("team %d: drawn into %d groups", team.ID, drawn[team.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestNewTournamentSeededDraw(t *testing.T) {
+	draw := func(seed int64) string {
+		tournament, err := NewTournament("Seeded Tournament", testTeams(12), TournamentOptions{Groups: 3, AdvancePerGroup: 2}, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups := make([][]int, 0, len(tournament.Groups))
+		for _, group := range tournament.Groups {
+			ids := make([]int, 0, len(group.Teams))
+			for _, team := range group.Teams {
+				ids = append(ids, team.ID)
+			}
+			groups = append(groups, ids)
+		}
+		return fmt.Sprint(groups)
+	}
+
+	if first, second := draw(11), draw(11); first != second {
+		t.Errorf("expected the same seed to draw the same groups, got %s and %s", first, second)
+	}
+
+	differs := false
+	for seed := int64(12); seed <= 20 && !differs; seed++ {
+		differs = draw(seed) != draw(11)
+	}
+	if !differs {
+		t.Error("expected other seeds to draw other groups")
+	}
+}
+
+func TestNewTournamentErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		teams int
+		opts  TournamentOptions
+	}{
+		{name: "no groups", teams: 8, opts: TournamentOptions{AdvancePerGroup: 1}},
+		{name: "too many groups", teams: 60, opts: TournamentOptions{Groups: 27, AdvancePerGroup: 1}},
+		{name: "too few teams", teams: 5, opts: TournamentOptions{Groups: 3, AdvancePerGroup: 1}},
+		{name: "more advancing than the smallest group", teams: 7, opts: TournamentOptions{Groups: 2, AdvancePerGroup: 4}},
+		{name: "a single team advancing", teams: 4, opts: TournamentOptions{Groups: 1, AdvancePerGroup: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTournament("Broken Tournament", testTeams(tt.teams), tt.opts, 1); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestTournamentKnockoutKeepsGroupsApart(t *testing.T) {
+	tournament, err := NewTournament("Knockout Tournament", testTeams(16), TournamentOptions{Groups: 4, AdvancePerGroup: 2}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tournament.Qualifiers(); err == nil {
+		t.Error("expected an error before the group stage has finished")
+	}
+
+	group := make(map[int]int)
+	for i, league := range tournament.Groups {
+		for _, team := range league.Teams {
+			group[team.ID] = i
+		}
+		for week := 1; week <= league.TotalWeeks; week++ {
+			if err := league.SimulateWeek(int64(week)); err != nil {
+				t.Fatalf("group %d week %d: %v", i, week, err)
+			}
+		}
+	}
+
+	// Group winners in group order, then the runners-up
+	qualifiers, err := tournament.Qualifiers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, team := range qualifiers {
+		standings := groupTable(tournament.Groups[i%4])
+		if want := standings.Teams[i/4].TeamID; team.ID != want {
+			t.Errorf("seed %d: expected team %d, got %d", i+1, want, team.ID)
+		}
+	}
+
+	cup, err := tournament.NewKnockout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tie := range cup.RoundTies(1) {
+		if group[*tie.HomeTeamID] == group[*tie.AwayTeamID] {
+			t.Errorf("tie %d: teams %d and %d of the same group meet", tie.Slot, *tie.HomeTeamID, *tie.AwayTeamID)
+		}
+	}
+}
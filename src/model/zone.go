@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ResolveZones validates the zones of a league table with the given number
+// of teams. Zones may overlap, e.g. the title within the Champions League
+// places. The number of teams is not checked when it is zero.
+func ResolveZones(zones []PositionBand, teams int) ([]PositionBand, error) {
+	seen := make(map[string]bool, len(zones))
+	for _, zone := range zones {
+		if zone.Name == "" {
+			return nil, errors.New("every zone needs a name")
+		}
+		if seen[zone.Name] {
+			return nil, fmt.Errorf("zone %q is listed more than once", zone.Name)
+		}
+		seen[zone.Name] = true
+
+		if zone.From < 1 || zone.To < zone.From {
+			return nil, fmt.Errorf("zone %q must cover positions from 1 upwards, with from at most to", zone.Name)
+		}
+		if teams > 0 && zone.To > teams {
+			return nil, fmt.Errorf("zone %q ends below the last of the %d positions", zone.Name, teams)
+		}
+	}
+
+	return append([]PositionBand(nil), zones...), nil
+}
+
+// SetZones validates the zones and stores them on the league. No zones
+// leaves the league with DefaultPositionBands.
+func (l *League) SetZones(zones []PositionBand) error {
+	zones, err := ResolveZones(zones, len(l.Teams))
+	if err != nil {
+		return err
+	}
+
+	l.Zones = zones
+	return nil
+}
+
+// PositionZones returns the zones of the league table, DefaultPositionBands
+// when the league has none of its own
+func (l *League) PositionZones() []PositionBand {
+	if len(l.Zones) == 0 {
+		return DefaultPositionBands(len(l.Teams))
+	}
+	return l.Zones
+}
+
+// SummaryZones returns the zones the top three and relegation probabilities
+// of predictions are about: the widest zone starting at the top of the table
+// and the widest ending at its bottom, neither covering the whole table.
+// Leagues without zones of their own keep the top three and the last place,
+// and a league whose zones have none at the top or the bottom gets nil for it.
+func (l *League) SummaryZones() (top, bottom *PositionBand) {
+	teams := len(l.Teams)
+	if len(l.Zones) == 0 {
+		return &PositionBand{Name: "top_three", From: 1, To: min(3, teams)},
+			&PositionBand{Name: "relegation", From: teams, To: teams}
+	}
+
+	for i := range l.Zones {
+		zone := &l.Zones[i]
+		if zone.From == 1 && zone.To < teams && (top == nil || zone.To > top.To) {
+			top = zone
+		}
+		if zone.To == teams && zone.From > 1 && (bottom == nil || zone.From < bottom.From) {
+			bottom = zone
+		}
+	}
+	return top, bottom
+}
+
+// AnnotateZones marks every row of sorted standings with the zones its position falls in
+func (l *League) AnnotateZones(standings *Standings) {
+	zones := l.PositionZones()
+	for i := range standings.Teams {
+		standings.Teams[i].Zones = nil
+		for _, zone := range zones {
+			if i+1 >= zone.From && i+1 <= zone.To {
+				standings.Teams[i].Zones = append(standings.Teams[i].Zones, zone.Name)
+			}
+		}
+	}
+}
+
+// ZoneProbability is the chance of a team finishing within a zone, in percent
+type ZoneProbability struct {
+	Zone        string  `json:"zone"`
+	Probability float64 `json:"probability"`
+	StdError    float64 `json:"standard_error"` // Monte Carlo standard error, in percentage points
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresCupRepository implements the CupRepository interface
+type PostgresCupRepository struct {
+	db DBTX
+}
+
+// NewPostgresCupRepository creates a new PostgresCupRepository
+func NewPostgresCupRepository(db DBTX) *PostgresCupRepository {
+	return &PostgresCupRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new cup and its entries into the database
+func (r *PostgresCupRepository) Create(ctx context.Context, cup *model.Cup) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return createCup(ctx, tx, cup)
+	})
+}
+
+// createCup inserts a cup with its entries and ties within the given transaction
+func createCup(ctx context.Context, tx *sql.Tx, cup *model.Cup) error {
+	engineParams, err := json.Marshal(cup.Engine.Params)
+	if err != nil {
+		return err
+	}
+
+	// Insert cup
+	cupQuery := `
+		INSERT INTO cups (name, current_round, total_rounds, two_legged, two_legged_final, draw, engine, engine_params, seed, tournament_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		cupQuery,
+		cup.Name,
+		cup.CurrentRound,
+		cup.TotalRounds,
+		cup.TwoLegged,
+		cup.TwoLeggedFinal,
+		cup.Draw,
+		cup.Engine.Name,
+		engineParams,
+		cup.Seed,
+		cup.TournamentID,
+	).Scan(&cup.ID)
+	if err != nil {
+		return err
+	}
+
+	// Insert cup entries
+	teamQuery := `
+		INSERT INTO cup_teams (cup_id, team_id)
+		VALUES ($1, $2)
+	`
+	for _, team := range cup.Teams {
+		if _, err := tx.ExecContext(ctx, teamQuery, cup.ID, team.ID); err != nil {
+			return err
+		}
+	}
+
+	// Insert the ties of a cup drawn before it was stored
+	return saveCupTies(ctx, tx, cup)
+}
+
+// GetByID retrieves a cup with its teams, ties and legs
+func (r *PostgresCupRepository) GetByID(ctx context.Context, id int) (*model.Cup, error) {
+	// Get cup info
+	cupQuery := `
+		SELECT id, name, current_round, total_rounds, two_legged, two_legged_final, draw, engine, engine_params, seed, champion_id, tournament_id
+		FROM cups
+		WHERE id = $1
+	`
+	cup := &model.Cup{}
+	var engineParams []byte
+	var championID, tournamentID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, cupQuery, id).Scan(
+		&cup.ID,
+		&cup.Name,
+		&cup.CurrentRound,
+		&cup.TotalRounds,
+		&cup.TwoLegged,
+		&cup.TwoLeggedFinal,
+		&cup.Draw,
+		&cup.Engine.Name,
+		&engineParams,
+		&cup.Seed,
+		&championID,
+		&tournamentID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("cup not found")
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(engineParams, &cup.Engine.Params); err != nil {
+		return nil, err
+	}
+	cup.ChampionID = nullIntPtr(championID)
+	cup.TournamentID = nullIntPtr(tournamentID)
+
+	// Get teams
+	teamsQuery := `
+		SELECT t.id, t.name, t.strength
+		FROM teams t
+		JOIN cup_teams ct ON ct.team_id = t.id
+		WHERE ct.cup_id = $1
+		ORDER BY t.id
+	`
+	teamRows, err := r.db.QueryContext(ctx, teamsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer teamRows.Close()
+
+	for teamRows.Next() {
+		team := &model.Team{}
+		if err := teamRows.Scan(&team.ID, &team.Name, &team.Strength); err != nil {
+			return nil, err
+		}
+		cup.Teams = append(cup.Teams, team)
+	}
+	if err := teamRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get ties
+	tiesQuery := `
+		SELECT id, round, slot, home_team_id, away_team_id, bye, home_penalties, away_penalties, winner_id
+		FROM cup_ties
+		WHERE cup_id = $1
+		ORDER BY round, slot
+	`
+	tieRows, err := r.db.QueryContext(ctx, tiesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer tieRows.Close()
+
+	ties := make(map[int]*model.CupTie)
+	for tieRows.Next() {
+		tie := &model.CupTie{Legs: make([]*model.CupLeg, 0)}
+		var homeTeamID, awayTeamID, homePenalties, awayPenalties, winnerID sql.NullInt64
+		if err := tieRows.Scan(
+			&tie.ID,
+			&tie.Round,
+			&tie.Slot,
+			&homeTeamID,
+			&awayTeamID,
+			&tie.Bye,
+			&homePenalties,
+			&awayPenalties,
+			&winnerID,
+		); err != nil {
+			return nil, err
+		}
+		tie.HomeTeamID = nullIntPtr(homeTeamID)
+		tie.AwayTeamID = nullIntPtr(awayTeamID)
+		tie.HomePenalties = nullIntPtr(homePenalties)
+		tie.AwayPenalties = nullIntPtr(awayPenalties)
+		tie.WinnerID = nullIntPtr(winnerID)
+
+		cup.Ties = append(cup.Ties, tie)
+		ties[tie.ID] = tie
+	}
+	if err := tieRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get legs
+	legsQuery := `
+		SELECT l.tie_id, l.leg, l.home_team_id, l.away_team_id, l.home_score, l.away_score, l.extra_time, l.played_at
+		FROM cup_legs l
+		JOIN cup_ties ct ON l.tie_id = ct.id
+		WHERE ct.cup_id = $1
+		ORDER BY l.tie_id, l.leg
+	`
+	legRows, err := r.db.QueryContext(ctx, legsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer legRows.Close()
+
+	for legRows.Next() {
+		leg := &model.CupLeg{}
+		var tieID int
+		var playedAt sql.NullTime
+		if err := legRows.Scan(
+			&tieID,
+			&leg.Leg,
+			&leg.HomeTeamID,
+			&leg.AwayTeamID,
+			&leg.HomeScore,
+			&leg.AwayScore,
+			&leg.ExtraTime,
+			&playedAt,
+		); err != nil {
+			return nil, err
+		}
+		if playedAt.Valid {
+			leg.PlayedAt = playedAt.Time
+		}
+		if tie, ok := ties[tieID]; ok {
+			tie.Legs = append(tie.Legs, leg)
+		}
+	}
+	if err := legRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cup, nil
+}
+
+// GetAll retrieves all cups without their teams and ties
+func (r *PostgresCupRepository) GetAll(ctx context.Context) ([]*model.Cup, error) {
+	query := `
+		SELECT id, name, current_round, total_rounds, two_legged, two_legged_final, draw, engine, engine_params, seed, champion_id, tournament_id
+		FROM cups
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cups []*model.Cup
+	for rows.Next() {
+		cup := &model.Cup{}
+		var engineParams []byte
+		var championID, tournamentID sql.NullInt64
+		if err := rows.Scan(
+			&cup.ID,
+			&cup.Name,
+			&cup.CurrentRound,
+			&cup.TotalRounds,
+			&cup.TwoLegged,
+			&cup.TwoLeggedFinal,
+			&cup.Draw,
+			&cup.Engine.Name,
+			&engineParams,
+			&cup.Seed,
+			&championID,
+			&tournamentID,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(engineParams, &cup.Engine.Params); err != nil {
+			return nil, err
+		}
+		cup.ChampionID = nullIntPtr(championID)
+		cup.TournamentID = nullIntPtr(tournamentID)
+		cups = append(cups, cup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cups, nil
+}
+
+// Update stores the progress of a cup: its current round, champion, ties and legs
+func (r *PostgresCupRepository) Update(ctx context.Context, cup *model.Cup) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return updateCup(ctx, tx, cup)
+	})
+}
+
+// updateCup stores the progress of a cup within the given transaction
+func updateCup(ctx context.Context, tx *sql.Tx, cup *model.Cup) error {
+	cupQuery := `
+		UPDATE cups
+		SET current_round = $1, champion_id = $2
+		WHERE id = $3
+	`
+	result, err := tx.ExecContext(ctx, cupQuery, cup.CurrentRound, cup.ChampionID, cup.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("cup not found")
+	}
+
+	return saveCupTies(ctx, tx, cup)
+}
+
+// saveCupTies inserts or updates the ties of a cup and inserts their new legs
+func saveCupTies(ctx context.Context, tx *sql.Tx, cup *model.Cup) error {
+	// Insert or update the ties
+	tieQuery := `
+		INSERT INTO cup_ties (
+			cup_id, round, slot, home_team_id, away_team_id, bye, home_penalties, away_penalties, winner_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		ON CONFLICT (cup_id, round, slot) DO UPDATE SET
+			home_team_id = $4, away_team_id = $5, bye = $6,
+			home_penalties = $7, away_penalties = $8, winner_id = $9
+		RETURNING id
+	`
+
+	// Insert the legs, played legs never change
+	legQuery := `
+		INSERT INTO cup_legs (tie_id, leg, home_team_id, away_team_id, home_score, away_score, extra_time, played_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tie_id, leg) DO NOTHING
+	`
+
+	for _, tie := range cup.Ties {
+		err := tx.QueryRowContext(
+			ctx,
+			tieQuery,
+			cup.ID,
+			tie.Round,
+			tie.Slot,
+			tie.HomeTeamID,
+			tie.AwayTeamID,
+			tie.Bye,
+			tie.HomePenalties,
+			tie.AwayPenalties,
+			tie.WinnerID,
+		).Scan(&tie.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, leg := range tie.Legs {
+			_, err := tx.ExecContext(
+				ctx,
+				legQuery,
+				tie.ID,
+				leg.Leg,
+				leg.HomeTeamID,
+				leg.AwayTeamID,
+				leg.HomeScore,
+				leg.AwayScore,
+				leg.ExtraTime,
+				leg.PlayedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// nullIntPtr converts a nullable integer column into an optional int
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	i := int(value.Int64)
+	return &i
+}
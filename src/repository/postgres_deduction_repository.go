@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresDeductionRepository implements the DeductionRepository interface
+type PostgresDeductionRepository struct {
+	db DBTX
+}
+
+// NewPostgresDeductionRepository creates a new PostgresDeductionRepository
+func NewPostgresDeductionRepository(db DBTX) *PostgresDeductionRepository {
+	return &PostgresDeductionRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new point deduction
+func (r *PostgresDeductionRepository) Create(ctx context.Context, deduction *model.PointDeduction) error {
+	query := `
+		INSERT INTO point_deductions (league_id, team_id, points, reason, week)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		deduction.LeagueID,
+		deduction.TeamID,
+		deduction.Points,
+		deduction.Reason,
+		deduction.Week,
+	).Scan(&deduction.ID, &deduction.CreatedAt)
+}
+
+// GetByLeagueID retrieves the point deductions of a league in the order they were made
+func (r *PostgresDeductionRepository) GetByLeagueID(ctx context.Context, leagueID int) ([]*model.PointDeduction, error) {
+	query := `
+		SELECT d.id, d.league_id, d.team_id, t.name, d.points, d.reason, d.week, d.created_at
+		FROM point_deductions d
+		JOIN teams t ON d.team_id = t.id
+		WHERE d.league_id = $1
+		ORDER BY d.week, d.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deductions := make([]*model.PointDeduction, 0)
+	for rows.Next() {
+		deduction := &model.PointDeduction{}
+		if err := rows.Scan(
+			&deduction.ID,
+			&deduction.LeagueID,
+			&deduction.TeamID,
+			&deduction.TeamName,
+			&deduction.Points,
+			&deduction.Reason,
+			&deduction.Week,
+			&deduction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deductions = append(deductions, deduction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deductions, nil
+}
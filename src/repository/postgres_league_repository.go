@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/user/league-simulator/src/model"
@@ -10,11 +11,11 @@ import (
 
 // PostgresLeagueRepository implements the LeagueRepository interface
 type PostgresLeagueRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPostgresLeagueRepository creates a new PostgresLeagueRepository
-func NewPostgresLeagueRepository(db *sql.DB) *PostgresLeagueRepository {
+func NewPostgresLeagueRepository(db DBTX) *PostgresLeagueRepository {
 	return &PostgresLeagueRepository{
 		db: db,
 	}
@@ -22,17 +23,53 @@ func NewPostgresLeagueRepository(db *sql.DB) *PostgresLeagueRepository {
 
 // Create inserts a new league into the database
 func (r *PostgresLeagueRepository) Create(ctx context.Context, league *model.League) error {
-	// Begin transaction
-	tx, err := r.db.BeginTx(ctx, nil)
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return createLeague(ctx, tx, league)
+	})
+}
+
+// createLeague inserts a league with its teams, schedule, byes and initial
+// standings and ratings within the given transaction
+func createLeague(ctx context.Context, tx *sql.Tx, league *model.League) error {
+	engineParams, err := json.Marshal(league.Engine.Params)
+	if err != nil {
+		return err
+	}
+
+	engineModifiers, err := json.Marshal(league.Engine.Modifiers)
+	if err != nil {
+		return err
+	}
+
+	ratingConfig, err := json.Marshal(league.Rating)
+	if err != nil {
+		return err
+	}
+
+	tieBreakers, err := json.Marshal(league.TieBreakers)
+	if err != nil {
+		return err
+	}
+
+	scoring, err := json.Marshal(league.Scoring)
+	if err != nil {
+		return err
+	}
+
+	zones, err := marshalZones(league.Zones)
+	if err != nil {
+		return err
+	}
+
+	discipline, err := json.Marshal(league.Discipline)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
 	// Insert league
 	leagueQuery := `
-		INSERT INTO leagues (name, current_week, total_weeks)
-		VALUES ($1, $2, $3)
+		INSERT INTO leagues (name, current_week, total_weeks, rounds, engine, engine_params, engine_modifiers, rating_config, tie_breakers, scoring, discipline, zones, seed, tournament_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 	err = tx.QueryRowContext(
@@ -41,22 +78,46 @@ func (r *PostgresLeagueRepository) Create(ctx context.Context, league *model.Lea
 		league.Name,
 		league.CurrentWeek,
 		league.TotalWeeks,
+		league.Rounds,
+		league.Engine.Name,
+		engineParams,
+		engineModifiers,
+		ratingConfig,
+		tieBreakers,
+		scoring,
+		discipline,
+		zones,
+		league.Seed,
+		league.TournamentID,
 	).Scan(&league.ID)
 	if err != nil {
 		return err
 	}
 
+	// Insert league membership
+	teamQuery := `
+		INSERT INTO league_teams (league_id, team_id)
+		VALUES ($1, $2)
+	`
+	for _, team := range league.Teams {
+		if _, err := tx.ExecContext(ctx, teamQuery, league.ID, team.ID); err != nil {
+			return err
+		}
+	}
+
 	// Insert matches
 	matchQuery := `
-		INSERT INTO matches (home_team_id, away_team_id, week, played)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO matches (league_id, home_team_id, away_team_id, week, played)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
 	for i := range league.Matches {
 		match := league.Matches[i]
+		match.LeagueID = league.ID
 		err = tx.QueryRowContext(
 			ctx,
 			matchQuery,
+			match.LeagueID,
 			match.HomeTeamID,
 			match.AwayTeamID,
 			match.Week,
@@ -67,9 +128,41 @@ func (r *PostgresLeagueRepository) Create(ctx context.Context, league *model.Lea
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return err
+	// Insert byes
+	byeQuery := `
+		INSERT INTO byes (league_id, team_id, week)
+		VALUES ($1, $2, $3)
+	`
+	for _, bye := range league.Byes {
+		if _, err := tx.ExecContext(ctx, byeQuery, league.ID, bye.TeamID, bye.Week); err != nil {
+			return err
+		}
+	}
+
+	// Insert the initial standings snapshot
+	standingsQuery := `
+		INSERT INTO standings_history (league_id, team_id, week, clinch)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, standing := range league.Standings.Teams {
+		clinch, err := marshalClinch(standing.Clinch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, standingsQuery, league.ID, standing.TeamID, league.Standings.Week, clinch); err != nil {
+			return err
+		}
+	}
+
+	// Insert the initial ratings
+	ratingQuery := `
+		INSERT INTO team_ratings (league_id, team_id, week, rating)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, rating := range league.Ratings {
+		if _, err := tx.ExecContext(ctx, ratingQuery, league.ID, rating.TeamID, rating.Week, rating.Rating); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -79,16 +172,29 @@ func (r *PostgresLeagueRepository) Create(ctx context.Context, league *model.Lea
 func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.League, error) {
 	// Get league info
 	leagueQuery := `
-		SELECT id, name, current_week, total_weeks
+		SELECT id, name, current_week, total_weeks, rounds, engine, engine_params, engine_modifiers, rating_config, tie_breakers, scoring, discipline, zones, seed, tournament_id
 		FROM leagues
 		WHERE id = $1
 	`
 	league := &model.League{}
+	var engineParams, engineModifiers, ratingConfig, tieBreakers, scoring, discipline, zones []byte
+	var tournamentID sql.NullInt64
 	err := r.db.QueryRowContext(ctx, leagueQuery, id).Scan(
 		&league.ID,
 		&league.Name,
 		&league.CurrentWeek,
 		&league.TotalWeeks,
+		&league.Rounds,
+		&league.Engine.Name,
+		&engineParams,
+		&engineModifiers,
+		&ratingConfig,
+		&tieBreakers,
+		&scoring,
+		&discipline,
+		&zones,
+		&league.Seed,
+		&tournamentID,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -96,14 +202,38 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 		}
 		return nil, err
 	}
+	if err := json.Unmarshal(engineParams, &league.Engine.Params); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(engineModifiers, &league.Engine.Modifiers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ratingConfig, &league.Rating); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tieBreakers, &league.TieBreakers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scoring, &league.Scoring); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(discipline, &league.Discipline); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(zones, &league.Zones); err != nil {
+		return nil, err
+	}
+	league.TournamentID = nullIntPtr(tournamentID)
 
 	// Get teams
 	teamsQuery := `
-		SELECT id, name, strength
-		FROM teams
-		ORDER BY id
+		SELECT t.id, t.name, t.strength, t.squad_strength
+		FROM teams t
+		JOIN league_teams lt ON lt.team_id = t.id
+		WHERE lt.league_id = $1
+		ORDER BY t.id
 	`
-	teamRows, err := r.db.QueryContext(ctx, teamsQuery)
+	teamRows, err := r.db.QueryContext(ctx, teamsQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +242,7 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 	var teams []*model.Team
 	for teamRows.Next() {
 		team := &model.Team{}
-		if err := teamRows.Scan(&team.ID, &team.Name, &team.Strength); err != nil {
+		if err := teamRows.Scan(&team.ID, &team.Name, &team.Strength, &team.SquadStrength); err != nil {
 			return nil, err
 		}
 		teams = append(teams, team)
@@ -122,13 +252,22 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 	}
 	league.Teams = teams
 
+	// Get the squads, which the event engine fields its players from
+	playerRepo := NewPostgresPlayerRepository(r.db)
+	for _, team := range teams {
+		if team.Players, err = playerRepo.GetByTeam(ctx, team.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get matches
 	matchesQuery := `
-		SELECT id, home_team_id, away_team_id, home_score, away_score, week, played, played_at
+		SELECT id, league_id, home_team_id, away_team_id, home_score, away_score, week, played, played_at, seed, home_penalties, away_penalties, home_lineup, away_lineup
 		FROM matches
+		WHERE league_id = $1
 		ORDER BY week, id
 	`
-	matchRows, err := r.db.QueryContext(ctx, matchesQuery)
+	matchRows, err := r.db.QueryContext(ctx, matchesQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -138,8 +277,12 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 	for matchRows.Next() {
 		match := &model.Match{}
 		var playedAt sql.NullTime
+		var seed sql.NullInt64
+		var homePenalties, awayPenalties sql.NullInt64
+		var homeLineup, awayLineup []byte
 		if err := matchRows.Scan(
 			&match.ID,
+			&match.LeagueID,
 			&match.HomeTeamID,
 			&match.AwayTeamID,
 			&match.HomeScore,
@@ -147,12 +290,28 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 			&match.Week,
 			&match.Played,
 			&playedAt,
+			&seed,
+			&homePenalties,
+			&awayPenalties,
+			&homeLineup,
+			&awayLineup,
 		); err != nil {
 			return nil, err
 		}
 		if playedAt.Valid {
 			match.PlayedAt = playedAt.Time
 		}
+		if seed.Valid {
+			match.Seed = &seed.Int64
+		}
+		match.HomePenalties = nullIntPtr(homePenalties)
+		match.AwayPenalties = nullIntPtr(awayPenalties)
+		if match.HomeLineup, err = unmarshalLineup(homeLineup); err != nil {
+			return nil, err
+		}
+		if match.AwayLineup, err = unmarshalLineup(awayLineup); err != nil {
+			return nil, err
+		}
 		matches = append(matches, match)
 	}
 	if err := matchRows.Err(); err != nil {
@@ -160,16 +319,66 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 	}
 	league.Matches = matches
 
+	// Get the timelines of the matches simulated by the event engine
+	events, err := NewPostgresMatchEventRepository(r.db).GetByLeague(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	matchesByID := make(map[int]*model.Match, len(matches))
+	for _, match := range matches {
+		matchesByID[match.ID] = match
+	}
+	for _, event := range events {
+		if match, ok := matchesByID[event.MatchID]; ok {
+			match.Events = append(match.Events, event)
+		}
+	}
+
+	// Get byes
+	byesQuery := `
+		SELECT b.team_id, t.name, b.week
+		FROM byes b
+		JOIN teams t ON b.team_id = t.id
+		WHERE b.league_id = $1
+		ORDER BY b.week, b.team_id
+	`
+	byeRows, err := r.db.QueryContext(ctx, byesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer byeRows.Close()
+
+	var byes []*model.Bye
+	for byeRows.Next() {
+		bye := &model.Bye{}
+		if err := byeRows.Scan(&bye.TeamID, &bye.TeamName, &bye.Week); err != nil {
+			return nil, err
+		}
+		byes = append(byes, bye)
+	}
+	if err := byeRows.Err(); err != nil {
+		return nil, err
+	}
+	league.Byes = byes
+
+	// Get point deductions
+	deductions, err := NewPostgresDeductionRepository(r.db).GetByLeagueID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	league.Deductions = deductions
+
 	// Get standings
 	standingsQuery := `
 		SELECT s.team_id, t.name, s.points, s.played, s.wins, s.draws, s.losses, 
-			   s.goals_for, s.goals_against, s.goals_for - s.goals_against as goal_difference
+			   s.goals_for, s.goals_against, s.goals_for - s.goals_against as goal_difference, s.byes,
+			   s.bonus_points, s.points_deducted, s.clinch
 		FROM standings_history s
 		JOIN teams t ON s.team_id = t.id
-		WHERE s.week = $1
-		ORDER BY s.points DESC, goal_difference DESC, s.goals_for DESC, t.name
+		WHERE s.league_id = $1 AND s.week = $2
+		ORDER BY t.name
 	`
-	standingsRows, err := r.db.QueryContext(ctx, standingsQuery, league.CurrentWeek)
+	standingsRows, err := r.db.QueryContext(ctx, standingsQuery, id, league.CurrentWeek)
 	if err != nil {
 		return nil, err
 	}
@@ -182,6 +391,7 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 
 	for standingsRows.Next() {
 		var standing model.TeamStanding
+		var clinch []byte
 		if err := standingsRows.Scan(
 			&standing.TeamID,
 			&standing.TeamName,
@@ -193,25 +403,138 @@ func (r *PostgresLeagueRepository) GetByID(ctx context.Context, id int) (*model.
 			&standing.GoalsFor,
 			&standing.GoalsAgainst,
 			&standing.GoalDifference,
+			&standing.Byes,
+			&standing.BonusPoints,
+			&standing.PointsDeducted,
+			&clinch,
 		); err != nil {
 			return nil, err
 		}
+		if standing.Clinch, err = unmarshalClinch(clinch); err != nil {
+			return nil, err
+		}
 		standings.Teams = append(standings.Teams, standing)
 	}
 	if err := standingsRows.Err(); err != nil {
 		return nil, err
 	}
+	standings.Byes = league.ByesForWeek(league.CurrentWeek)
+	league.SortStandings(&standings, league.Matches)
 	league.Standings = standings
 
+	// Get the latest ratings up to the current week
+	if league.Rating.Enabled {
+		ratingsQuery := `
+			SELECT r.team_id, t.name, r.week, r.rating
+			FROM team_ratings r
+			JOIN teams t ON r.team_id = t.id
+			WHERE r.league_id = $1 AND r.week = (
+				SELECT MAX(week) FROM team_ratings WHERE league_id = $1 AND week <= $2
+			)
+			ORDER BY r.team_id
+		`
+		ratingRows, err := r.db.QueryContext(ctx, ratingsQuery, id, league.CurrentWeek)
+		if err != nil {
+			return nil, err
+		}
+		defer ratingRows.Close()
+
+		for ratingRows.Next() {
+			rating := &model.TeamRating{}
+			if err := ratingRows.Scan(&rating.TeamID, &rating.TeamName, &rating.Week, &rating.Rating); err != nil {
+				return nil, err
+			}
+			league.Ratings = append(league.Ratings, rating)
+		}
+		if err := ratingRows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
 	return league, nil
 }
 
+// GetAll retrieves all leagues without their teams, matches and standings
+func (r *PostgresLeagueRepository) GetAll(ctx context.Context) ([]*model.League, error) {
+	query := `
+		SELECT id, name, current_week, total_weeks, rounds, engine, engine_params, engine_modifiers, rating_config, tie_breakers, scoring, discipline, zones, seed, tournament_id
+		FROM leagues
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leagues []*model.League
+	for rows.Next() {
+		league := &model.League{}
+		var engineParams, engineModifiers, ratingConfig, tieBreakers, scoring, discipline, zones []byte
+		var tournamentID sql.NullInt64
+		if err := rows.Scan(
+			&league.ID,
+			&league.Name,
+			&league.CurrentWeek,
+			&league.TotalWeeks,
+			&league.Rounds,
+			&league.Engine.Name,
+			&engineParams,
+			&engineModifiers,
+			&ratingConfig,
+			&tieBreakers,
+			&scoring,
+			&discipline,
+			&zones,
+			&league.Seed,
+			&tournamentID,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(engineParams, &league.Engine.Params); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(engineModifiers, &league.Engine.Modifiers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(ratingConfig, &league.Rating); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tieBreakers, &league.TieBreakers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(scoring, &league.Scoring); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(discipline, &league.Discipline); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(zones, &league.Zones); err != nil {
+			return nil, err
+		}
+		league.TournamentID = nullIntPtr(tournamentID)
+		leagues = append(leagues, league)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return leagues, nil
+}
+
 // Update updates a league
 func (r *PostgresLeagueRepository) Update(ctx context.Context, league *model.League) error {
+	zones, err := marshalZones(league.Zones)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE leagues
-		SET name = $1, current_week = $2, total_weeks = $3
-		WHERE id = $4
+		SET name = $1, current_week = $2, total_weeks = $3, zones = $4
+		WHERE id = $5
 	`
 
 	result, err := r.db.ExecContext(
@@ -220,6 +543,7 @@ func (r *PostgresLeagueRepository) Update(ctx context.Context, league *model.Lea
 		league.Name,
 		league.CurrentWeek,
 		league.TotalWeeks,
+		zones,
 		league.ID,
 	)
 	if err != nil {
@@ -237,3 +561,26 @@ func (r *PostgresLeagueRepository) Update(ctx context.Context, league *model.Lea
 
 	return nil
 }
+
+// Lock locks the row of a league until the end of the surrounding
+// transaction, so that concurrent changes to the league wait for each other
+func (r *PostgresLeagueRepository) Lock(ctx context.Context, id int) error {
+	var locked int
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM leagues WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("league not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// marshalZones encodes the zones of a league, an empty list when it has none
+func marshalZones(zones []model.PositionBand) ([]byte, error) {
+	if zones == nil {
+		zones = []model.PositionBand{}
+	}
+	return json.Marshal(zones)
+}
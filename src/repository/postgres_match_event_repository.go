@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresMatchEventRepository implements the MatchEventRepository interface
+type PostgresMatchEventRepository struct {
+	db DBTX
+}
+
+// NewPostgresMatchEventRepository creates a new PostgresMatchEventRepository
+func NewPostgresMatchEventRepository(db DBTX) *PostgresMatchEventRepository {
+	return &PostgresMatchEventRepository{
+		db: db,
+	}
+}
+
+// Save replaces the timeline of a match with the given events
+func (r *PostgresMatchEventRepository) Save(ctx context.Context, matchID int, events []*model.MatchEvent) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM match_events WHERE match_id = $1`, matchID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO match_events (match_id, minute, type, team_id, player_id, related_player_id, detail, weeks)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	for _, event := range events {
+		event.MatchID = matchID
+		err := r.db.QueryRowContext(
+			ctx,
+			query,
+			event.MatchID,
+			event.Minute,
+			event.Type,
+			event.TeamID,
+			event.PlayerID,
+			event.RelatedPlayerID,
+			event.Detail,
+			event.Weeks,
+		).Scan(&event.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByMatch retrieves the timeline of a match in the order the events happened
+func (r *PostgresMatchEventRepository) GetByMatch(ctx context.Context, matchID int) ([]*model.MatchEvent, error) {
+	query := `
+		SELECT e.id, e.match_id, e.minute, e.type, e.team_id, e.player_id, p.name, e.related_player_id, rp.name, e.detail, e.weeks
+		FROM match_events e
+		LEFT JOIN players p ON e.player_id = p.id
+		LEFT JOIN players rp ON e.related_player_id = rp.id
+		WHERE e.match_id = $1
+		ORDER BY e.minute, e.id
+	`
+
+	return r.query(ctx, query, matchID)
+}
+
+// GetByLeague retrieves the timelines of every match of a league, ordered by match and minute
+func (r *PostgresMatchEventRepository) GetByLeague(ctx context.Context, leagueID int) ([]*model.MatchEvent, error) {
+	query := `
+		SELECT e.id, e.match_id, e.minute, e.type, e.team_id, e.player_id, p.name, e.related_player_id, rp.name, e.detail, e.weeks
+		FROM match_events e
+		JOIN matches m ON e.match_id = m.id
+		LEFT JOIN players p ON e.player_id = p.id
+		LEFT JOIN players rp ON e.related_player_id = rp.id
+		WHERE m.league_id = $1
+		ORDER BY e.match_id, e.minute, e.id
+	`
+
+	return r.query(ctx, query, leagueID)
+}
+
+// query runs a select of match events
+func (r *PostgresMatchEventRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.MatchEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*model.MatchEvent, 0)
+	for rows.Next() {
+		event := &model.MatchEvent{}
+		var playerID, relatedPlayerID sql.NullInt64
+		var playerName, relatedPlayerName sql.NullString
+		if err := rows.Scan(
+			&event.ID,
+			&event.MatchID,
+			&event.Minute,
+			&event.Type,
+			&event.TeamID,
+			&playerID,
+			&playerName,
+			&relatedPlayerID,
+			&relatedPlayerName,
+			&event.Detail,
+			&event.Weeks,
+		); err != nil {
+			return nil, err
+		}
+		event.PlayerID = nullIntPtr(playerID)
+		event.PlayerName = playerName.String
+		event.RelatedPlayerID = nullIntPtr(relatedPlayerID)
+		event.RelatedPlayerName = relatedPlayerName.String
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/user/league-simulator/src/model"
@@ -10,11 +11,11 @@ import (
 
 // PostgresMatchRepository implements the MatchRepository interface
 type PostgresMatchRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPostgresMatchRepository creates a new PostgresMatchRepository
-func NewPostgresMatchRepository(db *sql.DB) *PostgresMatchRepository {
+func NewPostgresMatchRepository(db DBTX) *PostgresMatchRepository {
 	return &PostgresMatchRepository{
 		db: db,
 	}
@@ -23,14 +24,15 @@ func NewPostgresMatchRepository(db *sql.DB) *PostgresMatchRepository {
 // Create inserts a new match into the database
 func (r *PostgresMatchRepository) Create(ctx context.Context, match *model.Match) error {
 	query := `
-		INSERT INTO matches (home_team_id, away_team_id, home_score, away_score, week, played, played_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO matches (league_id, home_team_id, away_team_id, home_score, away_score, week, played, played_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 
 	err := r.db.QueryRowContext(
 		ctx,
 		query,
+		match.LeagueID,
 		match.HomeTeamID,
 		match.AwayTeamID,
 		match.HomeScore,
@@ -50,7 +52,7 @@ func (r *PostgresMatchRepository) Create(ctx context.Context, match *model.Match
 // GetByID retrieves a match by its ID
 func (r *PostgresMatchRepository) GetByID(ctx context.Context, id int) (*model.Match, error) {
 	query := `
-		SELECT m.id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at,
+		SELECT m.id, m.league_id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at, m.seed, m.home_penalties, m.away_penalties,
 			   ht.id, ht.name, ht.strength,
 			   at.id, at.name, at.strength
 		FROM matches m
@@ -63,9 +65,12 @@ func (r *PostgresMatchRepository) GetByID(ctx context.Context, id int) (*model.M
 	var homeTeam model.Team
 	var awayTeam model.Team
 	var playedAt sql.NullTime
+	var seed sql.NullInt64
+	var homePenalties, awayPenalties sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&match.ID,
+		&match.LeagueID,
 		&match.HomeTeamID,
 		&match.AwayTeamID,
 		&match.HomeScore,
@@ -73,6 +78,9 @@ func (r *PostgresMatchRepository) GetByID(ctx context.Context, id int) (*model.M
 		&match.Week,
 		&match.Played,
 		&playedAt,
+		&seed,
+		&homePenalties,
+		&awayPenalties,
 		&homeTeam.ID,
 		&homeTeam.Name,
 		&homeTeam.Strength,
@@ -91,6 +99,11 @@ func (r *PostgresMatchRepository) GetByID(ctx context.Context, id int) (*model.M
 	if playedAt.Valid {
 		match.PlayedAt = playedAt.Time
 	}
+	if seed.Valid {
+		match.Seed = &seed.Int64
+	}
+	match.HomePenalties = nullIntPtr(homePenalties)
+	match.AwayPenalties = nullIntPtr(awayPenalties)
 
 	match.HomeTeam = &homeTeam
 	match.AwayTeam = &awayTeam
@@ -98,20 +111,20 @@ func (r *PostgresMatchRepository) GetByID(ctx context.Context, id int) (*model.M
 	return &match, nil
 }
 
-// GetByWeek retrieves all matches for a specific week
-func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, week int) ([]*model.Match, error) {
+// GetByWeek retrieves all matches of a league for a specific week
+func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, leagueID, week int) ([]*model.Match, error) {
 	query := `
-		SELECT m.id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at,
+		SELECT m.id, m.league_id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at, m.seed, m.home_penalties, m.away_penalties,
 			   ht.id, ht.name, ht.strength,
 			   at.id, at.name, at.strength
 		FROM matches m
 		JOIN teams ht ON m.home_team_id = ht.id
 		JOIN teams at ON m.away_team_id = at.id
-		WHERE m.week = $1
+		WHERE m.league_id = $1 AND m.week = $2
 		ORDER BY m.id
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, week)
+	rows, err := r.db.QueryContext(ctx, query, leagueID, week)
 	if err != nil {
 		return nil, err
 	}
@@ -123,9 +136,12 @@ func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, week int) ([]*m
 		var homeTeam model.Team
 		var awayTeam model.Team
 		var playedAt sql.NullTime
+		var seed sql.NullInt64
+		var homePenalties, awayPenalties sql.NullInt64
 
 		if err := rows.Scan(
 			&match.ID,
+			&match.LeagueID,
 			&match.HomeTeamID,
 			&match.AwayTeamID,
 			&match.HomeScore,
@@ -133,6 +149,9 @@ func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, week int) ([]*m
 			&match.Week,
 			&match.Played,
 			&playedAt,
+			&seed,
+			&homePenalties,
+			&awayPenalties,
 			&homeTeam.ID,
 			&homeTeam.Name,
 			&homeTeam.Strength,
@@ -146,6 +165,11 @@ func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, week int) ([]*m
 		if playedAt.Valid {
 			match.PlayedAt = playedAt.Time
 		}
+		if seed.Valid {
+			match.Seed = &seed.Int64
+		}
+		match.HomePenalties = nullIntPtr(homePenalties)
+		match.AwayPenalties = nullIntPtr(awayPenalties)
 
 		match.HomeTeam = &homeTeam
 		match.AwayTeam = &awayTeam
@@ -160,10 +184,68 @@ func (r *PostgresMatchRepository) GetByWeek(ctx context.Context, week int) ([]*m
 	return matches, nil
 }
 
+// GetByLeague retrieves all matches of a league
+func (r *PostgresMatchRepository) GetByLeague(ctx context.Context, leagueID int) ([]*model.Match, error) {
+	query := `
+		SELECT m.id, m.league_id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at, m.seed, m.home_penalties, m.away_penalties
+		FROM matches m
+		WHERE m.league_id = $1
+		ORDER BY m.week, m.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*model.Match
+	for rows.Next() {
+		var match model.Match
+		var playedAt sql.NullTime
+		var seed sql.NullInt64
+		var homePenalties, awayPenalties sql.NullInt64
+
+		if err := rows.Scan(
+			&match.ID,
+			&match.LeagueID,
+			&match.HomeTeamID,
+			&match.AwayTeamID,
+			&match.HomeScore,
+			&match.AwayScore,
+			&match.Week,
+			&match.Played,
+			&playedAt,
+			&seed,
+			&homePenalties,
+			&awayPenalties,
+		); err != nil {
+			return nil, err
+		}
+
+		if playedAt.Valid {
+			match.PlayedAt = playedAt.Time
+		}
+		if seed.Valid {
+			match.Seed = &seed.Int64
+		}
+		match.HomePenalties = nullIntPtr(homePenalties)
+		match.AwayPenalties = nullIntPtr(awayPenalties)
+
+		matches = append(matches, &match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
 // GetAll retrieves all matches
 func (r *PostgresMatchRepository) GetAll(ctx context.Context) ([]*model.Match, error) {
 	query := `
-		SELECT m.id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at
+		SELECT m.id, m.league_id, m.home_team_id, m.away_team_id, m.home_score, m.away_score, m.week, m.played, m.played_at, m.seed, m.home_penalties, m.away_penalties
 		FROM matches m
 		ORDER BY m.week, m.id
 	`
@@ -178,9 +260,12 @@ func (r *PostgresMatchRepository) GetAll(ctx context.Context) ([]*model.Match, e
 	for rows.Next() {
 		var match model.Match
 		var playedAt sql.NullTime
+		var seed sql.NullInt64
+		var homePenalties, awayPenalties sql.NullInt64
 
 		if err := rows.Scan(
 			&match.ID,
+			&match.LeagueID,
 			&match.HomeTeamID,
 			&match.AwayTeamID,
 			&match.HomeScore,
@@ -188,6 +273,9 @@ func (r *PostgresMatchRepository) GetAll(ctx context.Context) ([]*model.Match, e
 			&match.Week,
 			&match.Played,
 			&playedAt,
+			&seed,
+			&homePenalties,
+			&awayPenalties,
 		); err != nil {
 			return nil, err
 		}
@@ -195,6 +283,11 @@ func (r *PostgresMatchRepository) GetAll(ctx context.Context) ([]*model.Match, e
 		if playedAt.Valid {
 			match.PlayedAt = playedAt.Time
 		}
+		if seed.Valid {
+			match.Seed = &seed.Int64
+		}
+		match.HomePenalties = nullIntPtr(homePenalties)
+		match.AwayPenalties = nullIntPtr(awayPenalties)
 
 		matches = append(matches, &match)
 	}
@@ -208,16 +301,35 @@ func (r *PostgresMatchRepository) GetAll(ctx context.Context) ([]*model.Match, e
 
 // Update updates a match
 func (r *PostgresMatchRepository) Update(ctx context.Context, match *model.Match) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return updateMatch(ctx, tx, match)
+	})
+}
+
+// updateMatch updates a match within the given transaction
+func updateMatch(ctx context.Context, tx *sql.Tx, match *model.Match) error {
+	homeLineup, err := marshalLineup(match.HomeLineup)
+	if err != nil {
+		return err
+	}
+
+	awayLineup, err := marshalLineup(match.AwayLineup)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE matches
-		SET home_team_id = $1, away_team_id = $2, home_score = $3, away_score = $4, 
-			week = $5, played = $6, played_at = $7
-		WHERE id = $8
+		SET league_id = $1, home_team_id = $2, away_team_id = $3, home_score = $4, away_score = $5,
+			week = $6, played = $7, played_at = $8, seed = $9, home_penalties = $10, away_penalties = $11,
+			home_lineup = $12, away_lineup = $13
+		WHERE id = $14
 	`
 
-	result, err := r.db.ExecContext(
+	result, err := tx.ExecContext(
 		ctx,
 		query,
+		match.LeagueID,
 		match.HomeTeamID,
 		match.AwayTeamID,
 		match.HomeScore,
@@ -225,6 +337,11 @@ func (r *PostgresMatchRepository) Update(ctx context.Context, match *model.Match
 		match.Week,
 		match.Played,
 		match.PlayedAt,
+		match.Seed,
+		match.HomePenalties,
+		match.AwayPenalties,
+		homeLineup,
+		awayLineup,
 		match.ID,
 	)
 	if err != nil {
@@ -266,3 +383,27 @@ func (r *PostgresMatchRepository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// marshalLineup encodes the starting players of a match side, NULL when unknown
+func marshalLineup(lineup []int) (sql.NullString, error) {
+	if len(lineup) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(lineup)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unmarshalLineup decodes the starting players of a match side
+func unmarshalLineup(data []byte) ([]int, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var lineup []int
+	if err := json.Unmarshal(data, &lineup); err != nil {
+		return nil, err
+	}
+	return lineup, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresPlayerRepository implements the PlayerRepository interface
+type PostgresPlayerRepository struct {
+	db DBTX
+}
+
+// NewPostgresPlayerRepository creates a new PostgresPlayerRepository
+func NewPostgresPlayerRepository(db DBTX) *PostgresPlayerRepository {
+	return &PostgresPlayerRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new player into the database
+func (r *PostgresPlayerRepository) Create(ctx context.Context, player *model.Player) error {
+	query := `
+		INSERT INTO players (team_id, name, position, shirt_number, attack, defence, goalkeeping, age)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		player.TeamID,
+		player.Name,
+		player.Position,
+		player.ShirtNumber,
+		player.Attack,
+		player.Defence,
+		player.Goalkeeping,
+		player.Age,
+	).Scan(&player.ID)
+}
+
+// GetByID retrieves a player by its ID
+func (r *PostgresPlayerRepository) GetByID(ctx context.Context, id int) (*model.Player, error) {
+	query := `
+		SELECT id, team_id, name, position, shirt_number, attack, defence, goalkeeping, age
+		FROM players
+		WHERE id = $1
+	`
+
+	player, err := scanPlayer(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("player not found")
+		}
+		return nil, err
+	}
+
+	return player, nil
+}
+
+// GetByTeam retrieves the squad of a team ordered by shirt number
+func (r *PostgresPlayerRepository) GetByTeam(ctx context.Context, teamID int) ([]*model.Player, error) {
+	query := `
+		SELECT id, team_id, name, position, shirt_number, attack, defence, goalkeeping, age
+		FROM players
+		WHERE team_id = $1
+		ORDER BY shirt_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]*model.Player, 0)
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
+// Update updates a player
+func (r *PostgresPlayerRepository) Update(ctx context.Context, player *model.Player) error {
+	query := `
+		UPDATE players
+		SET name = $1, position = $2, shirt_number = $3, attack = $4, defence = $5, goalkeeping = $6, age = $7
+		WHERE id = $8 AND team_id = $9
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		player.Name,
+		player.Position,
+		player.ShirtNumber,
+		player.Attack,
+		player.Defence,
+		player.Goalkeeping,
+		player.Age,
+		player.ID,
+		player.TeamID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("player not found")
+	}
+
+	return nil
+}
+
+// Delete removes a player from a team's squad
+func (r *PostgresPlayerRepository) Delete(ctx context.Context, teamID, id int) error {
+	query := `
+		DELETE FROM players
+		WHERE id = $1 AND team_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, teamID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("player not found")
+	}
+
+	return nil
+}
+
+// rowScanner is the part of *sql.Row and *sql.Rows a player is scanned from
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPlayer reads a player from a row of the players table
+func scanPlayer(row rowScanner) (*model.Player, error) {
+	player := &model.Player{}
+	err := row.Scan(
+		&player.ID,
+		&player.TeamID,
+		&player.Name,
+		&player.Position,
+		&player.ShirtNumber,
+		&player.Attack,
+		&player.Defence,
+		&player.Goalkeeping,
+		&player.Age,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return player, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresRatingRepository implements the RatingRepository interface
+type PostgresRatingRepository struct {
+	db DBTX
+}
+
+// NewPostgresRatingRepository creates a new PostgresRatingRepository
+func NewPostgresRatingRepository(db DBTX) *PostgresRatingRepository {
+	return &PostgresRatingRepository{
+		db: db,
+	}
+}
+
+// GetHistory retrieves every stored rating of a league ordered by week
+func (r *PostgresRatingRepository) GetHistory(ctx context.Context, leagueID int) ([]*model.TeamRating, error) {
+	query := `
+		SELECT r.team_id, t.name, r.week, r.rating
+		FROM team_ratings r
+		JOIN teams t ON r.team_id = t.id
+		WHERE r.league_id = $1
+		ORDER BY r.week, r.team_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make([]*model.TeamRating, 0)
+	for rows.Next() {
+		rating := &model.TeamRating{}
+		if err := rows.Scan(&rating.TeamID, &rating.TeamName, &rating.Week, &rating.Rating); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// Save inserts or updates the given rating snapshots of a league
+func (r *PostgresRatingRepository) Save(ctx context.Context, leagueID int, ratings []*model.TeamRating) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return saveRatings(ctx, tx, leagueID, ratings)
+	})
+}
+
+// saveRatings inserts or updates rating snapshots within the given transaction
+func saveRatings(ctx context.Context, tx *sql.Tx, leagueID int, ratings []*model.TeamRating) error {
+	query := `
+		INSERT INTO team_ratings (league_id, team_id, week, rating)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (league_id, week, team_id) DO UPDATE SET rating = $4
+	`
+	for _, rating := range ratings {
+		if _, err := tx.ExecContext(ctx, query, leagueID, rating.TeamID, rating.Week, rating.Rating); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteAfterWeek removes the rating snapshots of a league recorded for weeks after the given week
+func (r *PostgresRatingRepository) DeleteAfterWeek(ctx context.Context, leagueID, week int) error {
+	query := `
+		DELETE FROM team_ratings
+		WHERE league_id = $1 AND week > $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, leagueID, week)
+	return err
+}
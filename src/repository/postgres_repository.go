@@ -1,23 +1,33 @@
 package repository
 
-import (
-	"database/sql"
-)
-
 // PostgresRepository implements all repository interfaces using PostgreSQL
 type PostgresRepository struct {
-	Team      TeamRepository
-	Match     MatchRepository
-	Standings StandingsRepository
-	League    LeagueRepository
+	Team       TeamRepository
+	Player     PlayerRepository
+	Match      MatchRepository
+	MatchEvent MatchEventRepository
+	Standings  StandingsRepository
+	League     LeagueRepository
+	Rating     RatingRepository
+	Deduction  DeductionRepository
+	Cup        CupRepository
+	Tournament TournamentRepository
+	UnitOfWork UnitOfWork
 }
 
 // NewPostgresRepository creates a new PostgresRepository with all implementations
-func NewPostgresRepository(db *sql.DB) *Repository {
+func NewPostgresRepository(db DBTX) *Repository {
 	return &Repository{
-		Team:      NewPostgresTeamRepository(db),
-		Match:     NewPostgresMatchRepository(db),
-		Standings: NewPostgresStandingsRepository(db),
-		League:    NewPostgresLeagueRepository(db),
+		Team:       NewPostgresTeamRepository(db),
+		Player:     NewPostgresPlayerRepository(db),
+		Match:      NewPostgresMatchRepository(db),
+		MatchEvent: NewPostgresMatchEventRepository(db),
+		Standings:  NewPostgresStandingsRepository(db),
+		League:     NewPostgresLeagueRepository(db),
+		Rating:     NewPostgresRatingRepository(db),
+		Deduction:  NewPostgresDeductionRepository(db),
+		Cup:        NewPostgresCupRepository(db),
+		Tournament: NewPostgresTournamentRepository(db),
+		UnitOfWork: NewPostgresUnitOfWork(db),
 	}
 }
@@ -3,41 +3,46 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/user/league-simulator/src/model"
 )
 
 // PostgresStandingsRepository implements the StandingsRepository interface
 type PostgresStandingsRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPostgresStandingsRepository creates a new PostgresStandingsRepository
-func NewPostgresStandingsRepository(db *sql.DB) *PostgresStandingsRepository {
+func NewPostgresStandingsRepository(db DBTX) *PostgresStandingsRepository {
 	return &PostgresStandingsRepository{
 		db: db,
 	}
 }
 
-// GetCurrent retrieves the current standings
-func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context) (*model.Standings, error) {
+// GetCurrent retrieves the current standings of a league
+func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context, leagueID int) (*model.Standings, error) {
 	// First get the current week
 	weekQuery := `
-		SELECT COALESCE(MAX(week), 0) FROM standings_history
+		SELECT COALESCE(MAX(week), 0) FROM standings_history WHERE league_id = $1
 	`
 	var week int
-	err := r.db.QueryRowContext(ctx, weekQuery).Scan(&week)
+	err := r.db.QueryRowContext(ctx, weekQuery, leagueID).Scan(&week)
 	if err != nil {
 		return nil, err
 	}
 
 	// If no standings exist yet, return empty standings
 	if week == 0 {
-		// Get all teams to create empty standings
+		// Get all teams of the league to create empty standings
 		teamsQuery := `
-			SELECT id, name FROM teams ORDER BY id
+			SELECT t.id, t.name
+			FROM teams t
+			JOIN league_teams lt ON lt.team_id = t.id
+			WHERE lt.league_id = $1
+			ORDER BY t.id
 		`
-		rows, err := r.db.QueryContext(ctx, teamsQuery)
+		rows, err := r.db.QueryContext(ctx, teamsQuery, leagueID)
 		if err != nil {
 			return nil, err
 		}
@@ -68,17 +73,18 @@ func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context) (*model.St
 		return standings, nil
 	}
 
-	// Get standings for the current week
+	// Get standings for the current week, ordered by the league's tie-breakers in the service layer
 	query := `
 		SELECT s.team_id, t.name, s.points, s.played, s.wins, s.draws, s.losses, 
-			   s.goals_for, s.goals_against, s.goals_for - s.goals_against as goal_difference
+			   s.goals_for, s.goals_against, s.goals_for - s.goals_against as goal_difference, s.byes,
+			   s.bonus_points, s.points_deducted, s.clinch
 		FROM standings_history s
 		JOIN teams t ON s.team_id = t.id
-		WHERE s.week = $1
-		ORDER BY s.points DESC, goal_difference DESC, s.goals_for DESC, t.name
+		WHERE s.league_id = $1 AND s.week = $2
+		ORDER BY t.name
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, week)
+	rows, err := r.db.QueryContext(ctx, query, leagueID, week)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +97,7 @@ func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context) (*model.St
 
 	for rows.Next() {
 		var standing model.TeamStanding
+		var clinch []byte
 		if err := rows.Scan(
 			&standing.TeamID,
 			&standing.TeamName,
@@ -102,9 +109,16 @@ func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context) (*model.St
 			&standing.GoalsFor,
 			&standing.GoalsAgainst,
 			&standing.GoalDifference,
+			&standing.Byes,
+			&standing.BonusPoints,
+			&standing.PointsDeducted,
+			&clinch,
 		); err != nil {
 			return nil, err
 		}
+		if standing.Clinch, err = unmarshalClinch(clinch); err != nil {
+			return nil, err
+		}
 
 		standings.Teams = append(standings.Teams, standing)
 	}
@@ -113,34 +127,69 @@ func (r *PostgresStandingsRepository) GetCurrent(ctx context.Context) (*model.St
 		return nil, err
 	}
 
-	return standings, nil
-}
+	// Get the teams sitting out the week
+	byesQuery := `
+		SELECT b.team_id, t.name, b.week
+		FROM byes b
+		JOIN teams t ON b.team_id = t.id
+		WHERE b.league_id = $1 AND b.week = $2
+		ORDER BY b.team_id
+	`
 
-// Update updates the standings
-func (r *PostgresStandingsRepository) Update(ctx context.Context, standings *model.Standings) error {
-	// Begin transaction
-	tx, err := r.db.BeginTx(ctx, nil)
+	byeRows, err := r.db.QueryContext(ctx, byesQuery, leagueID, week)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer byeRows.Close()
+
+	for byeRows.Next() {
+		bye := &model.Bye{}
+		if err := byeRows.Scan(&bye.TeamID, &bye.TeamName, &bye.Week); err != nil {
+			return nil, err
+		}
+		standings.Byes = append(standings.Byes, bye)
 	}
-	defer tx.Rollback()
 
+	if err := byeRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return standings, nil
+}
+
+// Update stores the standings snapshot of a league for standings.Week
+func (r *PostgresStandingsRepository) Update(ctx context.Context, leagueID int, standings *model.Standings) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return saveStandings(ctx, tx, leagueID, standings)
+	})
+}
+
+// saveStandings stores a standings snapshot within the given transaction
+func saveStandings(ctx context.Context, tx *sql.Tx, leagueID int, standings *model.Standings) error {
 	// Insert or update standings for each team
 	for _, team := range standings.Teams {
+		clinch, err := marshalClinch(team.Clinch)
+		if err != nil {
+			return err
+		}
+
 		query := `
 			INSERT INTO standings_history (
-				team_id, week, points, played, wins, draws, losses, goals_for, goals_against
+				league_id, team_id, week, points, played, wins, draws, losses, goals_for, goals_against, byes,
+				bonus_points, points_deducted, clinch
 			) VALUES (
-				$1, $2, $3, $4, $5, $6, $7, $8, $9
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 			)
-			ON CONFLICT (team_id, week) DO UPDATE SET
-				points = $3, played = $4, wins = $5, draws = $6, losses = $7, 
-				goals_for = $8, goals_against = $9
+			ON CONFLICT (league_id, team_id, week) DO UPDATE SET
+				points = $4, played = $5, wins = $6, draws = $7, losses = $8,
+				goals_for = $9, goals_against = $10, byes = $11,
+				bonus_points = $12, points_deducted = $13, clinch = $14
 		`
 
-		_, err := tx.ExecContext(
+		_, err = tx.ExecContext(
 			ctx,
 			query,
+			leagueID,
 			team.TeamID,
 			standings.Week,
 			team.Points,
@@ -150,16 +199,50 @@ func (r *PostgresStandingsRepository) Update(ctx context.Context, standings *mod
 			team.Losses,
 			team.GoalsFor,
 			team.GoalsAgainst,
+			team.Byes,
+			team.BonusPoints,
+			team.PointsDeducted,
+			clinch,
 		)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return err
+	return nil
+}
+
+// DeleteAfterWeek removes the standings snapshots of a league recorded for weeks after the given week
+func (r *PostgresStandingsRepository) DeleteAfterWeek(ctx context.Context, leagueID, week int) error {
+	query := `
+		DELETE FROM standings_history
+		WHERE league_id = $1 AND week > $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, leagueID, week)
+	return err
+}
+
+// marshalClinch encodes the decided position bands of a team, NULL when not worked out
+func marshalClinch(clinch []*model.ClinchStatus) (sql.NullString, error) {
+	if clinch == nil {
+		return sql.NullString{}, nil
 	}
+	data, err := json.Marshal(clinch)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
 
-	return nil
+// unmarshalClinch decodes the decided position bands of a team
+func unmarshalClinch(data []byte) ([]*model.ClinchStatus, error) {
+	if data == nil {
+		return nil, nil
+	}
+	clinch := make([]*model.ClinchStatus, 0)
+	if err := json.Unmarshal(data, &clinch); err != nil {
+		return nil, err
+	}
+	return clinch, nil
 }
@@ -10,11 +10,11 @@ import (
 
 // PostgresTeamRepository implements the TeamRepository interface
 type PostgresTeamRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPostgresTeamRepository creates a new PostgresTeamRepository
-func NewPostgresTeamRepository(db *sql.DB) *PostgresTeamRepository {
+func NewPostgresTeamRepository(db DBTX) *PostgresTeamRepository {
 	return &PostgresTeamRepository{
 		db: db,
 	}
@@ -23,12 +23,12 @@ func NewPostgresTeamRepository(db *sql.DB) *PostgresTeamRepository {
 // Create inserts a new team into the database
 func (r *PostgresTeamRepository) Create(ctx context.Context, team *model.Team) error {
 	query := `
-		INSERT INTO teams (name, strength)
-		VALUES ($1, $2)
+		INSERT INTO teams (name, strength, squad_strength)
+		VALUES ($1, $2, $3)
 		RETURNING id
 	`
 
-	err := r.db.QueryRowContext(ctx, query, team.Name, team.Strength).Scan(&team.ID)
+	err := r.db.QueryRowContext(ctx, query, team.Name, team.Strength, team.SquadStrength).Scan(&team.ID)
 	if err != nil {
 		return err
 	}
@@ -39,13 +39,13 @@ func (r *PostgresTeamRepository) Create(ctx context.Context, team *model.Team) e
 // GetByID retrieves a team by its ID
 func (r *PostgresTeamRepository) GetByID(ctx context.Context, id int) (*model.Team, error) {
 	query := `
-		SELECT id, name, strength
+		SELECT id, name, strength, squad_strength
 		FROM teams
 		WHERE id = $1
 	`
 
 	team := &model.Team{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&team.ID, &team.Name, &team.Strength)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&team.ID, &team.Name, &team.Strength, &team.SquadStrength)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("team not found")
@@ -59,7 +59,7 @@ func (r *PostgresTeamRepository) GetByID(ctx context.Context, id int) (*model.Te
 // GetAll retrieves all teams
 func (r *PostgresTeamRepository) GetAll(ctx context.Context) ([]*model.Team, error) {
 	query := `
-		SELECT id, name, strength
+		SELECT id, name, strength, squad_strength
 		FROM teams
 		ORDER BY id
 	`
@@ -73,7 +73,39 @@ func (r *PostgresTeamRepository) GetAll(ctx context.Context) ([]*model.Team, err
 	var teams []*model.Team
 	for rows.Next() {
 		team := &model.Team{}
-		if err := rows.Scan(&team.ID, &team.Name, &team.Strength); err != nil {
+		if err := rows.Scan(&team.ID, &team.Name, &team.Strength, &team.SquadStrength); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// GetByLeague retrieves all teams taking part in a league
+func (r *PostgresTeamRepository) GetByLeague(ctx context.Context, leagueID int) ([]*model.Team, error) {
+	query := `
+		SELECT t.id, t.name, t.strength, t.squad_strength
+		FROM teams t
+		JOIN league_teams lt ON lt.team_id = t.id
+		WHERE lt.league_id = $1
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []*model.Team
+	for rows.Next() {
+		team := &model.Team{}
+		if err := rows.Scan(&team.ID, &team.Name, &team.Strength, &team.SquadStrength); err != nil {
 			return nil, err
 		}
 		teams = append(teams, team)
@@ -90,11 +122,11 @@ func (r *PostgresTeamRepository) GetAll(ctx context.Context) ([]*model.Team, err
 func (r *PostgresTeamRepository) Update(ctx context.Context, team *model.Team) error {
 	query := `
 		UPDATE teams
-		SET name = $1, strength = $2
-		WHERE id = $3
+		SET name = $1, strength = $2, squad_strength = $3
+		WHERE id = $4
 	`
 
-	result, err := r.db.ExecContext(ctx, query, team.Name, team.Strength, team.ID)
+	result, err := r.db.ExecContext(ctx, query, team.Name, team.Strength, team.SquadStrength, team.ID)
 	if err != nil {
 		return err
 	}
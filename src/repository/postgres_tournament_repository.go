@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// PostgresTournamentRepository implements the TournamentRepository interface.
+// Groups are stored as leagues and the knockout stage as a cup.
+type PostgresTournamentRepository struct {
+	db      DBTX
+	leagues *PostgresLeagueRepository
+	cups    *PostgresCupRepository
+}
+
+// NewPostgresTournamentRepository creates a new PostgresTournamentRepository
+func NewPostgresTournamentRepository(db DBTX) *PostgresTournamentRepository {
+	return &PostgresTournamentRepository{
+		db:      db,
+		leagues: NewPostgresLeagueRepository(db),
+		cups:    NewPostgresCupRepository(db),
+	}
+}
+
+// Create inserts a new tournament with the leagues of its groups
+func (r *PostgresTournamentRepository) Create(ctx context.Context, tournament *model.Tournament) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		engineParams, err := json.Marshal(tournament.Engine.Params)
+		if err != nil {
+			return err
+		}
+
+		// Insert tournament
+		query := `
+			INSERT INTO tournaments (
+				name, group_count, advance_per_group, group_rounds, two_legged, two_legged_final, engine, engine_params, seed
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9
+			)
+			RETURNING id
+		`
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			tournament.Name,
+			tournament.GroupCount,
+			tournament.AdvancePerGroup,
+			tournament.GroupRounds,
+			tournament.TwoLegged,
+			tournament.TwoLeggedFinal,
+			tournament.Engine.Name,
+			engineParams,
+			tournament.Seed,
+		).Scan(&tournament.ID)
+		if err != nil {
+			return err
+		}
+
+		// Insert the groups
+		for _, group := range tournament.Groups {
+			group.TournamentID = &tournament.ID
+			if err := createLeague(ctx, tx, group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByID retrieves a tournament with its groups and knockout stage
+func (r *PostgresTournamentRepository) GetByID(ctx context.Context, id int) (*model.Tournament, error) {
+	query := `
+		SELECT id, name, group_count, advance_per_group, group_rounds, two_legged, two_legged_final, engine, engine_params, seed
+		FROM tournaments
+		WHERE id = $1
+	`
+	tournament := &model.Tournament{}
+	var engineParams []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&tournament.ID,
+		&tournament.Name,
+		&tournament.GroupCount,
+		&tournament.AdvancePerGroup,
+		&tournament.GroupRounds,
+		&tournament.TwoLegged,
+		&tournament.TwoLeggedFinal,
+		&tournament.Engine.Name,
+		&engineParams,
+		&tournament.Seed,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("tournament not found")
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(engineParams, &tournament.Engine.Params); err != nil {
+		return nil, err
+	}
+
+	// Get the groups in the order they were drawn
+	groupIDs, err := r.ids(ctx, `SELECT id FROM leagues WHERE tournament_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groupIDs {
+		group, err := r.leagues.GetByID(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		tournament.Groups = append(tournament.Groups, group)
+	}
+
+	// Get the knockout stage once it exists
+	cupIDs, err := r.ids(ctx, `SELECT id FROM cups WHERE tournament_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, cupID := range cupIDs {
+		if tournament.Knockout, err = r.cups.GetByID(ctx, cupID); err != nil {
+			return nil, err
+		}
+	}
+
+	return tournament, nil
+}
+
+// GetAll retrieves all tournaments without their groups and knockout stage
+func (r *PostgresTournamentRepository) GetAll(ctx context.Context) ([]*model.Tournament, error) {
+	query := `
+		SELECT id, name, group_count, advance_per_group, group_rounds, two_legged, two_legged_final, engine, engine_params, seed
+		FROM tournaments
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []*model.Tournament
+	for rows.Next() {
+		tournament := &model.Tournament{}
+		var engineParams []byte
+		if err := rows.Scan(
+			&tournament.ID,
+			&tournament.Name,
+			&tournament.GroupCount,
+			&tournament.AdvancePerGroup,
+			&tournament.GroupRounds,
+			&tournament.TwoLegged,
+			&tournament.TwoLeggedFinal,
+			&tournament.Engine.Name,
+			&engineParams,
+			&tournament.Seed,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(engineParams, &tournament.Engine.Params); err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, tournament)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tournaments, nil
+}
+
+// CreateKnockout inserts the drawn knockout stage of a tournament
+func (r *PostgresTournamentRepository) CreateKnockout(ctx context.Context, tournament *model.Tournament) error {
+	if tournament.Knockout == nil {
+		return errors.New("tournament has no knockout stage")
+	}
+
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		tournament.Knockout.TournamentID = &tournament.ID
+		if err := createCup(ctx, tx, tournament.Knockout); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ids runs a query returning a single integer column
+func (r *PostgresTournamentRepository) ids(ctx context.Context, query string, args ...interface{}) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// DBTX is the part of *sql.DB and *sql.Tx the PostgreSQL repositories run their queries on
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// PostgresUnitOfWork implements the UnitOfWork interface with database transactions
+type PostgresUnitOfWork struct {
+	db DBTX
+}
+
+// NewPostgresUnitOfWork creates a new PostgresUnitOfWork
+func NewPostgresUnitOfWork(db DBTX) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{
+		db: db,
+	}
+}
+
+// Do runs fn with repositories bound to a single transaction. A unit of work
+// started from repositories that already share a transaction joins it.
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(repos *Repository) error) error {
+	return inTx(ctx, u.db, func(tx *sql.Tx) error {
+		return fn(NewPostgresRepository(tx))
+	})
+}
+
+// inTx runs fn within a transaction on db, committing when fn succeeds and
+// rolling back otherwise. When db already is a transaction fn joins it and
+// committing is left to the transaction's owner.
+func inTx(ctx context.Context, db DBTX, fn func(tx *sql.Tx) error) error {
+	switch db := db.(type) {
+	case *sql.Tx:
+		return fn(db)
+
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+
+	default:
+		return errors.New("unsupported database handle")
+	}
+}
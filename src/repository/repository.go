@@ -11,37 +11,102 @@ type TeamRepository interface {
 	Create(ctx context.Context, team *model.Team) error
 	GetByID(ctx context.Context, id int) (*model.Team, error)
 	GetAll(ctx context.Context) ([]*model.Team, error)
+	GetByLeague(ctx context.Context, leagueID int) ([]*model.Team, error)
 	Update(ctx context.Context, team *model.Team) error
 	Delete(ctx context.Context, id int) error
 }
 
+// PlayerRepository defines the interface for player data operations
+type PlayerRepository interface {
+	Create(ctx context.Context, player *model.Player) error
+	GetByID(ctx context.Context, id int) (*model.Player, error)
+	GetByTeam(ctx context.Context, teamID int) ([]*model.Player, error)
+	Update(ctx context.Context, player *model.Player) error
+	Delete(ctx context.Context, teamID, id int) error
+}
+
 // MatchRepository defines the interface for match data operations
 type MatchRepository interface {
 	Create(ctx context.Context, match *model.Match) error
 	GetByID(ctx context.Context, id int) (*model.Match, error)
-	GetByWeek(ctx context.Context, week int) ([]*model.Match, error)
+	GetByWeek(ctx context.Context, leagueID, week int) ([]*model.Match, error)
+	GetByLeague(ctx context.Context, leagueID int) ([]*model.Match, error)
 	GetAll(ctx context.Context) ([]*model.Match, error)
 	Update(ctx context.Context, match *model.Match) error
 	Delete(ctx context.Context, id int) error
 }
 
+// MatchEventRepository defines the interface for match event data operations
+type MatchEventRepository interface {
+	Save(ctx context.Context, matchID int, events []*model.MatchEvent) error
+	GetByMatch(ctx context.Context, matchID int) ([]*model.MatchEvent, error)
+	GetByLeague(ctx context.Context, leagueID int) ([]*model.MatchEvent, error)
+}
+
 // StandingsRepository defines the interface for standings data operations
 type StandingsRepository interface {
-	GetCurrent(ctx context.Context) (*model.Standings, error)
-	Update(ctx context.Context, standings *model.Standings) error
+	GetCurrent(ctx context.Context, leagueID int) (*model.Standings, error)
+	Update(ctx context.Context, leagueID int, standings *model.Standings) error
+	DeleteAfterWeek(ctx context.Context, leagueID, week int) error
 }
 
 // LeagueRepository defines the interface for league data operations
 type LeagueRepository interface {
 	Create(ctx context.Context, league *model.League) error
 	GetByID(ctx context.Context, id int) (*model.League, error)
+	GetAll(ctx context.Context) ([]*model.League, error)
 	Update(ctx context.Context, league *model.League) error
+	// Lock locks a league until the end of the transaction of the repository
+	Lock(ctx context.Context, id int) error
+}
+
+// RatingRepository defines the interface for team rating data operations
+type RatingRepository interface {
+	GetHistory(ctx context.Context, leagueID int) ([]*model.TeamRating, error)
+	Save(ctx context.Context, leagueID int, ratings []*model.TeamRating) error
+	DeleteAfterWeek(ctx context.Context, leagueID, week int) error
+}
+
+// DeductionRepository defines the interface for point deduction data operations
+type DeductionRepository interface {
+	Create(ctx context.Context, deduction *model.PointDeduction) error
+	GetByLeagueID(ctx context.Context, leagueID int) ([]*model.PointDeduction, error)
+}
+
+// CupRepository defines the interface for cup data operations
+type CupRepository interface {
+	Create(ctx context.Context, cup *model.Cup) error
+	GetByID(ctx context.Context, id int) (*model.Cup, error)
+	GetAll(ctx context.Context) ([]*model.Cup, error)
+	Update(ctx context.Context, cup *model.Cup) error
+}
+
+// TournamentRepository defines the interface for tournament data operations
+type TournamentRepository interface {
+	Create(ctx context.Context, tournament *model.Tournament) error
+	GetByID(ctx context.Context, id int) (*model.Tournament, error)
+	GetAll(ctx context.Context) ([]*model.Tournament, error)
+	CreateKnockout(ctx context.Context, tournament *model.Tournament) error
+}
+
+// UnitOfWork runs a set of repository operations atomically
+type UnitOfWork interface {
+	// Do runs fn with repositories sharing a single transaction, which is
+	// committed when fn returns nil and rolled back otherwise
+	Do(ctx context.Context, fn func(repos *Repository) error) error
 }
 
 // Repository combines all repositories
 type Repository struct {
-	Team      TeamRepository
-	Match     MatchRepository
-	Standings StandingsRepository
-	League    LeagueRepository
+	Team       TeamRepository
+	Player     PlayerRepository
+	Match      MatchRepository
+	MatchEvent MatchEventRepository
+	Standings  StandingsRepository
+	League     LeagueRepository
+	Rating     RatingRepository
+	Deduction  DeductionRepository
+	Cup        CupRepository
+	Tournament TournamentRepository
+	UnitOfWork UnitOfWork
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/user/league-simulator/src/model"
+	"github.com/user/league-simulator/src/repository"
+)
+
+// CupService handles business logic for knockout cups
+type CupService struct {
+	cupRepo  repository.CupRepository
+	teamRepo repository.TeamRepository
+}
+
+// NewCupService creates a new CupService
+func NewCupService(cupRepo repository.CupRepository, teamRepo repository.TeamRepository) *CupService {
+	return &CupService{
+		cupRepo:  cupRepo,
+		teamRepo: teamRepo,
+	}
+}
+
+// Create creates a new cup with the given options.
+// When no team IDs are given, every existing team takes part.
+func (s *CupService) Create(ctx context.Context, name string, opts model.CupOptions) (*model.Cup, error) {
+	teams, err := resolveTeams(ctx, s.teamRepo, opts.TeamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cup, err := model.NewCup(name, teams, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cup.SetEngine(opts.Engine); err != nil {
+		return nil, err
+	}
+
+	if opts.Seed != nil {
+		cup.Seed = *opts.Seed
+	} else {
+		cup.Seed = rand.Int63()
+	}
+
+	if err := s.cupRepo.Create(ctx, cup); err != nil {
+		return nil, err
+	}
+
+	return cup, nil
+}
+
+// GetByID retrieves a cup by its ID
+func (s *CupService) GetByID(ctx context.Context, id int) (*model.Cup, error) {
+	return s.cupRepo.GetByID(ctx, id)
+}
+
+// GetAll retrieves all cups
+func (s *CupService) GetAll(ctx context.Context) ([]*model.Cup, error) {
+	return s.cupRepo.GetAll(ctx)
+}
+
+// Draw makes the draw of a cup and returns its bracket
+func (s *CupService) Draw(ctx context.Context, cupID int) (*model.CupBracket, error) {
+	cup, err := s.cupRepo.GetByID(ctx, cupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cup.MakeDraw(); err != nil {
+		return nil, err
+	}
+
+	if err := s.cupRepo.Update(ctx, cup); err != nil {
+		return nil, err
+	}
+
+	return cup.Bracket(), nil
+}
+
+// SimulateRound plays the next round of a cup and returns its ties
+func (s *CupService) SimulateRound(ctx context.Context, cupID int) (*model.CupRound, error) {
+	cup, err := s.cupRepo.GetByID(ctx, cupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := cup.SimulateRound(); err != nil {
+		return nil, err
+	}
+
+	if err := s.cupRepo.Update(ctx, cup); err != nil {
+		return nil, err
+	}
+
+	return cup.Bracket().Rounds[cup.CurrentRound-1], nil
+}
+
+// GetBracket returns the bracket of a cup
+func (s *CupService) GetBracket(ctx context.Context, cupID int) (*model.CupBracket, error) {
+	cup, err := s.cupRepo.GetByID(ctx, cupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cup.Bracket(), nil
+}
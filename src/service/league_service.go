@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 
 	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/repository"
@@ -14,6 +16,9 @@ type LeagueService struct {
 	teamRepo      repository.TeamRepository
 	matchRepo     repository.MatchRepository
 	standingsRepo repository.StandingsRepository
+	ratingRepo    repository.RatingRepository
+	deductionRepo repository.DeductionRepository
+	unitOfWork    repository.UnitOfWork
 }
 
 // NewLeagueService creates a new LeagueService
@@ -22,19 +27,25 @@ func NewLeagueService(
 	teamRepo repository.TeamRepository,
 	matchRepo repository.MatchRepository,
 	standingsRepo repository.StandingsRepository,
+	ratingRepo repository.RatingRepository,
+	deductionRepo repository.DeductionRepository,
+	unitOfWork repository.UnitOfWork,
 ) *LeagueService {
 	return &LeagueService{
 		leagueRepo:    leagueRepo,
 		teamRepo:      teamRepo,
 		matchRepo:     matchRepo,
 		standingsRepo: standingsRepo,
+		ratingRepo:    ratingRepo,
+		deductionRepo: deductionRepo,
+		unitOfWork:    unitOfWork,
 	}
 }
 
-// Create creates a new league
-func (s *LeagueService) Create(ctx context.Context, name string) (*model.League, error) {
-	// Get all teams
-	teams, err := s.teamRepo.GetAll(ctx)
+// Create creates a new league with the given options.
+// When no team IDs are given, every existing team takes part.
+func (s *LeagueService) Create(ctx context.Context, name string, opts model.LeagueOptions) (*model.League, error) {
+	teams, err := resolveTeams(ctx, s.teamRepo, opts.TeamIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -44,11 +55,42 @@ func (s *LeagueService) Create(ctx context.Context, name string) (*model.League,
 	}
 
 	// Create a new league
-	league, err := model.NewLeague(name, teams)
+	league, err := model.NewLeague(name, teams, opts.Rounds)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := league.SetEngine(opts.Engine); err != nil {
+		return nil, err
+	}
+
+	if err := league.SetRating(opts.Rating); err != nil {
+		return nil, err
+	}
+
+	if err := league.SetTieBreakers(opts.TieBreakers); err != nil {
+		return nil, err
+	}
+
+	if err := league.SetScoring(opts.Scoring); err != nil {
+		return nil, err
+	}
+
+	if err := league.SetDiscipline(opts.Discipline); err != nil {
+		return nil, err
+	}
+
+	if err := league.SetZones(opts.Zones); err != nil {
+		return nil, err
+	}
+	league.AnnotateClinches()
+
+	if opts.Seed != nil {
+		league.Seed = *opts.Seed
+	} else {
+		league.Seed = rand.Int63()
+	}
+
 	// Save the league
 	if err := s.leagueRepo.Create(ctx, league); err != nil {
 		return nil, err
@@ -62,21 +104,66 @@ func (s *LeagueService) GetByID(ctx context.Context, id int) (*model.League, err
 	return s.leagueRepo.GetByID(ctx, id)
 }
 
-// SimulateWeek simulates all matches for the current week
-func (s *LeagueService) SimulateWeek(ctx context.Context, leagueID int) (*model.Standings, error) {
-	// Get the league
-	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+// GetAll retrieves all leagues
+func (s *LeagueService) GetAll(ctx context.Context) ([]*model.League, error) {
+	return s.leagueRepo.GetAll(ctx)
+}
+
+// SimulateWeek simulates all matches for the current week.
+// The week is simulated with the given seed, or with the seed derived from the league seed when nil.
+func (s *LeagueService) SimulateWeek(ctx context.Context, leagueID int, seed *int64) (*model.Standings, error) {
+	var league *model.League
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		league, _, err = s.simulateWeek(ctx, repos, leagueID, seed, false)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	return &league.Standings, nil
+}
+
+// simulateWeek simulates the current week of a league, reading and storing it
+// with the given repositories, which the caller binds to a transaction. The
+// week is simulated with the given seed, or with one derived from it and the
+// week when derived is set, and with the week's seed of the league when nil.
+// It returns the league as stored and the results of the week.
+func (s *LeagueService) simulateWeek(ctx context.Context, repos *repository.Repository, leagueID int, seed *int64, derived bool) (*model.League, *model.WeeklyResult, error) {
+	// Concurrent weeks and result edits of the league wait for each other
+	if err := repos.League.Lock(ctx, leagueID); err != nil {
+		return nil, nil, err
+	}
+
+	// Get the league
+	league, err := repos.League.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if league.CurrentWeek >= league.TotalWeeks {
-		return nil, errors.New("all weeks have been played")
+		return nil, nil, errors.New("all weeks have been played")
 	}
 
 	// Increment the current week
 	league.CurrentWeek++
 
+	weekSeed := league.WeekSeed(league.CurrentWeek)
+	switch {
+	case seed != nil && derived:
+		weekSeed = model.DeriveSeed(*seed, int64(league.CurrentWeek))
+	case seed != nil:
+		weekSeed = *seed
+	}
+	rng := rand.New(rand.NewSource(weekSeed))
+
+	weekResult := &model.WeeklyResult{
+		Week:            league.CurrentWeek,
+		Matches:         make([]*model.MatchResult, 0),
+		StandingsBefore: s.copyStandings(&league.Standings),
+	}
+
 	// Find matches for the current week
 	var weekMatches []*model.Match
 	for _, match := range league.Matches {
@@ -105,29 +192,46 @@ func (s *LeagueService) SimulateWeek(ctx context.Context, leagueID int) (*model.
 		// Simulate the match
 		match.HomeTeam = homeTeam
 		match.AwayTeam = awayTeam
-		league.SimulateMatch(match)
-
-		// Update the match in the database
-		if err := s.matchRepo.Update(ctx, match); err != nil {
-			return nil, err
+		if err := league.SimulateMatch(match, rng); err != nil {
+			return nil, nil, err
 		}
-
-		// Update standings
-		league.Standings.UpdateStandings(match)
+		match.Seed = &weekSeed
+
+		weekResult.Matches = append(weekResult.Matches, &model.MatchResult{
+			MatchID:   match.ID,
+			HomeTeam:  homeTeam.Name,
+			AwayTeam:  awayTeam.Name,
+			HomeScore: match.HomeScore,
+			AwayScore: match.AwayScore,
+			Result:    match.Result(),
+			PlayedAt:  match.PlayedAt,
+		})
+
+		// Update standings and ratings
+		league.Standings.UpdateStandings(match, league.Scoring)
+		league.UpdateRatings(match)
 	}
 
-	// Update the league
-	if err := s.leagueRepo.Update(ctx, league); err != nil {
-		return nil, err
+	// Record teams sitting out the week
+	weekResult.Byes = league.ByesForWeek(league.CurrentWeek)
+	for _, bye := range weekResult.Byes {
+		league.Standings.RecordBye(bye.TeamID)
 	}
+	league.Standings.Byes = weekResult.Byes
+	league.ApplyDeductions(league.CurrentWeek)
 
-	// Update the standings
 	league.Standings.Week = league.CurrentWeek
-	if err := s.standingsRepo.Update(ctx, &league.Standings); err != nil {
-		return nil, err
+	league.SortStandings(&league.Standings, league.Matches)
+
+	// Store the week
+	if err := s.saveWeek(ctx, repos, league, weekMatches); err != nil {
+		return nil, nil, err
 	}
 
-	return &league.Standings, nil
+	league.AnnotateZones(&league.Standings)
+	weekResult.StandingsAfter = s.copyStandings(&league.Standings)
+
+	return league, weekResult, nil
 }
 
 // GetCurrentStandings retrieves the current standings
@@ -138,116 +242,255 @@ func (s *LeagueService) GetCurrentStandings(ctx context.Context, leagueID int) (
 		return nil, err
 	}
 
+	// Mark the zones of every position and the zones already decided,
+	// which are stored with the standings
+	league.AnnotateZones(&league.Standings)
+	clinches := league.CurrentClinches()
+	for i := range league.Standings.Teams {
+		league.Standings.Teams[i].Clinch = clinches[league.Standings.Teams[i].TeamID]
+	}
+
 	return &league.Standings, nil
 }
 
-// SimulateAllRemainingWeeks - Kalan tüm haftaları otomatik simüle eder
-func (s *LeagueService) SimulateAllRemainingWeeks(ctx context.Context, leagueID int) (*model.LeagueSimulationResult, error) {
-	// Liga bilgilerini al
+// SimulateAllRemainingWeeks - Kalan tüm haftaları otomatik simüle eder.
+// Seed verilirse hafta seed'leri ondan, verilmezse liganın seed'inden türetilir.
+// Her hafta, tek hafta simülasyonu gibi lig kilitlenerek kendi transaction'ı
+// içinde okunur, simüle edilir ve kaydedilir; böylece aradaki düzenlemeler
+// eski bir kopyanın üzerine yazılmaz.
+func (s *LeagueService) SimulateAllRemainingWeeks(ctx context.Context, leagueID int, seed *int64) (*model.LeagueSimulationResult, error) {
+	result := &model.LeagueSimulationResult{
+		LeagueID:      leagueID,
+		WeeklyResults: make([]*model.WeeklyResult, 0),
+	}
+
+	// Kalan haftaları tek tek simüle et
+	for {
+		var league *model.League
+		var weekResult *model.WeeklyResult
+		err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+			var err error
+			league, weekResult, err = s.simulateWeek(ctx, repos, leagueID, seed, true)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result.WeeklyResults) == 0 {
+			result.StartingWeek = weekResult.Week
+		}
+		result.WeeklyResults = append(result.WeeklyResults, weekResult)
+
+		if league.CurrentWeek >= league.TotalWeeks {
+			result.EndingWeek = league.TotalWeeks
+			result.FinalStandings = &league.Standings
+			return result, nil
+		}
+	}
+}
+
+// Replay re-simulates the played weeks of a league from their recorded seeds
+// and reports whether the results are identical to the stored ones
+func (s *LeagueService) Replay(ctx context.Context, leagueID int) (*model.ReplayResult, error) {
 	league, err := s.leagueRepo.GetByID(ctx, leagueID)
 	if err != nil {
 		return nil, err
 	}
 
-	if league.CurrentWeek >= league.TotalWeeks {
-		return nil, errors.New("tüm haftalar zaten oynanmış")
+	return league.Replay()
+}
+
+// GetRatingHistory returns the weekly rating history of every team of a league
+func (s *LeagueService) GetRatingHistory(ctx context.Context, leagueID int) (*model.RatingHistory, error) {
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
 	}
 
-	result := &model.LeagueSimulationResult{
-		LeagueID:      leagueID,
-		StartingWeek:  league.CurrentWeek + 1,
-		EndingWeek:    league.TotalWeeks,
-		WeeklyResults: make([]*model.WeeklyResult, 0),
+	ratings, err := s.ratingRepo.GetHistory(ctx, leagueID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Kalan haftaları tek tek simüle et
-	for league.CurrentWeek < league.TotalWeeks {
-		// Haftayı artır
-		league.CurrentWeek++
-
-		// Bu haftanın maçlarını bul
-		var weekMatches []*model.Match
-		for _, match := range league.Matches {
-			if match.Week == league.CurrentWeek && !match.Played {
-				weekMatches = append(weekMatches, match)
-			}
+	return model.NewRatingHistory(league, ratings), nil
+}
+
+// GetPlayerStats returns the player leaderboards and the open bans of a league
+func (s *LeagueService) GetPlayerStats(ctx context.Context, leagueID int) (*model.PlayerStatsReport, error) {
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return league.PlayerStats(), nil
+}
+
+// GetTeamForm returns the recent results of a team of a league and the effect
+// of the league's strength modifiers on its next match
+func (s *LeagueService) GetTeamForm(ctx context.Context, leagueID, teamID int) (*model.TeamForm, error) {
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return league.TeamForm(teamID)
+}
+
+// GetAbsences returns the players of a league who are injured or banned for its next week
+func (s *LeagueService) GetAbsences(ctx context.Context, leagueID int) ([]*model.Absence, error) {
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return league.Absences(), nil
+}
+
+// AddDeduction takes points off a team of a league from the current week onwards
+// and updates the current standings
+func (s *LeagueService) AddDeduction(ctx context.Context, leagueID, teamID, points int, reason string) (*model.PointDeduction, error) {
+	if points == 0 {
+		return nil, errors.New("a deduction must change the points of the team")
+	}
+
+	if reason == "" {
+		return nil, errors.New("a deduction needs a reason")
+	}
+
+	var deduction *model.PointDeduction
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		// A week simulated at the same time must not store standings without the deduction
+		if err := repos.League.Lock(ctx, leagueID); err != nil {
+			return err
 		}
 
-		weekResult := &model.WeeklyResult{
-			Week:            league.CurrentWeek,
-			Matches:         make([]*model.MatchResult, 0),
-			StandingsBefore: s.copyStandings(&league.Standings),
+		league, err := repos.League.GetByID(ctx, leagueID)
+		if err != nil {
+			return err
 		}
 
-		// Her maçı simüle et
-		for _, match := range weekMatches {
-			// Takımları bul
-			var homeTeam, awayTeam *model.Team
-			for _, team := range league.Teams {
-				if team.ID == match.HomeTeamID {
-					homeTeam = team
-				}
-				if team.ID == match.AwayTeamID {
-					awayTeam = team
-				}
+		deduction = &model.PointDeduction{
+			LeagueID: league.ID,
+			TeamID:   teamID,
+			Points:   points,
+			Reason:   reason,
+			Week:     league.CurrentWeek,
+		}
+		for _, team := range league.Teams {
+			if team.ID == teamID {
+				deduction.TeamName = team.Name
 			}
+		}
+		if deduction.TeamName == "" {
+			return fmt.Errorf("team %d is not part of the league", teamID)
+		}
 
-			if homeTeam == nil || awayTeam == nil {
-				continue
-			}
+		// Later weeks build on the current standings, so the deduction carries over
+		league.Standings.ApplyDeduction(deduction)
+		league.AnnotateClinches()
 
-			// Maçı simüle et
-			match.HomeTeam = homeTeam
-			match.AwayTeam = awayTeam
-			league.SimulateMatch(match)
-
-			// Maç sonucunu kaydet
-			matchResult := &model.MatchResult{
-				MatchID:   match.ID,
-				HomeTeam:  homeTeam.Name,
-				AwayTeam:  awayTeam.Name,
-				HomeScore: match.HomeScore,
-				AwayScore: match.AwayScore,
-				Result:    match.Result(),
-				PlayedAt:  match.PlayedAt,
-			}
-			weekResult.Matches = append(weekResult.Matches, matchResult)
+		if err := repos.Deduction.Create(ctx, deduction); err != nil {
+			return err
+		}
+		return repos.Standings.Update(ctx, league.ID, &league.Standings)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			// Veritabanını güncelle
-			if err := s.matchRepo.Update(ctx, match); err != nil {
-				return nil, err
-			}
+	return deduction, nil
+}
 
-			// Puan tablosunu güncelle
-			league.Standings.UpdateStandings(match)
+// GetDeductions returns the point deductions of a league
+func (s *LeagueService) GetDeductions(ctx context.Context, leagueID int) ([]*model.PointDeduction, error) {
+	if _, err := s.leagueRepo.GetByID(ctx, leagueID); err != nil {
+		return nil, err
+	}
+
+	return s.deductionRepo.GetByLeagueID(ctx, leagueID)
+}
+
+// SetZones replaces the qualification and relegation zones of a league and
+// returns the zones now in effect. No zones restore DefaultPositionBands.
+func (s *LeagueService) SetZones(ctx context.Context, leagueID int, zones []model.PositionBand) ([]model.PositionBand, error) {
+	var league *model.League
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		// The stored clinches must be worked out from the standings of the latest week
+		if err := repos.League.Lock(ctx, leagueID); err != nil {
+			return err
 		}
 
-		// Liga ve puan tablosunu kaydet
-		if err := s.leagueRepo.Update(ctx, league); err != nil {
-			return nil, err
+		var err error
+		league, err = repos.League.GetByID(ctx, leagueID)
+		if err != nil {
+			return err
 		}
 
-		league.Standings.Week = league.CurrentWeek
-		if err := s.standingsRepo.Update(ctx, &league.Standings); err != nil {
-			return nil, err
+		if err := league.SetZones(zones); err != nil {
+			return err
 		}
 
-		weekResult.StandingsAfter = s.copyStandings(&league.Standings)
-		result.WeeklyResults = append(result.WeeklyResults, weekResult)
+		// The stored clinches are worked out for the zones
+		league.AnnotateClinches()
+		if err := repos.League.Update(ctx, league); err != nil {
+			return err
+		}
+		return repos.Standings.Update(ctx, league.ID, &league.Standings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return league.PositionZones(), nil
+}
+
+// EditMatchResult - Lig maçının sonucunu düzenler; düzenlenen haftadan güncel
+// haftaya kadar tüm puan tablosu ve reyting kayıtlarını tek bir transaction
+// içinde yeniden hesaplar ve güncel puan tablosunu döner. Olay motoruyla
+// oynanan maçların akışından sadece gol sayısı değişen tarafın golleri
+// çıkarılır; diğer tarafın golleri, kartlar ve değişiklikler kalır.
+// Berabere biten maçların penaltılarla belirlendiği liglerde beraberlik için
+// penaltı sonucu da verilmelidir.
+func (s *LeagueService) EditMatchResult(ctx context.Context, leagueID, matchID int, homeScore, awayScore int, homePenalties, awayPenalties *int) (*model.Standings, error) {
+	var standings *model.Standings
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		standings, err = s.editMatchResult(ctx, repos, leagueID, matchID, homeScore, awayScore, homePenalties, awayPenalties)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	result.FinalStandings = &league.Standings
-	return result, nil
+	return standings, nil
 }
 
-// EditMatchResult - Maç sonucunu düzenler ve puan tablosunu yeniden hesaplar
-func (s *LeagueService) EditMatchResult(ctx context.Context, matchID int, homeScore, awayScore int) (*model.Standings, error) {
-	// Maçı bul
-	match, err := s.matchRepo.GetByID(ctx, matchID)
+// editMatchResult - Sonucu verilen repository'lerin transaction'ı içinde
+// düzenler. Lig okunmadan önce kilitlenir, böylece aynı ligdeki eşzamanlı
+// düzenlemeler birbirinin eski verisinden yeniden hesaplama yapmaz.
+func (s *LeagueService) editMatchResult(ctx context.Context, repos *repository.Repository, leagueID, matchID int, homeScore, awayScore int, homePenalties, awayPenalties *int) (*model.Standings, error) {
+	if err := repos.League.Lock(ctx, leagueID); err != nil {
+		return nil, err
+	}
+
+	// Ligi takımları, maçları, bay haftaları ve cezalarıyla birlikte al
+	league, err := repos.League.GetByID(ctx, leagueID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Maçı sadece bu ligin maçları arasında ara
+	var match *model.Match
+	for _, m := range league.Matches {
+		if m.ID == matchID {
+			match = m
+		}
+	}
+	if match == nil {
+		return nil, errors.New("maç bu ligde bulunamadı")
+	}
+
 	if !match.Played {
 		return nil, errors.New("oynanmamış maçın sonucu düzenlenemez")
 	}
@@ -256,21 +499,139 @@ func (s *LeagueService) EditMatchResult(ctx context.Context, matchID int, homeSc
 		return nil, errors.New("skorlar negatif olamaz")
 	}
 
+	// Penaltılar sadece penaltı atışlı liglerde beraberlikleri belirler
+	if homeScore == awayScore && league.Scoring.DrawShootout {
+		if homePenalties == nil || awayPenalties == nil {
+			return nil, errors.New("bu ligde berabere biten maç için penaltı sonucu gerekli")
+		}
+		if *homePenalties < 0 || *awayPenalties < 0 || *homePenalties == *awayPenalties {
+			return nil, errors.New("penaltı atışlarının bir kazananı olmalı")
+		}
+	} else {
+		homePenalties, awayPenalties = nil, nil
+	}
+
+	// Düzenlenen skor olay akışındaki gollerle çelişmesin diye gol sayısı
+	// değişen tarafın golleri akıştan çıkarılır; diğer tarafın golleri ve
+	// asistleri geçerli kalır
+	if homeScore != match.HomeScore {
+		match.DropGoalEvents(match.HomeTeamID)
+	}
+	if awayScore != match.AwayScore {
+		match.DropGoalEvents(match.AwayTeamID)
+	}
+
 	// Skorları güncelle
 	match.HomeScore = homeScore
 	match.AwayScore = awayScore
+	match.HomePenalties = homePenalties
+	match.AwayPenalties = awayPenalties
+
+	// Düzenlenen haftadan itibaren puan tablolarını yeniden hesapla; kesinleşen
+	// bölgeler güncel tabloyla birlikte saklanır
+	standings := league.RebuildStandings(match.Week)
+	league.AnnotateClinches()
+	standings[len(standings)-1] = &league.Standings
+
+	// Reytingler sonuca bağlı olduğundan düzenlenen haftadan itibaren yeniden hesaplanır
+	ratings, err := rebuildRatings(ctx, repos.Rating, league, match.Week)
+	if err != nil {
+		return nil, err
+	}
+
+	// Maçı, puan tablolarını ve reytingleri aynı transaction içinde kaydet
+	if err := repos.Match.Update(ctx, match); err != nil {
+		return nil, err
+	}
+	if err := repos.MatchEvent.Save(ctx, match.ID, match.Events); err != nil {
+		return nil, err
+	}
+	for _, snapshot := range standings {
+		if err := repos.Standings.Update(ctx, league.ID, snapshot); err != nil {
+			return nil, err
+		}
+	}
+	if err := repos.Rating.Save(ctx, league.ID, ratings); err != nil {
+		return nil, err
+	}
+
+	return &league.Standings, nil
+}
+
+// Rewind - Ligi verilen haftanın sonuna geri alır. Sonraki maçlar oynanmamış
+// olur; sonraki haftaların maç olayları, puan tablosu kayıtları ve reytingleri
+// tek bir transaction içinde silinir. Puan cezaları saklanır, sonraki
+// haftalarınkiler o haftalar yeniden oynandığında tekrar uygulanır.
+func (s *LeagueService) Rewind(ctx context.Context, leagueID, week int) (*model.Standings, error) {
+	var standings *model.Standings
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		standings, err = s.rewind(ctx, repos, leagueID, week)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return standings, nil
+}
+
+// rewind - Ligi verilen repository'lerin transaction'ı içinde geri alır. Lig
+// okunmadan önce kilitlenir, böylece aynı anda oynanan bir hafta eski veriden
+// geri alınıp yarım kalmaz.
+func (s *LeagueService) rewind(ctx context.Context, repos *repository.Repository, leagueID, week int) (*model.Standings, error) {
+	if err := repos.League.Lock(ctx, leagueID); err != nil {
+		return nil, err
+	}
 
-	// Veritabanını güncelle
-	if err := s.matchRepo.Update(ctx, match); err != nil {
+	league, err := repos.League.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Turnuvanın eleme aşaması son grup tablolarından çekilir
+	if league.TournamentID != nil {
+		return nil, errors.New("tournament groups cannot be rewound")
+	}
+
+	reset, err := league.Rewind(week)
+	if err != nil {
+		return nil, err
+	}
+	league.AnnotateClinches()
+
+	for _, match := range reset {
+		if err := repos.Match.Update(ctx, match); err != nil {
+			return nil, err
+		}
+		if err := repos.MatchEvent.Save(ctx, match.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repos.League.Update(ctx, league); err != nil {
+		return nil, err
+	}
+
+	if err := repos.Standings.DeleteAfterWeek(ctx, league.ID, week); err != nil {
 		return nil, err
 	}
 
-	// Puan tablosunu yeniden hesapla
-	return s.recalculateStandings(ctx, match)
+	// Haftanın kesinleşen bölgeleri yeniden saklanır; kayıt, önceki bir
+	// sonucun düzenlenmesiyle onlarsız yeniden hesaplanmış olabilir
+	if err := repos.Standings.Update(ctx, league.ID, &league.Standings); err != nil {
+		return nil, err
+	}
+
+	if err := repos.Rating.DeleteAfterWeek(ctx, league.ID, week); err != nil {
+		return nil, err
+	}
+
+	return &league.Standings, nil
 }
 
-// GetWeeklyMatches - Belirli bir haftanın maçlarını getir
-func (s *LeagueService) GetWeeklyMatches(ctx context.Context, leagueID, week int) ([]*model.Match, error) {
+// GetWeeklyMatches - Belirli bir haftanın maçlarını ve bay geçen takımlarını getir
+func (s *LeagueService) GetWeeklyMatches(ctx context.Context, leagueID, week int) (*model.WeeklyFixtures, error) {
 	league, err := s.leagueRepo.GetByID(ctx, leagueID)
 	if err != nil {
 		return nil, err
@@ -280,7 +641,7 @@ func (s *LeagueService) GetWeeklyMatches(ctx context.Context, leagueID, week int
 		return nil, errors.New("geçersiz hafta numarası")
 	}
 
-	var weekMatches []*model.Match
+	weekMatches := make([]*model.Match, 0)
 	for _, match := range league.Matches {
 		if match.Week == week {
 			// Takım bilgilerini ekle
@@ -296,14 +657,72 @@ func (s *LeagueService) GetWeeklyMatches(ctx context.Context, leagueID, week int
 		}
 	}
 
-	return weekMatches, nil
+	return &model.WeeklyFixtures{
+		Week:    week,
+		Matches: weekMatches,
+		Byes:    league.ByesForWeek(week),
+	}, nil
 }
 
 // Helper fonksiyonlar
+
+// saveWeek stores the matches of a simulated week and their events together
+// with the league's week counter, standings and ratings. The repositories
+// share the caller's transaction, so that a failure leaves the week unplayed.
+func (s *LeagueService) saveWeek(ctx context.Context, repos *repository.Repository, league *model.League, weekMatches []*model.Match) error {
+	for _, match := range weekMatches {
+		if err := repos.Match.Update(ctx, match); err != nil {
+			return err
+		}
+		if err := repos.MatchEvent.Save(ctx, match.ID, match.Events); err != nil {
+			return err
+		}
+	}
+
+	if err := repos.League.Update(ctx, league); err != nil {
+		return err
+	}
+
+	league.AnnotateClinches()
+	if err := repos.Standings.Update(ctx, league.ID, &league.Standings); err != nil {
+		return err
+	}
+
+	if league.Rating.Enabled {
+		return repos.Rating.Save(ctx, league.ID, league.RatingsSnapshot(league.CurrentWeek))
+	}
+	return nil
+}
+
+// resolveTeams returns the teams with the given IDs, or all teams when none are given
+func resolveTeams(ctx context.Context, teamRepo repository.TeamRepository, teamIDs []int) ([]*model.Team, error) {
+	if len(teamIDs) == 0 {
+		return teamRepo.GetAll(ctx)
+	}
+
+	seen := make(map[int]bool, len(teamIDs))
+	teams := make([]*model.Team, 0, len(teamIDs))
+	for _, id := range teamIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("team %d is listed more than once", id)
+		}
+		seen[id] = true
+
+		team, err := teamRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
 func (s *LeagueService) copyStandings(standings *model.Standings) *model.Standings {
 	copy := &model.Standings{
 		Week:  standings.Week,
 		Teams: make([]model.TeamStanding, len(standings.Teams)),
+		Byes:  standings.Byes,
 	}
 	for i, team := range standings.Teams {
 		copy.Teams[i] = team
@@ -311,42 +730,31 @@ func (s *LeagueService) copyStandings(standings *model.Standings) *model.Standin
 	return copy
 }
 
-func (s *LeagueService) recalculateStandings(ctx context.Context, editedMatch *model.Match) (*model.Standings, error) {
-	// Tüm takımları al
-	teams, err := s.teamRepo.GetAll(ctx)
-	if err != nil {
-		return nil, err
+// rebuildRatings - Reytingleri başlangıç değerlerinden yeniden hesaplar ve
+// fromWeek ile sonraki haftaların kayıtlarını döner
+func rebuildRatings(ctx context.Context, ratingRepo repository.RatingRepository, league *model.League, fromWeek int) ([]*model.TeamRating, error) {
+	if !league.Rating.Enabled {
+		return nil, nil
 	}
 
-	// Temiz puan tablosu başlat
-	standings := &model.Standings{
-		Week:  editedMatch.Week,
-		Teams: make([]model.TeamStanding, len(teams)),
-	}
-
-	for i, team := range teams {
-		standings.Teams[i] = model.TeamStanding{
-			TeamID:   team.ID,
-			TeamName: team.Name,
-		}
-	}
-
-	// Tüm oynanmış maçları al ve puan tablosunu yeniden hesapla
-	allMatches, err := s.matchRepo.GetAll(ctx)
+	history, err := ratingRepo.GetHistory(ctx, league.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, match := range allMatches {
-		if match.Played && match.Week <= editedMatch.Week {
-			standings.UpdateStandings(match)
+	var initial []*model.TeamRating
+	for _, rating := range history {
+		if rating.Week == 0 {
+			initial = append(initial, rating)
 		}
 	}
 
-	// Veritabanını güncelle
-	if err := s.standingsRepo.Update(ctx, standings); err != nil {
-		return nil, err
+	var changed []*model.TeamRating
+	for _, rating := range league.RebuildRatings(initial) {
+		if rating.Week >= fromWeek {
+			changed = append(changed, rating)
+		}
 	}
 
-	return standings, nil
+	return changed, nil
 }
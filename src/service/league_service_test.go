@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// storedLeague is what a failed simulation must leave untouched: the week
+// counter, the results of the matches and the standings of every week
+type storedLeague struct {
+	week      int
+	results   map[int][3]int // Played, home and away score by match
+	standings []model.Standings
+}
+
+func storedLeagueOf(store *memoryStore, leagueID int) storedLeague {
+	stored := storedLeague{
+		week:    store.data.leagues[leagueID].CurrentWeek,
+		results: make(map[int][3]int),
+	}
+	for id, match := range store.data.matches {
+		played := 0
+		if match.Played {
+			played = 1
+		}
+		stored.results[id] = [3]int{played, match.HomeScore, match.AwayScore}
+	}
+	for _, standings := range store.data.standings[leagueID] {
+		stored.standings = append(stored.standings, *copyStandings(standings))
+	}
+	return stored
+}
+
+func TestSimulateWeekFailureLeavesWeekUnplayed(t *testing.T) {
+	tests := []struct {
+		name            string
+		matchUpdate     int
+		standingsUpdate int
+	}{
+		{name: "first match update", matchUpdate: 1},
+		{name: "last match update", matchUpdate: 2},
+		{name: "standings update", standingsUpdate: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, league := newMemoryStore(t, 4)
+			service := store.leagueService()
+
+			// Play a week first, so that there is a stored week to keep
+			if _, err := service.SimulateWeek(ctx, league.ID, nil); err != nil {
+				t.Fatalf("first week: %v", err)
+			}
+			before := storedLeagueOf(store, league.ID)
+
+			store.failMatchUpdate = store.matchUpdates + tt.matchUpdate
+			if tt.matchUpdate == 0 {
+				store.failMatchUpdate = 0
+			}
+			store.failStandingsUpdate = store.standingsUpdates + tt.standingsUpdate
+			if tt.standingsUpdate == 0 {
+				store.failStandingsUpdate = 0
+			}
+
+			if _, err := service.SimulateWeek(ctx, league.ID, nil); !errors.Is(err, errInjected) {
+				t.Fatalf("expected the injected failure, got %v", err)
+			}
+
+			after := storedLeagueOf(store, league.ID)
+			if after.week != before.week {
+				t.Errorf("week counter changed from %d to %d", before.week, after.week)
+			}
+			if !reflect.DeepEqual(after.results, before.results) {
+				t.Errorf("match results changed:\nbefore %v\nafter  %v", before.results, after.results)
+			}
+			if !reflect.DeepEqual(after.standings, before.standings) {
+				t.Errorf("standings changed:\nbefore %+v\nafter  %+v", before.standings, after.standings)
+			}
+
+			// The week can be played once the failure is gone
+			store.failMatchUpdate, store.failStandingsUpdate = 0, 0
+			standings, err := service.SimulateWeek(ctx, league.ID, nil)
+			if err != nil {
+				t.Fatalf("retried week: %v", err)
+			}
+			if standings.Week != before.week+1 || store.data.leagues[league.ID].CurrentWeek != before.week+1 {
+				t.Errorf("expected week %d to be stored, got standings of week %d and week counter %d",
+					before.week+1, standings.Week, store.data.leagues[league.ID].CurrentWeek)
+			}
+		})
+	}
+}
+
+func TestSimulateAllRemainingWeeksFailureKeepsEarlierWeeks(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	service := store.leagueService()
+
+	// Two matches a week, so the fifth update is the first match of week 3
+	store.failMatchUpdate = 5
+	if _, err := service.SimulateAllRemainingWeeks(ctx, league.ID, nil); !errors.Is(err, errInjected) {
+		t.Fatalf("expected the injected failure, got %v", err)
+	}
+
+	after := storedLeagueOf(store, league.ID)
+	if after.week != 2 {
+		t.Fatalf("expected weeks 1 and 2 to be stored, got week counter %d", after.week)
+	}
+	if len(after.standings) != 3 || after.standings[2].Week != 2 {
+		t.Fatalf("expected the standings of weeks 0 to 2, got %+v", after.standings)
+	}
+	for id, match := range store.data.matches {
+		if played := after.results[id][0] == 1; played != (match.Week <= 2) {
+			t.Errorf("match %d of week %d: played %v", id, match.Week, played)
+		}
+	}
+}
+
+func TestSimulateAllRemainingWeeksKeepsConcurrentEdits(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	service := store.leagueService()
+
+	// A deduction committed once the first week is stored must not be
+	// overwritten by the weeks simulated after it
+	store.afterCommit = func() {
+		if _, err := service.AddDeduction(ctx, league.ID, 1, 3, "financial irregularities"); err != nil {
+			t.Errorf("deduction: %v", err)
+		}
+	}
+
+	result, err := service.SimulateAllRemainingWeeks(ctx, league.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StartingWeek != 1 || result.EndingWeek != league.TotalWeeks || len(result.WeeklyResults) != league.TotalWeeks {
+		t.Fatalf("expected weeks 1 to %d, got %d to %d in %d results",
+			league.TotalWeeks, result.StartingWeek, result.EndingWeek, len(result.WeeklyResults))
+	}
+	if deducted := pointsDeducted(result.FinalStandings, 1); deducted != 3 {
+		t.Errorf("expected 3 points deducted in the final standings, got %d", deducted)
+	}
+
+	snapshots := store.data.standings[league.ID]
+	if deducted := pointsDeducted(snapshots[len(snapshots)-1], 1); deducted != 3 {
+		t.Errorf("expected 3 points deducted in the stored standings, got %d", deducted)
+	}
+}
+
+func TestEditsReadTheLeagueLocked(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(ctx context.Context, service *LeagueService, leagueID int) error
+	}{
+		{name: "simulate week", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.SimulateWeek(ctx, leagueID, nil)
+			return err
+		}},
+		{name: "simulate all remaining weeks", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.SimulateAllRemainingWeeks(ctx, leagueID, nil)
+			return err
+		}},
+		{name: "edit match result", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.EditMatchResult(ctx, leagueID, 1, 3, 0, nil, nil)
+			return err
+		}},
+		{name: "add deduction", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.AddDeduction(ctx, leagueID, 1, 3, "financial irregularities")
+			return err
+		}},
+		{name: "set zones", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.SetZones(ctx, leagueID, []model.PositionBand{{Name: "champion", From: 1, To: 1}})
+			return err
+		}},
+		{name: "rewind", edit: func(ctx context.Context, service *LeagueService, leagueID int) error {
+			_, err := service.Rewind(ctx, leagueID, 0)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, league := newMemoryStore(t, 4)
+			service := store.leagueService()
+			if _, err := service.SimulateWeek(ctx, league.ID, nil); err != nil {
+				t.Fatalf("first week: %v", err)
+			}
+
+			// Reads of an edit outside of the league's lock may be overwritten
+			// by a concurrent edit of the league
+			store.outsideReads, store.unlockedReads = 0, 0
+			if err := tt.edit(ctx, service, league.ID); err != nil {
+				t.Fatal(err)
+			}
+			if store.outsideReads != 0 || store.unlockedReads != 0 {
+				t.Errorf("expected the league to be read locked in a unit of work only, got %d reads outside and %d before the lock",
+					store.outsideReads, store.unlockedReads)
+			}
+		})
+	}
+}
+
+func TestRewindKeepsDeductions(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	service := store.leagueService()
+
+	for week := 1; week <= 2; week++ {
+		if _, err := service.SimulateWeek(ctx, league.ID, nil); err != nil {
+			t.Fatalf("week %d: %v", week, err)
+		}
+	}
+	played, err := service.GetCurrentStandings(ctx, league.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AddDeduction(ctx, league.ID, 1, 3, "financial irregularities"); err != nil {
+		t.Fatal(err)
+	}
+
+	standings, err := service.Rewind(ctx, league.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deducted := pointsDeducted(standings, 1); deducted != 0 {
+		t.Errorf("expected no points deducted at week 1, got %d", deducted)
+	}
+	deductions, err := service.GetDeductions(ctx, league.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deductions) != 1 || deductions[0].Week != 2 {
+		t.Fatalf("expected the deduction of week 2 to be kept, got %+v", deductions)
+	}
+
+	// Playing week 2 again takes the deduction off, with the same results as before
+	standings, err = service.SimulateWeek(ctx, league.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deducted := pointsDeducted(standings, 1); deducted != 3 {
+		t.Errorf("expected 3 points deducted at week 2, got %d", deducted)
+	}
+	for _, team := range played.Teams {
+		want := team.Points
+		if team.TeamID == 1 {
+			want -= 3
+		}
+		for _, replayed := range standings.Teams {
+			if replayed.TeamID == team.TeamID && replayed.Points != want {
+				t.Errorf("team %d: expected %d points, got %d", team.TeamID, want, replayed.Points)
+			}
+		}
+	}
+}
+
+func pointsDeducted(standings *model.Standings, teamID int) int {
+	for _, team := range standings.Teams {
+		if team.TeamID == teamID {
+			return team.PointsDeducted
+		}
+	}
+	return 0
+}
+
+func TestSimulateWeekStoresClinches(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	service := store.leagueService()
+
+	for week := 1; week <= league.TotalWeeks; week++ {
+		if _, err := service.SimulateWeek(ctx, league.ID, nil); err != nil {
+			t.Fatalf("week %d: %v", week, err)
+		}
+
+		snapshots := store.data.standings[league.ID]
+		stored := snapshots[len(snapshots)-1]
+		for _, team := range stored.Teams {
+			if len(team.Clinch) != len(model.DefaultPositionBands(4)) {
+				t.Fatalf("week %d: expected the clinches of team %d to be stored, got %v", week, team.TeamID, team.Clinch)
+			}
+		}
+	}
+
+	// Once every match has been played, the final table decides every band
+	standings, err := service.GetCurrentStandings(ctx, league.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, team := range standings.Teams {
+		for _, status := range team.Clinch {
+			if status.Clinched == status.Eliminated || status.Undecided {
+				t.Errorf("team %d, band %s: expected a decided band, got %+v", team.TeamID, status.Band, status)
+			}
+		}
+	}
+}
+
+func TestEditMatchResultKeepsUnchangedGoals(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	if err := store.data.leagues[league.ID].SetEngine(model.EngineConfig{Name: model.EngineEvents}); err != nil {
+		t.Fatal(err)
+	}
+	addSquads(store.data.leagues[league.ID])
+	service := store.leagueService()
+	if _, err := service.SimulateWeek(ctx, league.ID, nil); err != nil {
+		t.Fatalf("first week: %v", err)
+	}
+
+	goals := func(matchID, teamID int) int {
+		count := 0
+		for _, event := range store.data.events[matchID] {
+			if event.Type == model.EventGoal && event.TeamID == teamID {
+				count++
+			}
+		}
+		return count
+	}
+
+	match := copyMatch(store.data.matches[1])
+	home, away := goals(match.ID, match.HomeTeamID), goals(match.ID, match.AwayTeamID)
+	if home != match.HomeScore || away != match.AwayScore {
+		t.Fatalf("expected the timeline to hold the %d-%d score, got %d-%d", match.HomeScore, match.AwayScore, home, away)
+	}
+
+	// The same score keeps every goal
+	if _, err := service.EditMatchResult(ctx, league.ID, match.ID, match.HomeScore, match.AwayScore, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if goals(match.ID, match.HomeTeamID) != home || goals(match.ID, match.AwayTeamID) != away {
+		t.Errorf("expected the goals of an unchanged score to be kept")
+	}
+
+	// A changed home score drops the home goals only
+	if _, err := service.EditMatchResult(ctx, league.ID, match.ID, match.HomeScore+1, match.AwayScore, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := goals(match.ID, match.HomeTeamID); got != 0 {
+		t.Errorf("expected the home goals to be dropped, got %d", got)
+	}
+	if got := goals(match.ID, match.AwayTeamID); got != away {
+		t.Errorf("expected the %d away goals to be kept, got %d", away, got)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/repository"
@@ -10,22 +11,54 @@ import (
 
 // MatchService handles business logic for matches
 type MatchService struct {
-	repo repository.MatchRepository
+	repo       repository.MatchRepository
+	eventRepo  repository.MatchEventRepository
+	unitOfWork repository.UnitOfWork
 }
 
 // NewMatchService creates a new MatchService
-func NewMatchService(repo repository.MatchRepository) *MatchService {
+func NewMatchService(repo repository.MatchRepository, eventRepo repository.MatchEventRepository, unitOfWork repository.UnitOfWork) *MatchService {
 	return &MatchService{
-		repo: repo,
+		repo:       repo,
+		eventRepo:  eventRepo,
+		unitOfWork: unitOfWork,
 	}
 }
 
-// Create creates a new match
+// Create adds an unplayed match between two teams of a league to one of its
+// weeks that has not been simulated yet. Matches are played by simulating
+// their week and their results edited through LeagueService.EditMatchResult,
+// so a played match cannot be created here.
 func (s *MatchService) Create(ctx context.Context, match *model.Match) error {
 	if err := match.Validate(); err != nil {
 		return err
 	}
-	return s.repo.Create(ctx, match)
+
+	if match.Played || match.HomeScore != 0 || match.AwayScore != 0 || match.HomePenalties != nil || match.AwayPenalties != nil {
+		return errors.New("matches are played by simulating their week")
+	}
+
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		// The league's weeks must not be simulated while the match is added
+		if err := repos.League.Lock(ctx, match.LeagueID); err != nil {
+			return err
+		}
+
+		league, err := repos.League.GetByID(ctx, match.LeagueID)
+		if err != nil {
+			return err
+		}
+
+		if match.Week <= league.CurrentWeek || match.Week > league.TotalWeeks {
+			return fmt.Errorf("a match can only be added to a week between %d and %d", league.CurrentWeek+1, league.TotalWeeks)
+		}
+
+		if err := checkFixture(league, match); err != nil {
+			return err
+		}
+
+		return repos.Match.Create(ctx, match)
+	})
 }
 
 // GetByID retrieves a match by its ID
@@ -33,12 +66,26 @@ func (s *MatchService) GetByID(ctx context.Context, id int) (*model.Match, error
 	return s.repo.GetByID(ctx, id)
 }
 
-// GetByWeek retrieves all matches for a specific week
-func (s *MatchService) GetByWeek(ctx context.Context, week int) ([]*model.Match, error) {
+// GetEvents retrieves the timeline of a match. Only matches simulated by the
+// event engine have one.
+func (s *MatchService) GetEvents(ctx context.Context, id int) ([]*model.MatchEvent, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.eventRepo.GetByMatch(ctx, id)
+}
+
+// GetByWeek retrieves all matches of a league for a specific week
+func (s *MatchService) GetByWeek(ctx context.Context, leagueID, week int) ([]*model.Match, error) {
 	if week < 1 {
 		return nil, errors.New("week must be a positive number")
 	}
-	return s.repo.GetByWeek(ctx, week)
+	return s.repo.GetByWeek(ctx, leagueID, week)
+}
+
+// GetByLeague retrieves all matches of a league
+func (s *MatchService) GetByLeague(ctx context.Context, leagueID int) ([]*model.Match, error) {
+	return s.repo.GetByLeague(ctx, leagueID)
 }
 
 // GetAll retrieves all matches
@@ -46,15 +93,114 @@ func (s *MatchService) GetAll(ctx context.Context) ([]*model.Match, error) {
 	return s.repo.GetAll(ctx)
 }
 
-// Update updates a match
+// Update reschedules an unplayed match to a later week of its league that
+// has not been simulated yet. Results are edited through
+// LeagueService.EditMatchResult, which rebuilds the standings and ratings,
+// so the league, teams and result of a match cannot change here. A league
+// or team ID of 0 keeps the stored one.
 func (s *MatchService) Update(ctx context.Context, match *model.Match) error {
-	if err := match.Validate(); err != nil {
-		return err
-	}
-	return s.repo.Update(ctx, match)
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		current, err := repos.Match.GetByID(ctx, match.ID)
+		if err != nil {
+			return err
+		}
+
+		if match.LeagueID != 0 && match.LeagueID != current.LeagueID {
+			return errors.New("a match cannot move to another league")
+		}
+
+		if (match.HomeTeamID != 0 && match.HomeTeamID != current.HomeTeamID) || (match.AwayTeamID != 0 && match.AwayTeamID != current.AwayTeamID) {
+			return errors.New("the teams of a match cannot change")
+		}
+
+		if current.Played {
+			return errors.New("played matches are edited through PUT /api/leagues/{id}/matches/{matchId}/result")
+		}
+
+		if match.Played || match.HomeScore != 0 || match.AwayScore != 0 {
+			return errors.New("matches are played by simulating their week")
+		}
+
+		// The league's weeks must not be simulated while the match moves
+		if err := repos.League.Lock(ctx, current.LeagueID); err != nil {
+			return err
+		}
+
+		league, err := repos.League.GetByID(ctx, current.LeagueID)
+		if err != nil {
+			return err
+		}
+
+		if match.Week <= league.CurrentWeek || match.Week > league.TotalWeeks {
+			return fmt.Errorf("a match can only move to a week between %d and %d", league.CurrentWeek+1, league.TotalWeeks)
+		}
+
+		updated := *current
+		updated.Week = match.Week
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+		if err := checkFixture(league, &updated); err != nil {
+			return err
+		}
+
+		if err := repos.Match.Update(ctx, &updated); err != nil {
+			return err
+		}
+
+		*match = updated
+		return nil
+	})
 }
 
-// Delete removes a match
+// Delete removes an unplayed match of a week of its league that has not been
+// simulated yet. Played matches are taken back by rewinding their league,
+// which rebuilds the standings and ratings.
 func (s *MatchService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		current, err := repos.Match.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// The league's weeks must not be simulated while the match is removed
+		if err := repos.League.Lock(ctx, current.LeagueID); err != nil {
+			return err
+		}
+
+		league, err := repos.League.GetByID(ctx, current.LeagueID)
+		if err != nil {
+			return err
+		}
+
+		if current.Played || current.Week <= league.CurrentWeek {
+			return errors.New("matches of simulated weeks are taken back by rewinding their league")
+		}
+
+		return repos.Match.Delete(ctx, id)
+	})
+}
+
+// checkFixture checks that both teams of a match belong to its league and
+// play no other match in its week
+func checkFixture(league *model.League, match *model.Match) error {
+	teams := make(map[int]bool, len(league.Teams))
+	for _, team := range league.Teams {
+		teams[team.ID] = true
+	}
+	if !teams[match.HomeTeamID] || !teams[match.AwayTeamID] {
+		return errors.New("both teams of a match must be part of its league")
+	}
+
+	for _, other := range league.Matches {
+		if other.ID == match.ID || other.Week != match.Week {
+			continue
+		}
+		if other.HomeTeamID == match.HomeTeamID || other.HomeTeamID == match.AwayTeamID ||
+			other.AwayTeamID == match.HomeTeamID || other.AwayTeamID == match.AwayTeamID {
+			return fmt.Errorf("a team of the match already plays in week %d", match.Week)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+func TestMatchServiceKeepsPlayedWeeks(t *testing.T) {
+	ctx := context.Background()
+	store, league := newMemoryStore(t, 4)
+	if _, err := store.leagueService().SimulateWeek(ctx, league.ID, nil); err != nil {
+		t.Fatalf("first week: %v", err)
+	}
+	service := store.matchService()
+
+	// Four teams play every week, so a match can only take the place of a removed one
+	var removed *model.Match
+	for id := 1; id <= len(store.data.matches); id++ {
+		if match := store.data.matches[id]; match.Week == league.TotalWeeks {
+			removed = copyMatch(match)
+			break
+		}
+	}
+
+	for _, match := range store.data.matches {
+		if match.Week == 1 {
+			if err := service.Delete(ctx, match.ID); err == nil {
+				t.Errorf("expected played match %d to be kept", match.ID)
+			}
+			break
+		}
+	}
+	if err := service.Delete(ctx, removed.ID); err != nil {
+		t.Fatalf("expected the unplayed match of the last week to be removed, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		match model.Match
+		valid bool
+	}{
+		{name: "played", match: model.Match{HomeTeamID: removed.HomeTeamID, AwayTeamID: removed.AwayTeamID, Week: league.TotalWeeks, Played: true, HomeScore: 2}},
+		{name: "simulated week", match: model.Match{HomeTeamID: removed.HomeTeamID, AwayTeamID: removed.AwayTeamID, Week: 1}},
+		{name: "team playing that week", match: model.Match{HomeTeamID: removed.HomeTeamID, AwayTeamID: removed.AwayTeamID, Week: league.TotalWeeks - 1}},
+		{name: "team of another league", match: model.Match{HomeTeamID: removed.HomeTeamID, AwayTeamID: 99, Week: league.TotalWeeks}},
+		{name: "free week", match: model.Match{HomeTeamID: removed.HomeTeamID, AwayTeamID: removed.AwayTeamID, Week: league.TotalWeeks}, valid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := len(store.data.matches)
+			match := tt.match
+			match.LeagueID = league.ID
+
+			err := service.Create(ctx, &match)
+			if tt.valid != (err == nil) {
+				t.Fatalf("expected valid %v, got %v", tt.valid, err)
+			}
+			want := 0
+			if tt.valid {
+				want = 1
+			}
+			if created := len(store.data.matches) - matches; created != want {
+				t.Errorf("expected %d matches created, got %d", want, created)
+			}
+		})
+	}
+}
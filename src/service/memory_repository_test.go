@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/user/league-simulator/src/model"
+	"github.com/user/league-simulator/src/repository"
+)
+
+// errInjected is returned by the memory repositories for the failures a test injects
+var errInjected = errors.New("injected failure")
+
+// memoryData is the content of the memory database. It holds copies, so
+// that nothing a service changes in the leagues it reads ends up stored
+// without going through a repository.
+type memoryData struct {
+	leagues    map[int]*model.League
+	matches    map[int]*model.Match
+	events     map[int][]*model.MatchEvent
+	standings  map[int][]*model.Standings // Snapshots of every week by league
+	ratings    map[int][]*model.TeamRating
+	deductions map[int][]*model.PointDeduction
+}
+
+// memoryStore is an in-memory database behind the repositories of a league.
+// A unit of work runs on a copy of its data, which replaces the data only
+// when the unit of work succeeds, just as a transaction would be committed.
+type memoryStore struct {
+	data *memoryData
+
+	// Calls of Match.Update and Standings.Update failing when reached,
+	// counted over all units of work, 0 for none
+	failMatchUpdate     int
+	failStandingsUpdate int
+
+	matchUpdates     int
+	standingsUpdates int
+
+	// League reads outside of units of work and reads inside them before the
+	// league was locked
+	outsideReads  int
+	unlockedReads int
+
+	// Called once after the next unit of work succeeds, standing in for a
+	// concurrent request committing between two units of work
+	afterCommit func()
+}
+
+// newMemoryStore stores a league of the given number of teams playing two round robins
+func newMemoryStore(t testing.TB, teams int) (*memoryStore, *model.League) {
+	t.Helper()
+
+	leagueTeams := make([]*model.Team, teams)
+	for i := range leagueTeams {
+		leagueTeams[i] = &model.Team{ID: i + 1, Name: fmt.Sprintf("Team %d", i+1), Strength: 40 + 50*i/teams}
+	}
+
+	league, err := model.NewLeague("Memory League", leagueTeams, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	league.ID = 1
+	league.Seed = 42
+
+	store := &memoryStore{data: &memoryData{
+		leagues:    map[int]*model.League{},
+		matches:    map[int]*model.Match{},
+		events:     map[int][]*model.MatchEvent{},
+		standings:  map[int][]*model.Standings{},
+		ratings:    map[int][]*model.TeamRating{},
+		deductions: map[int][]*model.PointDeduction{},
+	}}
+
+	for i, match := range league.Matches {
+		match.ID = i + 1
+		match.LeagueID = league.ID
+		store.data.matches[match.ID] = copyMatch(match)
+	}
+
+	stored := *league
+	stored.Matches = nil
+	store.data.leagues[league.ID] = &stored
+	store.data.standings[league.ID] = []*model.Standings{copyStandings(&league.Standings)}
+
+	return store, league
+}
+
+// clone returns a deep copy of the data
+func (d *memoryData) clone() *memoryData {
+	clone := &memoryData{
+		leagues:    make(map[int]*model.League, len(d.leagues)),
+		matches:    make(map[int]*model.Match, len(d.matches)),
+		events:     make(map[int][]*model.MatchEvent, len(d.events)),
+		standings:  make(map[int][]*model.Standings, len(d.standings)),
+		ratings:    make(map[int][]*model.TeamRating, len(d.ratings)),
+		deductions: make(map[int][]*model.PointDeduction, len(d.deductions)),
+	}
+
+	for id, league := range d.leagues {
+		copied := *league
+		clone.leagues[id] = &copied
+	}
+	for id, match := range d.matches {
+		clone.matches[id] = copyMatch(match)
+	}
+	for id, events := range d.events {
+		clone.events[id] = append([]*model.MatchEvent(nil), events...)
+	}
+	for id, snapshots := range d.standings {
+		for _, standings := range snapshots {
+			clone.standings[id] = append(clone.standings[id], copyStandings(standings))
+		}
+	}
+	for id, ratings := range d.ratings {
+		for _, rating := range ratings {
+			copied := *rating
+			clone.ratings[id] = append(clone.ratings[id], &copied)
+		}
+	}
+	for id, deductions := range d.deductions {
+		clone.deductions[id] = append([]*model.PointDeduction(nil), deductions...)
+	}
+
+	return clone
+}
+
+// repositories returns the repositories working on the given data of the store
+func (s *memoryStore) repositories(data *memoryData) *repository.Repository {
+	return &repository.Repository{
+		Match:      &memoryMatchRepository{store: s, data: data},
+		MatchEvent: &memoryMatchEventRepository{data: data},
+		Standings:  &memoryStandingsRepository{store: s, data: data},
+		League:     &memoryLeagueRepository{store: s, data: data},
+		Rating:     &memoryRatingRepository{data: data},
+		Deduction:  &memoryDeductionRepository{data: data},
+		UnitOfWork: s,
+	}
+}
+
+// Do runs fn on a copy of the data, which replaces the data when fn returns
+// nil. The data is replaced in place, so that the repositories outside of
+// units of work see it.
+func (s *memoryStore) Do(ctx context.Context, fn func(repos *repository.Repository) error) error {
+	data := s.data.clone()
+	if err := fn(s.repositories(data)); err != nil {
+		return err
+	}
+	*s.data = *data
+
+	if afterCommit := s.afterCommit; afterCommit != nil {
+		s.afterCommit = nil
+		afterCommit()
+	}
+	return nil
+}
+
+// predictionService returns a prediction service whose repositories use the store
+func (s *memoryStore) predictionService() *PredictionService {
+	repos := s.repositories(s.data)
+	return NewPredictionService(repos.League, nil, repos.Match)
+}
+
+// matchService returns a match service whose repositories use the store
+func (s *memoryStore) matchService() *MatchService {
+	repos := s.repositories(s.data)
+	return NewMatchService(repos.Match, repos.MatchEvent, s)
+}
+
+// leagueService returns a league service whose repositories use the store
+func (s *memoryStore) leagueService() *LeagueService {
+	repos := s.repositories(s.data)
+	return NewLeagueService(repos.League, nil, repos.Match, repos.Standings, repos.Rating, repos.Deduction, s)
+}
+
+func copyMatch(match *model.Match) *model.Match {
+	copied := *match
+	copied.HomeTeam = nil
+	copied.AwayTeam = nil
+	return &copied
+}
+
+func copyStandings(standings *model.Standings) *model.Standings {
+	copied := *standings
+	copied.Teams = append([]model.TeamStanding(nil), standings.Teams...)
+	copied.Byes = append([]*model.Bye(nil), standings.Byes...)
+	return &copied
+}
+
+// memoryLeagueRepository reads leagues together with their matches and
+// latest standings, as the Postgres repository does
+type memoryLeagueRepository struct {
+	repository.LeagueRepository
+	store  *memoryStore
+	data   *memoryData
+	locked bool
+}
+
+func (r *memoryLeagueRepository) GetByID(ctx context.Context, id int) (*model.League, error) {
+	switch {
+	case r.data == r.store.data:
+		r.store.outsideReads++
+	case !r.locked:
+		r.store.unlockedReads++
+	}
+
+	stored, ok := r.data.leagues[id]
+	if !ok {
+		return nil, errors.New("league not found")
+	}
+
+	league := *stored
+	league.Matches = make([]*model.Match, 0)
+	matchIDs := make([]int, 0, len(r.data.matches))
+	for matchID := range r.data.matches {
+		matchIDs = append(matchIDs, matchID)
+	}
+	sort.Ints(matchIDs)
+	for _, matchID := range matchIDs {
+		match := r.data.matches[matchID]
+		if match.LeagueID != id {
+			continue
+		}
+		match = copyMatch(match)
+		for _, team := range league.Teams {
+			if team.ID == match.HomeTeamID {
+				match.HomeTeam = team
+			}
+			if team.ID == match.AwayTeamID {
+				match.AwayTeam = team
+			}
+		}
+		match.Events = append([]*model.MatchEvent(nil), r.data.events[match.ID]...)
+		league.Matches = append(league.Matches, match)
+	}
+
+	snapshots := r.data.standings[id]
+	league.Standings = *copyStandings(snapshots[len(snapshots)-1])
+	league.Deductions = append([]*model.PointDeduction(nil), r.data.deductions[id]...)
+
+	return &league, nil
+}
+
+func (r *memoryLeagueRepository) Update(ctx context.Context, league *model.League) error {
+	stored, ok := r.data.leagues[league.ID]
+	if !ok {
+		return errors.New("league not found")
+	}
+	stored.Name = league.Name
+	stored.CurrentWeek = league.CurrentWeek
+	stored.TotalWeeks = league.TotalWeeks
+	return nil
+}
+
+func (r *memoryLeagueRepository) Lock(ctx context.Context, id int) error {
+	if _, ok := r.data.leagues[id]; !ok {
+		return errors.New("league not found")
+	}
+	r.locked = true
+	return nil
+}
+
+type memoryMatchRepository struct {
+	repository.MatchRepository
+	store *memoryStore
+	data  *memoryData
+}
+
+func (r *memoryMatchRepository) Create(ctx context.Context, match *model.Match) error {
+	match.ID = 1
+	for id := range r.data.matches {
+		match.ID = max(match.ID, id+1)
+	}
+	r.data.matches[match.ID] = copyMatch(match)
+	return nil
+}
+
+func (r *memoryMatchRepository) GetByID(ctx context.Context, id int) (*model.Match, error) {
+	match, ok := r.data.matches[id]
+	if !ok {
+		return nil, errors.New("match not found")
+	}
+	return copyMatch(match), nil
+}
+
+func (r *memoryMatchRepository) Delete(ctx context.Context, id int) error {
+	if _, ok := r.data.matches[id]; !ok {
+		return errors.New("match not found")
+	}
+	delete(r.data.matches, id)
+	return nil
+}
+
+func (r *memoryMatchRepository) Update(ctx context.Context, match *model.Match) error {
+	r.store.matchUpdates++
+	if r.store.matchUpdates == r.store.failMatchUpdate {
+		return errInjected
+	}
+
+	if _, ok := r.data.matches[match.ID]; !ok {
+		return errors.New("match not found")
+	}
+	r.data.matches[match.ID] = copyMatch(match)
+	return nil
+}
+
+type memoryMatchEventRepository struct {
+	repository.MatchEventRepository
+	data *memoryData
+}
+
+func (r *memoryMatchEventRepository) Save(ctx context.Context, matchID int, events []*model.MatchEvent) error {
+	r.data.events[matchID] = append([]*model.MatchEvent(nil), events...)
+	return nil
+}
+
+type memoryStandingsRepository struct {
+	repository.StandingsRepository
+	store *memoryStore
+	data  *memoryData
+}
+
+func (r *memoryStandingsRepository) GetCurrent(ctx context.Context, leagueID int) (*model.Standings, error) {
+	snapshots := r.data.standings[leagueID]
+	if len(snapshots) == 0 {
+		return nil, errors.New("standings not found")
+	}
+	return copyStandings(snapshots[len(snapshots)-1]), nil
+}
+
+func (r *memoryStandingsRepository) Update(ctx context.Context, leagueID int, standings *model.Standings) error {
+	r.store.standingsUpdates++
+	if r.store.standingsUpdates == r.store.failStandingsUpdate {
+		return errInjected
+	}
+
+	snapshots := r.data.standings[leagueID]
+	for i, snapshot := range snapshots {
+		if snapshot.Week == standings.Week {
+			snapshots[i] = copyStandings(standings)
+			return nil
+		}
+	}
+	r.data.standings[leagueID] = append(snapshots, copyStandings(standings))
+	return nil
+}
+
+func (r *memoryStandingsRepository) DeleteAfterWeek(ctx context.Context, leagueID, week int) error {
+	kept := make([]*model.Standings, 0)
+	for _, snapshot := range r.data.standings[leagueID] {
+		if snapshot.Week <= week {
+			kept = append(kept, snapshot)
+		}
+	}
+	r.data.standings[leagueID] = kept
+	return nil
+}
+
+type memoryRatingRepository struct {
+	repository.RatingRepository
+	data *memoryData
+}
+
+func (r *memoryRatingRepository) GetHistory(ctx context.Context, leagueID int) ([]*model.TeamRating, error) {
+	return r.data.ratings[leagueID], nil
+}
+
+func (r *memoryRatingRepository) Save(ctx context.Context, leagueID int, ratings []*model.TeamRating) error {
+	for _, rating := range ratings {
+		copied := *rating
+		r.data.ratings[leagueID] = append(r.data.ratings[leagueID], &copied)
+	}
+	return nil
+}
+
+func (r *memoryRatingRepository) DeleteAfterWeek(ctx context.Context, leagueID, week int) error {
+	kept := make([]*model.TeamRating, 0)
+	for _, rating := range r.data.ratings[leagueID] {
+		if rating.Week <= week {
+			kept = append(kept, rating)
+		}
+	}
+	r.data.ratings[leagueID] = kept
+	return nil
+}
+
+type memoryDeductionRepository struct {
+	data *memoryData
+}
+
+func (r *memoryDeductionRepository) Create(ctx context.Context, deduction *model.PointDeduction) error {
+	deduction.ID = len(r.data.deductions[deduction.LeagueID]) + 1
+	r.data.deductions[deduction.LeagueID] = append(r.data.deductions[deduction.LeagueID], deduction)
+	return nil
+}
+
+func (r *memoryDeductionRepository) GetByLeagueID(ctx context.Context, leagueID int) ([]*model.PointDeduction, error) {
+	return append([]*model.PointDeduction(nil), r.data.deductions[leagueID]...), nil
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"math/rand"
+	"runtime"
 	"sort"
+	"sync"
 
 	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/repository"
@@ -29,8 +32,9 @@ func NewPredictionService(
 	}
 }
 
-// PredictFinalStandings predicts the final standings after all weeks
-func (s *PredictionService) PredictFinalStandings(ctx context.Context, leagueID int) (*model.Standings, error) {
+// PredictFinalStandings predicts the final standings after all weeks.
+// The remaining matches are simulated with the given seed, or with the league's prediction seed when nil.
+func (s *PredictionService) PredictFinalStandings(ctx context.Context, leagueID int, seed *int64) (*model.Standings, error) {
 	// Get the league
 	league, err := s.leagueRepo.GetByID(ctx, leagueID)
 	if err != nil {
@@ -39,87 +43,450 @@ func (s *PredictionService) PredictFinalStandings(ctx context.Context, leagueID
 
 	// If all weeks have been played, return the current standings
 	if league.CurrentWeek >= league.TotalWeeks {
+		league.AnnotateZones(&league.Standings)
 		return &league.Standings, nil
 	}
 
-	// Create a copy of the current standings
-	predictedStandings := model.Standings{
-		Week:  league.TotalWeeks,
-		Teams: make([]model.TeamStanding, len(league.Standings.Teams)),
+	standings, err := s.simulateRemaining(league, rand.New(rand.NewSource(predictionSeed(league, seed))))
+	if err != nil {
+		return nil, err
 	}
-	copy(predictedStandings.Teams, league.Standings.Teams)
+	league.AnnotateZones(standings)
 
-	// Find remaining matches
-	var remainingMatches []*model.Match
-	for _, match := range league.Matches {
-		if !match.Played {
-			remainingMatches = append(remainingMatches, match)
-		}
+	return standings, nil
+}
+
+// simulateRemaining simulates the unplayed matches of an already loaded league
+// and returns the resulting final standings, leaving the league untouched
+func (s *PredictionService) simulateRemaining(league *model.League, rng *rand.Rand) (*model.Standings, error) {
+	run, err := newSimulationRun(league)
+	if err != nil {
+		return nil, err
 	}
 
-	// Simulate remaining matches
-	// No need to seed the random generator in Go 1.20+
-	for _, match := range remainingMatches {
-		// Find the teams
-		var homeTeam, awayTeam *model.Team
-		for _, team := range league.Teams {
-			if team.ID == match.HomeTeamID {
-				homeTeam = team
-			}
-			if team.ID == match.AwayTeamID {
-				awayTeam = team
+	return run.simulate(rng)
+}
+
+// simulationRun is what every simulation of a league's remaining matches
+// starts from. It is prepared once and only read afterwards, so simulations
+// can share it across goroutines.
+type simulationRun struct {
+	league    *model.League
+	standings model.Standings    // Current standings with the results fixed by a what-if scenario and the remaining byes
+	results   []*model.Match     // Played matches, for head-to-head tie-breakers
+	remaining []*model.Match     // Matches to simulate, with their teams set, by week
+	state     *model.SeasonState // Injuries, bans and form of the played matches before the first remaining week
+	fixed     []*model.Match     // Played matches from the first remaining week on, by week
+}
+
+// newSimulationRun prepares the simulations of a league's remaining matches
+func newSimulationRun(league *model.League) (*simulationRun, error) {
+	// Resolve the match engine up front, it is created lazily on first use
+	if _, err := league.Simulator(); err != nil {
+		return nil, err
+	}
+
+	run := &simulationRun{
+		league: league,
+		standings: model.Standings{
+			Week:  league.TotalWeeks,
+			Teams: make([]model.TeamStanding, len(league.Standings.Teams)),
+		},
+	}
+	copy(run.standings.Teams, league.Standings.Teams)
+
+	teams := make(map[int]*model.Team, len(league.Teams))
+	for _, team := range league.Teams {
+		teams[team.ID] = team
+	}
+
+	// Matches of later weeks that are already played carry results fixed by
+	// a what-if scenario
+	for _, match := range league.Matches {
+		if match.Played {
+			run.results = append(run.results, match)
+			if match.Week > league.CurrentWeek {
+				run.standings.UpdateStandings(match, league.Scoring)
 			}
+			continue
 		}
 
+		homeTeam, awayTeam := teams[match.HomeTeamID], teams[match.AwayTeamID]
 		if homeTeam == nil || awayTeam == nil {
 			continue
 		}
-
-		// Create a copy of the match for simulation
-		simulatedMatch := &model.Match{
+		run.remaining = append(run.remaining, &model.Match{
 			HomeTeamID: match.HomeTeamID,
 			AwayTeamID: match.AwayTeamID,
 			HomeTeam:   homeTeam,
 			AwayTeam:   awayTeam,
 			Week:       match.Week,
+		})
+	}
+
+	// Count the byes of the remaining weeks
+	for _, bye := range league.Byes {
+		if bye.Week > league.CurrentWeek {
+			run.standings.RecordBye(bye.TeamID)
 		}
+	}
+
+	// The season state is moved on through the remaining weeks in order,
+	// taking in the results fixed for them along the way
+	sort.SliceStable(run.remaining, func(i, j int) bool {
+		return run.remaining[i].Week < run.remaining[j].Week
+	})
+	firstWeek := league.TotalWeeks + 1
+	if len(run.remaining) > 0 {
+		firstWeek = run.remaining[0].Week
+	}
+	run.state = league.SeasonStateBefore(run.results, firstWeek)
+	for _, match := range run.results {
+		if match.Week >= firstWeek {
+			run.fixed = append(run.fixed, match)
+		}
+	}
+	sort.SliceStable(run.fixed, func(i, j int) bool {
+		if run.fixed[i].Week != run.fixed[j].Week {
+			return run.fixed[i].Week < run.fixed[j].Week
+		}
+		return run.fixed[i].ID < run.fixed[j].ID
+	})
 
-		// Simulate the match
-		// Create a temporary league for simulation
-		tempLeague := &model.League{
-			Teams: league.Teams,
+	return run, nil
+}
+
+// simulate plays the remaining matches once and returns the final standings
+// sorted by the league's tie-breakers
+func (r *simulationRun) simulate(rng *rand.Rand) (*model.Standings, error) {
+	standings := &model.Standings{
+		Week:  r.standings.Week,
+		Teams: make([]model.TeamStanding, len(r.standings.Teams)),
+	}
+	copy(standings.Teams, r.standings.Teams)
+
+	results := make([]*model.Match, len(r.results), len(r.results)+len(r.remaining))
+	copy(results, r.results)
+
+	// Injuries, bans and form of the run's earlier matches carry into later weeks
+	state := r.state.Clone()
+	fixed := 0
+
+	simulated := make([]model.Match, len(r.remaining))
+	for i, match := range r.remaining {
+		for ; fixed < len(r.fixed) && r.fixed[fixed].Week < match.Week; fixed++ {
+			state.Record(r.fixed[fixed])
 		}
-		tempLeague.SimulateMatch(simulatedMatch)
 
-		// Update the predicted standings
-		predictedStandings.UpdateStandings(simulatedMatch)
+		simulated[i] = *match
+		if err := r.league.SimulateMatchWith(&simulated[i], state, rng); err != nil {
+			return nil, err
+		}
+		standings.UpdateStandings(&simulated[i], r.league.Scoring)
+		results = append(results, &simulated[i])
+		state.Record(&simulated[i])
 	}
 
-	// Sort the standings by points, goal difference, etc.
-	s.sortStandings(&predictedStandings)
+	r.league.SortStandings(standings, results)
 
-	return &predictedStandings, nil
+	return standings, nil
 }
 
-// GetPredictionWithConfidence returns predictions with confidence levels after week 4
-func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, leagueID int) (*model.PredictionResult, error) {
+// predictionBatchSize is the number of simulations drawn from one generator.
+// Every batch is seeded from the prediction seed and its index, so the
+// results do not depend on the number of workers.
+const predictionBatchSize = 500
+
+// simulationTally accumulates the outcomes of simulated seasons
+type simulationTally struct {
+	positions      map[int][]int       // Finishing positions counted per team
+	points         map[int]map[int]int // Final points counted per team
+	goalDifference map[int]map[int]int // Final goal differences counted per team
+}
+
+// newSimulationTally creates an empty tally for the league's teams
+func newSimulationTally(league *model.League) *simulationTally {
+	tally := &simulationTally{
+		positions:      make(map[int][]int, len(league.Teams)),
+		points:         make(map[int]map[int]int, len(league.Teams)),
+		goalDifference: make(map[int]map[int]int, len(league.Teams)),
+	}
+	for _, team := range league.Teams {
+		tally.positions[team.ID] = make([]int, len(league.Teams))
+		tally.points[team.ID] = make(map[int]int)
+		tally.goalDifference[team.ID] = make(map[int]int)
+	}
+	return tally
+}
+
+// record adds the final standings of one simulation
+func (t *simulationTally) record(standings *model.Standings) {
+	for pos, standing := range standings.Teams {
+		if counts, exists := t.positions[standing.TeamID]; exists {
+			counts[pos]++
+			t.points[standing.TeamID][standing.Points]++
+			t.goalDifference[standing.TeamID][standing.GoalDifference]++
+		}
+	}
+}
+
+// merge adds another tally to this one
+func (t *simulationTally) merge(other *simulationTally) {
+	for teamID, counts := range other.positions {
+		for pos, count := range counts {
+			t.positions[teamID][pos] += count
+		}
+		for points, count := range other.points[teamID] {
+			t.points[teamID][points] += count
+		}
+		for goalDifference, count := range other.goalDifference[teamID] {
+			t.goalDifference[teamID][goalDifference] += count
+		}
+	}
+}
+
+// runSimulations spreads the given number of simulations over a pool of
+// workers, each batch with its own seeded generator, and tallies the results.
+// It stops early with the context's error when the context is cancelled.
+func (r *simulationRun) runSimulations(ctx context.Context, seed int64, iterations, workers int) (*simulationTally, error) {
+	batches := (iterations + predictionBatchSize - 1) / predictionBatchSize
+	if workers > batches {
+		workers = batches
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for batch := 0; batch < batches; batch++ {
+			select {
+			case jobs <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	tallies := make([]*simulationTally, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		tallies[w] = newSimulationTally(r.league)
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for batch := range jobs {
+				rng := rand.New(rand.NewSource(model.DeriveSeed(seed, int64(batch))))
+				size := predictionBatchSize
+				if last := iterations - batch*predictionBatchSize; last < size {
+					size = last
+				}
+				for i := 0; i < size; i++ {
+					if ctx.Err() != nil {
+						return
+					}
+					standings, err := r.simulate(rng)
+					if err != nil {
+						errs[w] = err
+						cancel()
+						return
+					}
+					tallies[w].record(standings)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tally := newSimulationTally(r.league)
+	for _, t := range tallies {
+		tally.merge(t)
+	}
+
+	return tally, nil
+}
+
+// GetPredictionWithConfidence returns predictions with confidence levels after week 4.
+// The league is loaded once and the simulations are spread over a pool of workers.
+func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, leagueID int, opts model.PredictionOptions) (*model.PredictionResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Get the league
 	league, err := s.leagueRepo.GetByID(ctx, leagueID)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.predictWithConfidence(ctx, league, opts)
+}
+
+// PredictScenario predicts the final standings with probabilities as if the
+// given results had happened and compares them with the predictions from the
+// real state of the league. Both run with the same options and nothing is persisted.
+func (s *PredictionService) PredictScenario(ctx context.Context, leagueID int, results []model.ForcedResult, opts model.PredictionOptions) (*model.ScenarioPrediction, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("a scenario needs at least one result")
+	}
+
+	baseline, err := s.predictWithConfidence(ctx, league, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	forced, err := league.ApplyScenario(results)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, err := s.predictWithConfidence(ctx, league, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ScenarioPrediction{
+		Results:  forced,
+		Baseline: baseline,
+		Scenario: scenario,
+		Diff:     model.DiffPredictions(baseline, scenario),
+	}, nil
+}
+
+// Backtest replays the predictions a finished league would have got after
+// each of its weeks, using only the results known then, and scores them
+// against the final table. The league's own match engine is tested first,
+// then every other engine with its default parameters.
+func (s *PredictionService) Backtest(ctx context.Context, leagueID int, opts model.PredictionOptions) (*model.BacktestReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if league.CurrentWeek < league.TotalWeeks {
+		return nil, errors.New("backtests need a league with every week played")
+	}
+
+	simulations, workers := simulationCounts(opts)
+
+	own := league.Engine.Name
+	if own == "" {
+		own = model.EngineLinear
+	}
+	engines := []model.EngineConfig{league.Engine}
+	for _, name := range model.Engines {
+		if name != own {
+			engines = append(engines, model.EngineConfig{Name: name})
+		}
+	}
+
+	final := model.Standings{
+		Week:  league.Standings.Week,
+		Teams: append([]model.TeamStanding(nil), league.Standings.Teams...),
+	}
+	league.SortStandings(&final, league.Matches)
+	league.AnnotateZones(&final)
+	actual := model.FinalPositions(&final)
+
+	report := &model.BacktestReport{
+		LeagueID:   league.ID,
+		TotalWeeks: league.TotalWeeks,
+		Iterations: simulations,
+		Final:      &final,
+		Engines:    make([]*model.EngineBacktest, 0, len(engines)),
+	}
+
+	for _, engine := range engines {
+		result := &model.EngineBacktest{
+			Calibration: model.NewCalibrationTable(),
+			Weeks:       make([]*model.ForecastScore, 0, league.TotalWeeks),
+		}
+
+		for week := 0; week < league.TotalWeeks; week++ {
+			past, err := league.AsOfWeek(week, engine)
+			if err != nil {
+				return nil, err
+			}
+			result.Engine = past.Engine
+
+			run, err := newSimulationRun(past)
+			if err != nil {
+				return nil, err
+			}
+
+			// Every week and engine uses the seed a prediction made that week would have used
+			tally, err := run.runSimulations(ctx, predictionSeed(past, opts.Seed), simulations, workers)
+			if err != nil {
+				return nil, err
+			}
+
+			probabilities := make(map[int][]float64, len(tally.positions))
+			for teamID, counts := range tally.positions {
+				probabilities[teamID] = make([]float64, len(counts))
+				for pos, count := range counts {
+					probabilities[teamID][pos] = float64(count) / float64(simulations) * 100
+				}
+			}
+
+			score := model.ScoreForecast(week, probabilities, actual, simulations)
+			model.AddToCalibration(result.Calibration, probabilities, actual)
+			result.Weeks = append(result.Weeks, score)
+			result.BrierScore += score.BrierScore / float64(league.TotalWeeks)
+			result.LogLoss += score.LogLoss / float64(league.TotalWeeks)
+			result.RankCorrelation += score.RankCorrelation / float64(league.TotalWeeks)
+		}
+
+		report.Engines = append(report.Engines, result)
+	}
+
+	return report, nil
+}
+
+// predictWithConfidence runs the predictions with confidence levels of an already loaded league
+func (s *PredictionService) predictWithConfidence(ctx context.Context, league *model.League, opts model.PredictionOptions) (*model.PredictionResult, error) {
 	// Predictions are only available after week 4 as per requirements
 	if league.CurrentWeek < 4 {
 		return nil, errors.New("tahminler sadece 4. hafta sonrası kullanılabilir")
 	}
 
+	// Zones already decided, exactly rather than by sampling, as stored with the standings
+	zones := league.PositionZones()
+	clinches := league.CurrentClinches()
+
 	// If all weeks have been played, return final results
 	if league.CurrentWeek >= league.TotalWeeks {
 		finalStandings := league.Standings
 		// Sort standings
-		s.sortStandings(&finalStandings)
-		
+		league.SortStandings(&finalStandings, league.Matches)
+		league.AnnotateZones(&finalStandings)
+		for i := range finalStandings.Teams {
+			finalStandings.Teams[i].Clinch = clinches[finalStandings.Teams[i].TeamID]
+		}
+
 		return &model.PredictionResult{
 			CurrentWeek:    league.CurrentWeek,
 			TotalWeeks:     league.TotalWeeks,
@@ -130,16 +497,32 @@ func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, lea
 	}
 
 	// Run multiple simulations for better prediction accuracy
-	simulations := 100
+	simulations, workers := simulationCounts(opts)
+
+	run, err := newSimulationRun(league)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the simulations from the loaded league, each batch with its own seeded generator
+	seed := predictionSeed(league, opts.Seed)
+	tally, err := run.runSimulations(ctx, seed, simulations, workers)
+	if err != nil {
+		return nil, err
+	}
+
 	teamPredictions := make(map[int]*model.TeamPrediction)
 
 	// Initialize predictions
 	for _, team := range league.Teams {
 		teamPredictions[team.ID] = &model.TeamPrediction{
-			TeamID:        team.ID,
-			TeamName:      team.Name,
-			CurrentPoints: 0,
-			PositionCounts: make([]int, len(league.Teams)),
+			TeamID:         team.ID,
+			TeamName:       team.Name,
+			CurrentPoints:  0,
+			PositionCounts: tally.positions[team.ID],
+			Points:         model.NewDistribution(tally.points[team.ID]),
+			GoalDifference: model.NewDistribution(tally.goalDifference[team.ID]),
+			Clinch:         clinches[team.ID],
 		}
 	}
 
@@ -150,29 +533,39 @@ func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, lea
 		}
 	}
 
-	// Run multiple simulations
-	for sim := 0; sim < simulations; sim++ {
-		predictedStandings, err := s.PredictFinalStandings(ctx, leagueID)
-		if err != nil {
-			continue
+	// Calculate averages, probabilities and their standard errors
+	top, bottom := league.SummaryZones()
+	for _, pred := range teamPredictions {
+		pred.PredictedPoints = int(pred.Points.Mean)
+
+		pred.PositionProbabilities = make([]float64, len(pred.PositionCounts))
+		pred.StandardErrors = &model.PredictionErrors{Positions: make([]float64, len(pred.PositionCounts))}
+		for pos, count := range pred.PositionCounts {
+			pred.PositionProbabilities[pos] = float64(count) / float64(simulations) * 100
+			pred.StandardErrors.Positions[pos] = model.ProbabilityStdError(count, simulations)
 		}
 
-		// Record positions from this simulation
-		for pos, standing := range predictedStandings.Teams {
-			if pred, exists := teamPredictions[standing.TeamID]; exists {
-				pred.PositionCounts[pos]++
-				pred.PredictedPoints += standing.Points
-			}
+		topThree := positionHits(pred.PositionCounts, top)
+		relegated := positionHits(pred.PositionCounts, bottom)
+
+		pred.ChampionshipProbability = pred.PositionProbabilities[0]
+		pred.TopThreeProbability = float64(topThree) / float64(simulations) * 100
+		pred.RelegationProbability = float64(relegated) / float64(simulations) * 100
+		pred.StandardErrors.Championship = pred.StandardErrors.Positions[0]
+		pred.StandardErrors.TopThree = model.ProbabilityStdError(topThree, simulations)
+		pred.StandardErrors.Relegation = model.ProbabilityStdError(relegated, simulations)
+
+		// Probabilities of the league's zones
+		pred.ZoneProbabilities = make([]*model.ZoneProbability, 0, len(zones))
+		for _, zone := range zones {
+			hits := positionHits(pred.PositionCounts, &zone)
+			pred.ZoneProbabilities = append(pred.ZoneProbabilities, &model.ZoneProbability{
+				Zone:        zone.Name,
+				Probability: float64(hits) / float64(simulations) * 100,
+				StdError:    model.ProbabilityStdError(hits, simulations),
+			})
 		}
-	}
 
-	// Calculate averages and probabilities
-	for _, pred := range teamPredictions {
-		pred.PredictedPoints /= simulations
-		pred.ChampionshipProbability = float64(pred.PositionCounts[0]) / float64(simulations) * 100
-		pred.TopThreeProbability = float64(pred.PositionCounts[0]+pred.PositionCounts[1]+pred.PositionCounts[2]) / float64(simulations) * 100
-		pred.RelegationProbability = float64(pred.PositionCounts[len(pred.PositionCounts)-1]) / float64(simulations) * 100
-		
 		// Calculate most likely position
 		maxCount := 0
 		for pos, count := range pred.PositionCounts {
@@ -189,11 +582,12 @@ func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, lea
 	confidence := float64(matchesPlayed) / float64(totalMatches) * 100
 
 	result := &model.PredictionResult{
-		CurrentWeek:       league.CurrentWeek,
-		TotalWeeks:        league.TotalWeeks,
-		PredictionType:    "Statistical Prediction",
-		TeamPredictions:   make([]*model.TeamPrediction, 0),
-		Confidence:        confidence,
+		CurrentWeek:     league.CurrentWeek,
+		TotalWeeks:      league.TotalWeeks,
+		PredictionType:  "Statistical Prediction",
+		Iterations:      simulations,
+		TeamPredictions: make([]*model.TeamPrediction, 0),
+		Confidence:      confidence,
 	}
 
 	// Convert map to slice and sort by predicted points
@@ -205,28 +599,50 @@ func (s *PredictionService) GetPredictionWithConfidence(ctx context.Context, lea
 		return result.TeamPredictions[i].PredictedPoints > result.TeamPredictions[j].PredictedPoints
 	})
 
-	// Get one final prediction for the standings field
-	finalPrediction, err := s.PredictFinalStandings(ctx, leagueID)
+	// Get one final prediction for the standings field, seeded after the last batch
+	finalSeed := model.DeriveSeed(seed, int64((simulations+predictionBatchSize-1)/predictionBatchSize))
+	finalPrediction, err := run.simulate(rand.New(rand.NewSource(finalSeed)))
 	if err != nil {
 		return nil, err
 	}
+	league.AnnotateZones(finalPrediction)
 	result.Standings = finalPrediction
 
 	return result, nil
 }
 
-// Helper function to sort standings
-func (s *PredictionService) sortStandings(standings *model.Standings) {
-	sort.Slice(standings.Teams, func(i, j int) bool {
-		if standings.Teams[i].Points != standings.Teams[j].Points {
-			return standings.Teams[i].Points > standings.Teams[j].Points
-		}
-		if standings.Teams[i].GoalDifference != standings.Teams[j].GoalDifference {
-			return standings.Teams[i].GoalDifference > standings.Teams[j].GoalDifference
-		}
-		if standings.Teams[i].GoalsFor != standings.Teams[j].GoalsFor {
-			return standings.Teams[i].GoalsFor > standings.Teams[j].GoalsFor
-		}
-		return standings.Teams[i].TeamName < standings.Teams[j].TeamName
-	})
+// simulationCounts returns the number of simulations and workers the options
+// ask for, with the defaults filled in
+func simulationCounts(opts model.PredictionOptions) (int, int) {
+	simulations := opts.Iterations
+	if simulations == 0 {
+		simulations = model.DefaultPredictionIterations
+	}
+	workers := opts.Workers
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return simulations, workers
+}
+
+// predictionSeed returns the requested seed or the league's default prediction seed
+func predictionSeed(league *model.League, seed *int64) int64 {
+	if seed != nil {
+		return *seed
+	}
+	return league.PredictionSeed()
+}
+
+// positionHits returns the number of simulations that ended with the team
+// within the band, given how often it finished in every position
+func positionHits(counts []int, band *model.PositionBand) int {
+	if band == nil {
+		return 0
+	}
+
+	hits := 0
+	for pos := band.From; pos <= band.To && pos <= len(counts); pos++ {
+		hits += counts[pos-1]
+	}
+	return hits
 }
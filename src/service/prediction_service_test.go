@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/user/league-simulator/src/model"
+)
+
+// Seasons simulated by every prediction of the benchmarks: the scale the
+// worker pool is built for, and a smaller one comparing the engines
+const (
+	benchmarkIterations       = 100000
+	engineBenchmarkIterations = 1000
+)
+
+// squadPositions are the positions of the players addSquads gives every team
+var squadPositions = []string{
+	model.PositionGoalkeeper, model.PositionGoalkeeper,
+	model.PositionDefender, model.PositionDefender, model.PositionDefender, model.PositionDefender, model.PositionDefender, model.PositionDefender,
+	model.PositionMidfielder, model.PositionMidfielder, model.PositionMidfielder, model.PositionMidfielder, model.PositionMidfielder, model.PositionMidfielder,
+	model.PositionForward, model.PositionForward, model.PositionForward, model.PositionForward,
+}
+
+// addSquads gives every team of the league a squad rated around its strength,
+// so that injuries and bans weaken the teams
+func addSquads(league *model.League) {
+	for _, team := range league.Teams {
+		team.Players = make([]*model.Player, len(squadPositions))
+		for i, position := range squadPositions {
+			rating := team.Strength + 10 - 2*(i%6)
+			team.Players[i] = &model.Player{
+				ID:          100*team.ID + i + 1,
+				TeamID:      team.ID,
+				Name:        fmt.Sprintf("Player %d-%d", team.ID, i+1),
+				Position:    position,
+				ShirtNumber: i + 1,
+				Attack:      rating,
+				Defence:     rating,
+				Goalkeeping: rating,
+				Age:         25,
+			}
+		}
+	}
+}
+
+// benchmarkVariants are the leagues every engine is benchmarked with: plain,
+// with squads and with the form, momentum and fatigue modifiers
+var benchmarkVariants = []struct {
+	name      string
+	squads    bool
+	modifiers *model.StrengthModifiers
+}{
+	{name: "plain"},
+	{name: "squads", squads: true},
+	{name: "form", modifiers: &model.StrengthModifiers{
+		Form:     model.FormModifier{Enabled: true},
+		Momentum: model.MomentumModifier{Enabled: true},
+		Fatigue:  model.FatigueModifier{Enabled: true},
+	}},
+}
+
+// BenchmarkPredictWithConfidence predicts a 20-team league halfway through
+// its season with the default engine, each operation simulating
+// benchmarkIterations seasons of the remaining 190 matches
+func BenchmarkPredictWithConfidence(b *testing.B) {
+	benchmarkPrediction(b, model.EngineConfig{}, false, benchmarkIterations)
+}
+
+// BenchmarkPredictionEngines predicts the same league with every engine and
+// variant, each operation simulating engineBenchmarkIterations seasons
+func BenchmarkPredictionEngines(b *testing.B) {
+	for _, engine := range model.Engines {
+		for _, variant := range benchmarkVariants {
+			b.Run(engine+"/"+variant.name, func(b *testing.B) {
+				benchmarkPrediction(b, model.EngineConfig{Name: engine, Modifiers: variant.modifiers}, variant.squads, engineBenchmarkIterations)
+			})
+		}
+	}
+}
+
+func benchmarkPrediction(b *testing.B, engine model.EngineConfig, squads bool, iterations int) {
+	ctx := context.Background()
+	store, league := newMemoryStore(b, 20)
+	if err := store.data.leagues[league.ID].SetEngine(engine); err != nil {
+		b.Fatal(err)
+	}
+	if squads {
+		addSquads(store.data.leagues[league.ID])
+	}
+
+	leagueService := store.leagueService()
+	for week := 1; week <= league.TotalWeeks/2; week++ {
+		if _, err := leagueService.SimulateWeek(ctx, league.ID, nil); err != nil {
+			b.Fatalf("week %d: %v", week, err)
+		}
+	}
+
+	predictionService := store.predictionService()
+	opts := model.PredictionOptions{Iterations: iterations}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := predictionService.GetPredictionWithConfidence(ctx, league.ID, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(iterations*b.N)/b.Elapsed().Seconds(), "seasons/s")
+}
@@ -11,15 +11,22 @@ type Service struct {
 	Standings  *StandingsService
 	League     *LeagueService
 	Prediction *PredictionService
+	Cup        *CupService
+	Tournament *TournamentService
 }
 
 // NewService creates a new Service with all service implementations
 func NewService(repo *repository.Repository) *Service {
+	league := NewLeagueService(repo.League, repo.Team, repo.Match, repo.Standings, repo.Rating, repo.Deduction, repo.UnitOfWork)
+	cup := NewCupService(repo.Cup, repo.Team)
+
 	return &Service{
-		Team:       NewTeamService(repo.Team),
-		Match:      NewMatchService(repo.Match),
-		Standings:  NewStandingsService(repo.Standings),
-		League:     NewLeagueService(repo.League, repo.Team, repo.Match, repo.Standings),
+		Team:       NewTeamService(repo.Team, repo.Player, repo.UnitOfWork),
+		Match:      NewMatchService(repo.Match, repo.MatchEvent, repo.UnitOfWork),
+		Standings:  NewStandingsService(repo.Standings, repo.League),
+		League:     league,
 		Prediction: NewPredictionService(repo.League, repo.Team, repo.Match),
+		Cup:        cup,
+		Tournament: NewTournamentService(repo.Tournament, repo.Team, league, cup, repo.UnitOfWork),
 	}
 }
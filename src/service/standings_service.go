@@ -9,22 +9,35 @@ import (
 
 // StandingsService handles business logic for standings
 type StandingsService struct {
-	repo repository.StandingsRepository
+	repo       repository.StandingsRepository
+	leagueRepo repository.LeagueRepository
 }
 
 // NewStandingsService creates a new StandingsService
-func NewStandingsService(repo repository.StandingsRepository) *StandingsService {
+func NewStandingsService(repo repository.StandingsRepository, leagueRepo repository.LeagueRepository) *StandingsService {
 	return &StandingsService{
-		repo: repo,
+		repo:       repo,
+		leagueRepo: leagueRepo,
 	}
 }
 
-// GetCurrent retrieves the current standings
-func (s *StandingsService) GetCurrent(ctx context.Context) (*model.Standings, error) {
-	return s.repo.GetCurrent(ctx)
+// GetCurrent retrieves the current standings of a league, ordered by its tie-breakers
+func (s *StandingsService) GetCurrent(ctx context.Context, leagueID int) (*model.Standings, error) {
+	standings, err := s.repo.GetCurrent(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	league, err := s.leagueRepo.GetByID(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	league.SortStandings(standings, league.Matches)
+
+	return standings, nil
 }
 
-// Update updates the standings
-func (s *StandingsService) Update(ctx context.Context, standings *model.Standings) error {
-	return s.repo.Update(ctx, standings)
+// Update updates the standings of a league
+func (s *StandingsService) Update(ctx context.Context, leagueID int, standings *model.Standings) error {
+	return s.repo.Update(ctx, leagueID, standings)
 }
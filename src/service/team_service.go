@@ -2,20 +2,25 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/user/league-simulator/src/model"
 	"github.com/user/league-simulator/src/repository"
 )
 
-// TeamService handles business logic for teams
+// TeamService handles business logic for teams and their squads
 type TeamService struct {
-	repo repository.TeamRepository
+	repo       repository.TeamRepository
+	playerRepo repository.PlayerRepository
+	unitOfWork repository.UnitOfWork
 }
 
 // NewTeamService creates a new TeamService
-func NewTeamService(repo repository.TeamRepository) *TeamService {
+func NewTeamService(repo repository.TeamRepository, playerRepo repository.PlayerRepository, unitOfWork repository.UnitOfWork) *TeamService {
 	return &TeamService{
-		repo: repo,
+		repo:       repo,
+		playerRepo: playerRepo,
+		unitOfWork: unitOfWork,
 	}
 }
 
@@ -37,11 +42,23 @@ func (s *TeamService) GetAll(ctx context.Context) ([]*model.Team, error) {
 	return s.repo.GetAll(ctx)
 }
 
-// Update updates a team
+// Update updates a team. A team deriving its strength from its squad gets
+// the squad's strength once the squad makes up a full line-up.
 func (s *TeamService) Update(ctx context.Context, team *model.Team) error {
 	if err := team.Validate(); err != nil {
 		return err
 	}
+
+	if team.SquadStrength {
+		players, err := s.playerRepo.GetByTeam(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		if strength, ok := model.SquadStrength(players); ok {
+			team.Strength = strength
+		}
+	}
+
 	return s.repo.Update(ctx, team)
 }
 
@@ -78,3 +95,107 @@ func (s *TeamService) CreateInitialTeams(ctx context.Context) ([]*model.Team, er
 
 	return teams, nil
 }
+
+// GetPlayers retrieves the squad of a team
+func (s *TeamService) GetPlayers(ctx context.Context, teamID int) ([]*model.Player, error) {
+	if _, err := s.repo.GetByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+	return s.playerRepo.GetByTeam(ctx, teamID)
+}
+
+// GetPlayer retrieves a player of a team's squad
+func (s *TeamService) GetPlayer(ctx context.Context, teamID, playerID int) (*model.Player, error) {
+	player, err := s.playerRepo.GetByID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if player.TeamID != teamID {
+		return nil, fmt.Errorf("player %d is not part of team %d", playerID, teamID)
+	}
+	return player, nil
+}
+
+// AddPlayer adds a player to a team's squad
+func (s *TeamService) AddPlayer(ctx context.Context, teamID int, player *model.Player) error {
+	player.TeamID = teamID
+	if err := s.checkPlayer(ctx, player); err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		if err := repos.Player.Create(ctx, player); err != nil {
+			return err
+		}
+		return refreshSquadStrength(ctx, repos, teamID)
+	})
+}
+
+// UpdatePlayer updates a player of a team's squad
+func (s *TeamService) UpdatePlayer(ctx context.Context, teamID int, player *model.Player) error {
+	player.TeamID = teamID
+	if err := s.checkPlayer(ctx, player); err != nil {
+		return err
+	}
+
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		if err := repos.Player.Update(ctx, player); err != nil {
+			return err
+		}
+		return refreshSquadStrength(ctx, repos, teamID)
+	})
+}
+
+// DeletePlayer removes a player from a team's squad
+func (s *TeamService) DeletePlayer(ctx context.Context, teamID, playerID int) error {
+	return s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		if err := repos.Player.Delete(ctx, teamID, playerID); err != nil {
+			return err
+		}
+		return refreshSquadStrength(ctx, repos, teamID)
+	})
+}
+
+// checkPlayer validates a player and makes sure its shirt number is free in the squad
+func (s *TeamService) checkPlayer(ctx context.Context, player *model.Player) error {
+	if err := player.Validate(); err != nil {
+		return err
+	}
+
+	squad, err := s.GetPlayers(ctx, player.TeamID)
+	if err != nil {
+		return err
+	}
+	for _, other := range squad {
+		if other.ID != player.ID && other.ShirtNumber == player.ShirtNumber {
+			return fmt.Errorf("shirt number %d is already taken by %s", player.ShirtNumber, other.Name)
+		}
+	}
+
+	return nil
+}
+
+// refreshSquadStrength recomputes the strength of a team deriving it from
+// its squad, leaving it unchanged until the squad makes up a full line-up
+func refreshSquadStrength(ctx context.Context, repos *repository.Repository, teamID int) error {
+	team, err := repos.Team.GetByID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	if !team.SquadStrength {
+		return nil
+	}
+
+	players, err := repos.Player.GetByTeam(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	strength, ok := model.SquadStrength(players)
+	if !ok || strength == team.Strength {
+		return nil
+	}
+
+	team.Strength = strength
+	return repos.Team.Update(ctx, team)
+}
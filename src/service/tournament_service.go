@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/user/league-simulator/src/model"
+	"github.com/user/league-simulator/src/repository"
+)
+
+// TournamentService handles business logic for group stage plus knockout tournaments.
+// The groups are played through the league service and the knockout stage through the cup service.
+type TournamentService struct {
+	tournamentRepo repository.TournamentRepository
+	teamRepo       repository.TeamRepository
+	leagueService  *LeagueService
+	cupService     *CupService
+	unitOfWork     repository.UnitOfWork
+}
+
+// NewTournamentService creates a new TournamentService
+func NewTournamentService(
+	tournamentRepo repository.TournamentRepository,
+	teamRepo repository.TeamRepository,
+	leagueService *LeagueService,
+	cupService *CupService,
+	unitOfWork repository.UnitOfWork,
+) *TournamentService {
+	return &TournamentService{
+		tournamentRepo: tournamentRepo,
+		teamRepo:       teamRepo,
+		leagueService:  leagueService,
+		cupService:     cupService,
+		unitOfWork:     unitOfWork,
+	}
+}
+
+// Create draws a new tournament into groups with the given options.
+// When no team IDs are given, every existing team takes part.
+func (s *TournamentService) Create(ctx context.Context, name string, opts model.TournamentOptions) (*model.TournamentView, error) {
+	teams, err := resolveTeams(ctx, s.teamRepo, opts.TeamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := rand.Int63()
+	if opts.Seed != nil {
+		seed = *opts.Seed
+	}
+
+	tournament, err := model.NewTournament(name, teams, opts, seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range tournament.Groups {
+		group.AnnotateClinches()
+	}
+
+	if err := s.tournamentRepo.Create(ctx, tournament); err != nil {
+		return nil, err
+	}
+
+	return tournament.View(), nil
+}
+
+// GetByID retrieves the combined view of a tournament
+func (s *TournamentService) GetByID(ctx context.Context, id int) (*model.TournamentView, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return tournament.View(), nil
+}
+
+// GetAll retrieves all tournaments
+func (s *TournamentService) GetAll(ctx context.Context) ([]*model.Tournament, error) {
+	return s.tournamentRepo.GetAll(ctx)
+}
+
+// SimulateGroupWeek plays the next week of every group that has not finished
+// yet, all groups in a single transaction. Once the last group week has been
+// played the knockout stage is drawn.
+func (s *TournamentService) SimulateGroupWeek(ctx context.Context, tournamentID int) (*model.TournamentView, error) {
+	var tournament *model.Tournament
+	err := s.unitOfWork.Do(ctx, func(repos *repository.Repository) error {
+		var err error
+		tournament, err = repos.Tournament.GetByID(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+
+		if tournament.GroupStageFinished() {
+			return errors.New("the group stage has already been played")
+		}
+
+		for _, group := range tournament.Groups {
+			if group.CurrentWeek >= group.TotalWeeks {
+				continue
+			}
+			if _, _, err := s.leagueService.simulateWeek(ctx, repos, group.ID, nil, false); err != nil {
+				return err
+			}
+		}
+
+		// Reload the groups with their new standings
+		tournament, err = repos.Tournament.GetByID(ctx, tournamentID)
+		if err != nil {
+			return err
+		}
+
+		if tournament.GroupStageFinished() && tournament.Knockout == nil {
+			knockout, err := tournament.NewKnockout()
+			if err != nil {
+				return err
+			}
+			tournament.Knockout = knockout
+
+			return repos.Tournament.CreateKnockout(ctx, tournament)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tournament.View(), nil
+}
+
+// SimulateKnockoutRound plays the next round of the knockout stage
+func (s *TournamentService) SimulateKnockoutRound(ctx context.Context, tournamentID int) (*model.TournamentView, error) {
+	tournament, err := s.tournamentRepo.GetByID(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tournament.Knockout == nil {
+		return nil, errors.New("the group stage has not finished yet")
+	}
+
+	if _, err := s.cupService.SimulateRound(ctx, tournament.Knockout.ID); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, tournamentID)
+}